@@ -4,6 +4,9 @@
 go run main.go internal config-uds-schema > uds.schema.json
 go run main.go internal config-tasks-schema > tasks.schema.json
 
+# Create the json schema for the events written with --events
+go run main.go internal config-events-schema > events.schema.json
+
 # Adds pattern properties to all definitions to allow for yaml extensions
 jq '.definitions |= map_values(. + {"patternProperties": {"^x-": {}}})' tasks.schema.json > temp_tasks.schema.json
 mv temp_tasks.schema.json tasks.schema.json
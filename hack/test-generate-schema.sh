@@ -13,5 +13,6 @@ check_git_status() {
 check_git_status uds.schema.json
 check_git_status zarf.schema.json
 check_git_status tasks.schema.json
+check_git_status events.schema.json
 
 exit 0
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package cmd contains the CLI commands for UDS.
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/config/lang"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThan string
+	pruneMaxSize   string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: lang.CmdCacheShort,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   lang.CmdCacheListShort,
+	Args:    cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		entries, err := cache.List()
+		if err != nil {
+			message.Fatalf(err, "Failed to list the UDS cache: %s", err.Error())
+		}
+		if len(entries) == 0 {
+			message.Infof("The UDS cache at %s is empty", cache.Dir())
+			return
+		}
+
+		var rows [][]string
+		var total int64
+		for _, e := range entries {
+			rows = append(rows, []string{
+				e.Digest[:12],
+				e.Kind,
+				zarfUtils.ByteFormat(float64(e.Size), 2),
+				e.LastUsed.Format(time.RFC1123),
+				strings.Join(e.Bundles, ", "),
+			})
+			total += e.Size
+		}
+		message.Table([]string{"Digest", "Kind", "Size", "Last Used", "Bundles"}, rows)
+		message.Infof("%d files using %s in %s", len(entries), zarfUtils.ByteFormat(float64(total), 2), cache.Dir())
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: lang.CmdCachePruneShort,
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if pruneOlderThan == "" && pruneMaxSize == "" {
+			message.Fatal(nil, lang.CmdCachePruneErrNoLimits)
+		}
+		var opts cache.PruneOptions
+		var err error
+		if pruneOlderThan != "" {
+			if opts.OlderThan, err = cache.ParseAge(pruneOlderThan); err != nil {
+				message.Fatalf(err, "Failed to prune the UDS cache: %s", err.Error())
+			}
+		}
+		if pruneMaxSize != "" {
+			if opts.MaxSize, err = cache.ParseSize(pruneMaxSize); err != nil {
+				message.Fatalf(err, "Failed to prune the UDS cache: %s", err.Error())
+			}
+		}
+
+		removed, err := cache.Prune(opts)
+		if err != nil {
+			message.Fatalf(err, "Failed to prune the UDS cache: %s", err.Error())
+		}
+		var freed int64
+		for _, e := range removed {
+			freed += e.Size
+		}
+		message.Successf("Removed %d files, freeing %s", len(removed), zarfUtils.ByteFormat(float64(freed), 2))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: lang.CmdCacheClearShort,
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if !config.CommonOptions.Confirm {
+			confirm := false
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf(lang.CmdCacheClearPrompt, cache.Dir()),
+			}
+			if err := survey.AskOne(prompt, &confirm); err != nil || !confirm {
+				message.Fatal(nil, "cache clear cancelled")
+			}
+		}
+		if err := cache.Clear(); err != nil {
+			message.Fatalf(err, "Failed to clear the UDS cache: %s", err.Error())
+		}
+		message.Successf("Cleared the UDS cache at %s", cache.Dir())
+	},
+}
+
+// enforceCacheSize prunes the UDS cache down to max_cache_size from the uds-config, if it is set
+func enforceCacheSize() {
+	if err := cache.Enforce(config.CommonOptions.MaxCacheSize); err != nil {
+		message.Warnf(lang.CmdCacheErrEnforceMaxSize, err.Error())
+	}
+}
+
+func init() {
+	initViper()
+
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", lang.CmdCachePruneFlagOlderThan)
+	cachePruneCmd.Flags().StringVar(&pruneMaxSize, "max-size", "", lang.CmdCachePruneFlagMaxSize)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheClearCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdCacheClearFlagConfirm)
+}
@@ -5,6 +5,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	zarfConfig "github.com/defenseunicorns/zarf/src/config"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
@@ -32,12 +34,14 @@ const (
 	architecture   configOption = "architecture"
 	noLogFile      configOption = "no_log_file"
 	noProgress     configOption = "no_progress"
+	maxCacheSize   configOption = "max_cache_size"
+	searchPaths    configOption = "search_paths"
 )
 
 // isValidConfigOption checks if a string is a valid config option
 func isValidConfigOption(str string) bool {
 	switch configOption(str) {
-	case confirm, insecure, cachePath, tempDirectory, logLevelOption, architecture, noLogFile, noProgress:
+	case confirm, insecure, cachePath, tempDirectory, logLevelOption, architecture, noLogFile, noProgress, maxCacheSize, searchPaths:
 		return true
 	default:
 		return false
@@ -46,20 +50,47 @@ func isValidConfigOption(str string) bool {
 
 // deploy performs validation, confirmation and deployment of a bundle
 func deploy(bndlClient *bundle.Bundle) {
+	startEvents("deploy", bundleCfg.DeployOpts.Source)
 	_, _, _, err := bndlClient.PreDeployValidation()
 	if err != nil {
+		events.Finish(err)
 		message.Fatalf(err, "Failed to validate bundle: %s", err.Error())
 	}
+
+	// print the plan without deploying anything
+	if bundleCfg.DeployOpts.DryRun {
+		err := bndlClient.DryRun()
+		events.Finish(err)
+		if err != nil {
+			bndlClient.ClearPaths()
+			message.Fatalf(err, "Failed to plan bundle deployment: %s", err.Error())
+		}
+		return
+	}
 	// confirm deployment
 	if ok := bndlClient.ConfirmBundleDeploy(); !ok {
+		events.Finish(fmt.Errorf("bundle deployment cancelled"))
 		message.Fatal(nil, "bundle deployment cancelled")
 	}
 
 	// deploy the bundle
-	if err := bndlClient.Deploy(); err != nil {
+	err = bndlClient.Deploy()
+	events.Finish(err)
+	if err != nil {
 		bndlClient.ClearPaths()
 		message.Fatalf(err, "Failed to deploy bundle: %s", err.Error())
 	}
+	enforceCacheSize()
+}
+
+// startEvents opens the --events stream for an operation, if it was set
+func startEvents(operation, source string) {
+	if eventsPath == "" {
+		return
+	}
+	if err := events.Start(eventsPath, operation, source); err != nil {
+		message.Fatalf(err, "Failed to open the event stream %s: %s", eventsPath, err.Error())
+	}
 }
 
 // configureZarf copies configs from UDS-CLI to Zarf
@@ -71,6 +102,10 @@ func configureZarf() {
 		Confirm:        config.CommonOptions.Confirm,
 		CachePath:      config.CommonOptions.CachePath, // use uds-cache instead of zarf-cache
 	}
+
+	// Zarf is vendored under `uds zarf`, so `./zarf` in package and bundle actions (including wait actions, which
+	// Zarf runs as `./zarf tools wait-for`) resolves to that rather than to `uds`
+	zarfConfig.ActionsCommandZarfPrefix = "zarf"
 }
 
 func setBundleFile(args []string) {
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"log/slog"
+	"testing"
+
+	zarfConfig "github.com/defenseunicorns/zarf/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/actions"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/defenseunicorns/zarf/src/pkg/variables"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureZarfActionsPrefix(t *testing.T) {
+	commonOptions, prefix := zarfConfig.CommonOptions, zarfConfig.ActionsCommandZarfPrefix
+	t.Cleanup(func() { zarfConfig.CommonOptions, zarfConfig.ActionsCommandZarfPrefix = commonOptions, prefix })
+	configureZarf()
+
+	executable, err := zarfUtils.GetFinalExecutablePath()
+	require.NoError(t, err)
+
+	// run a package action the way Zarf does during a deploy, echoing the command that ./zarf resolves to
+	variableConfig := variables.New("zarf", nil, nil, slog.New(message.ZarfHandler{}))
+	variableConfig.SetApplicationTemplates(make(map[string]*variables.TextTemplate))
+	action := zarfTypes.ZarfComponentAction{
+		Cmd:          "echo ./zarf tools kubectl get pods",
+		SetVariables: []variables.Variable{{Name: "RESOLVED"}},
+	}
+	require.NoError(t, actions.Run(zarfTypes.ZarfComponentActionDefaults{}, []zarfTypes.ZarfComponentAction{action}, variableConfig))
+
+	resolved, ok := variableConfig.GetSetVariable("RESOLVED")
+	require.True(t, ok)
+	require.Equal(t, executable+" zarf tools kubectl get pods", resolved.Value)
+}
@@ -7,9 +7,12 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/alecthomas/jsonschema"
+	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
+	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/spf13/cobra"
@@ -50,9 +53,38 @@ var configTasksSchemaCmd = &cobra.Command{
 	},
 }
 
+var configEventsSchemaCmd = &cobra.Command{
+	Use:   "config-events-schema",
+	Short: lang.CmdInternalEventsSchemaShort,
+	Run: func(_ *cobra.Command, _ []string) {
+		schema := jsonschema.Reflect(&types.Event{})
+		output, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			message.Fatal(err, lang.CmdInternalEventsSchemaErr)
+		}
+		fmt.Print(string(output) + "\n")
+	},
+}
+
+var deployPackageCmd = &cobra.Command{
+	Use:   "deploy-package",
+	Short: lang.CmdInternalDeployPackageShort,
+	Run: func(_ *cobra.Command, _ []string) {
+		// the parent deploy was already confirmed
+		config.CommonOptions.Confirm = true
+		configureZarf()
+
+		if err := bundle.DeployChildPackage(os.Stdin); err != nil {
+			message.Fatalf(err, lang.CmdInternalDeployPackageErr, err.Error())
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(internalCmd)
 
 	internalCmd.AddCommand(configUDSSchemaCmd)
 	internalCmd.AddCommand(configTasksSchemaCmd)
+	internalCmd.AddCommand(configEventsSchemaCmd)
+	internalCmd.AddCommand(deployPackageCmd)
 }
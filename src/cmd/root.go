@@ -11,6 +11,7 @@ import (
 
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	zarfCommon "github.com/defenseunicorns/zarf/src/cmd/common"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
@@ -21,6 +22,9 @@ import (
 var (
 	logLevel string
 
+	// eventsPath is where the JSON-lines event stream is written, "-" for stdout
+	eventsPath string
+
 	// Default global config for the bundler
 	bundleCfg = types.BundleConfig{}
 )
@@ -84,6 +88,10 @@ func init() {
 	v.SetDefault(V_INSECURE, false)
 	v.SetDefault(V_TMP_DIR, "")
 	v.SetDefault(V_BNDL_OCI_CONCURRENCY, 3)
+	v.SetDefault(V_MAX_CACHE_SIZE, "")
+	v.SetDefault(V_BNDL_DEPLOY_KEY_THRESHOLD, 1)
+	v.SetDefault(V_BNDL_INSPECT_KEY_THRESHOLD, 1)
+	v.SetDefault(V_BNDL_PULL_KEY_THRESHOLD, 1)
 
 	homeDir, _ := os.UserHomeDir()
 	v.SetDefault(V_UDS_CACHE, filepath.Join(homeDir, config.UDSCache))
@@ -96,6 +104,10 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&config.CommonOptions.TempDirectory, "tmpdir", v.GetString(V_TMP_DIR), lang.RootCmdFlagTempDir)
 	rootCmd.PersistentFlags().BoolVar(&config.CommonOptions.Insecure, "insecure", v.GetBool(V_INSECURE), lang.RootCmdFlagInsecure)
 	rootCmd.PersistentFlags().IntVar(&config.CommonOptions.OCIConcurrency, "oci-concurrency", v.GetInt(V_BNDL_OCI_CONCURRENCY), lang.CmdBundleFlagConcurrency)
+
+	// the max cache size and search paths are only set in the uds-config or env
+	config.CommonOptions.MaxCacheSize = v.GetString(V_MAX_CACHE_SIZE)
+	config.CommonOptions.SearchPaths = v.GetStringSlice(V_SEARCH_PATHS)
 }
 
 // loadViperConfig reads the config file and unmarshals the relevant config into DeployOpts.Variables
@@ -111,6 +123,10 @@ func loadViperConfig() error {
 		return err
 	}
 
+	// registry mirrors and settings apply to every command that uses a registry
+	config.CommonOptions.RegistryMirrors = bundleCfg.DeployOpts.RegistryMirrors
+	config.CommonOptions.Registries = bundleCfg.DeployOpts.Registries
+
 	// ensure the DeployOpts.Variables pkg vars are uppercase
 	for pkgName, pkgVar := range bundleCfg.DeployOpts.Variables {
 		for varName, varValue := range pkgVar {
@@ -144,5 +160,8 @@ func unmarshalAndValidateConfig(configFile []byte, bundleCfg *types.BundleConfig
 			return fmt.Errorf("invalid config option: %s", optionName)
 		}
 	}
-	return nil
+	if err := boci.ValidateMirrors(bundleCfg.DeployOpts.RegistryMirrors); err != nil {
+		return err
+	}
+	return boci.ValidateRegistries(bundleCfg.DeployOpts.Registries)
 }
@@ -12,9 +12,12 @@ import (
 	"path/filepath"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/config/lang"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundle"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
+	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/spf13/cobra"
 )
@@ -40,7 +43,10 @@ var createCmd = &cobra.Command{
 		bndlClient := bundle.NewOrDie(&bundleCfg)
 		defer bndlClient.ClearPaths()
 
-		if err := bndlClient.Create(); err != nil {
+		startEvents("create", srcDir)
+		err = bndlClient.Create()
+		events.Finish(err)
+		if err != nil {
 			bndlClient.ClearPaths()
 			message.Fatalf(err, "Failed to create bundle: %s", err.Error())
 		}
@@ -48,10 +54,16 @@ var createCmd = &cobra.Command{
 }
 
 var deployCmd = &cobra.Command{
-	Use:     "deploy [BUNDLE_TARBALL|OCI_REF]",
+	Use:     "deploy [BUNDLE_TARBALL|OCI_LAYOUT_DIR|OCI_REF]",
 	Aliases: []string{"d"},
 	Short:   lang.CmdBundleDeployShort,
 	Args:    cobra.MaximumNArgs(1),
+	PreRun: func(cmd *cobra.Command, _ []string) {
+		if bundleCfg.DeployOpts.PlanOut != "" && !bundleCfg.DeployOpts.DryRun {
+			message.Fatal(nil, "cannot use 'plan-out' flag without 'dry-run' flag")
+		}
+		bundleCfg.DeployOpts.RetriesSet = cmd.Flags().Changed("retries")
+	},
 	Run: func(_ *cobra.Command, args []string) {
 		bundleCfg.DeployOpts.Source = chooseBundle(args)
 		configureZarf()
@@ -64,13 +76,13 @@ var deployCmd = &cobra.Command{
 }
 
 var inspectCmd = &cobra.Command{
-	Use:     "inspect [BUNDLE_TARBALL|OCI_REF]",
+	Use:     "inspect [BUNDLE_TARBALL|OCI_LAYOUT_DIR|OCI_REF]",
 	Aliases: []string{"i"},
 	Short:   lang.CmdBundleInspectShort,
 	Args:    cobra.MaximumNArgs(1),
 	PreRun: func(cmd *cobra.Command, _ []string) {
-		if cmd.Flag("extract").Value.String() == "true" && cmd.Flag("sbom").Value.String() == "false" {
-			message.Fatal(nil, "cannot use 'extract' flag without 'sbom' flag")
+		if cmd.Flag("extract").Value.String() == "true" && cmd.Flag("sbom").Value.String() == "false" && cmd.Flag("referrers").Value.String() == "false" {
+			message.Fatal(nil, "cannot use 'extract' flag without 'sbom' or 'referrers' flag")
 		}
 	},
 	Run: func(_ *cobra.Command, args []string) {
@@ -88,32 +100,71 @@ var inspectCmd = &cobra.Command{
 }
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [BUNDLE_TARBALL|OCI_REF]",
+	Use:     "remove [BUNDLE_TARBALL|OCI_LAYOUT_DIR|OCI_REF]",
 	Aliases: []string{"r"},
-	Args:    cobra.ExactArgs(1),
+	Args:    cobra.MaximumNArgs(1),
 	Short:   lang.CmdBundleRemoveShort,
+	PreRun: func(_ *cobra.Command, args []string) {
+		if len(args) == 0 && bundleCfg.RemoveOpts.BundleName == "" {
+			message.Fatal(nil, "a bundle tarball or OCI ref is required unless the 'bundle' flag is set")
+		}
+		if len(args) > 0 && bundleCfg.RemoveOpts.BundleName != "" {
+			message.Fatal(nil, "cannot use the 'bundle' flag with a bundle tarball or OCI ref")
+		}
+	},
 	Run: func(_ *cobra.Command, args []string) {
-		bundleCfg.RemoveOpts.Source = args[0]
+		if len(args) > 0 {
+			bundleCfg.RemoveOpts.Source = args[0]
+		}
 		configureZarf()
 
 		bndlClient := bundle.NewOrDie(&bundleCfg)
 		defer bndlClient.ClearPaths()
 
-		if err := bndlClient.Remove(); err != nil {
+		startEvents("remove", bundleCfg.RemoveOpts.Source)
+		err := bndlClient.Remove()
+		events.Finish(err)
+		if err != nil {
 			bndlClient.ClearPaths()
 			message.Fatalf(err, "Failed to remove bundle: %s", err.Error())
 		}
 	},
 }
 
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   lang.CmdBundleListShort,
+	Args:    cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if err := bundle.List(); err != nil {
+			message.Fatalf(err, "Failed to list deployed bundles: %s", err.Error())
+		}
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [BUNDLE_NAME]",
+	Short: lang.CmdBundleStatusShort,
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		if err := bundle.Status(args[0]); err != nil {
+			message.Fatalf(err, "Failed to get the status of bundle %s: %s", args[0], err.Error())
+		}
+	},
+}
+
 var publishCmd = &cobra.Command{
-	Use:     "publish [BUNDLE_TARBALL] [OCI_REF]",
+	Use:     "publish [BUNDLE_TARBALL|OCI_LAYOUT_DIR|OCI_REF] [OCI_REF]",
 	Aliases: []string{"p"},
 	Short:   lang.CmdPublishShort,
 	Args:    cobra.ExactArgs(2),
 	PreRun: func(_ *cobra.Command, args []string) {
+		if helpers.IsOCIURL(args[0]) {
+			return
+		}
 		if _, err := os.Stat(args[0]); err != nil {
-			message.Fatalf(err, "First argument (%q) must be a valid local Bundle path: %s", args[0], err.Error())
+			message.Fatalf(err, "First argument (%q) must be a valid local Bundle path or OCI ref: %s", args[0], err.Error())
 		}
 	},
 	Run: func(_ *cobra.Command, args []string) {
@@ -123,13 +174,34 @@ var publishCmd = &cobra.Command{
 		bndlClient := bundle.NewOrDie(&bundleCfg)
 		defer bndlClient.ClearPaths()
 
-		if err := bndlClient.Publish(); err != nil {
+		startEvents("publish", bundleCfg.PublishOpts.Destination)
+		err := bndlClient.Publish()
+		events.Finish(err)
+		if err != nil {
 			bndlClient.ClearPaths()
 			message.Fatalf(err, "Failed to publish bundle: %s", err.Error())
 		}
 	},
 }
 
+var attachCmd = &cobra.Command{
+	Use:   "attach [OCI_REF] [FILE]...",
+	Short: lang.CmdBundleAttachShort,
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		bundleCfg.AttachOpts.Source = args[0]
+		bundleCfg.AttachOpts.Files = args[1:]
+		configureZarf()
+		bndlClient := bundle.NewOrDie(&bundleCfg)
+		defer bndlClient.ClearPaths()
+
+		if err := bndlClient.Attach(); err != nil {
+			bndlClient.ClearPaths()
+			message.Fatalf(err, "Failed to attach to bundle: %s", err.Error())
+		}
+	},
+}
+
 var pullCmd = &cobra.Command{
 	Use:     "pull [OCI_REF]",
 	Aliases: []string{"p"},
@@ -141,10 +213,14 @@ var pullCmd = &cobra.Command{
 		bndlClient := bundle.NewOrDie(&bundleCfg)
 		defer bndlClient.ClearPaths()
 
-		if err := bndlClient.Pull(); err != nil {
+		startEvents("pull", bundleCfg.PullOpts.Source)
+		err := bndlClient.Pull()
+		events.Finish(err)
+		if err != nil {
 			bndlClient.ClearPaths()
 			message.Fatalf(err, "Failed to pull bundle: %s", err.Error())
 		}
+		enforceCacheSize()
 	},
 }
 
@@ -182,8 +258,11 @@ func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdBundleRemoveFlagConfirm)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.Output, "output", "o", v.GetString(V_BNDL_CREATE_OUTPUT), lang.CmdBundleCreateFlagOutput)
-	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.SigningKeyPath, "signing-key", "k", v.GetString(V_BNDL_CREATE_SIGNING_KEY), lang.CmdBundleCreateFlagSigningKey)
+	createCmd.Flags().StringSliceVarP(&bundleCfg.CreateOpts.SigningKeyPaths, "signing-key", "k", v.GetStringSlice(V_BNDL_CREATE_SIGNING_KEY), lang.CmdBundleCreateFlagSigningKey)
 	createCmd.Flags().StringVarP(&bundleCfg.CreateOpts.SigningKeyPassword, "signing-key-password", "p", v.GetString(V_BNDL_CREATE_SIGNING_KEY_PASSWORD), lang.CmdBundleCreateFlagSigningKeyPassword)
+	createCmd.Flags().StringArrayVar(&bundleCfg.CreateOpts.Tags, "tag", []string{}, lang.CmdBundleFlagTag)
+	createCmd.Flags().StringVar(&bundleCfg.CreateOpts.Repository, "repository", "", lang.CmdBundleFlagRepository)
+	createCmd.Flags().StringVar(&eventsPath, "events", "", lang.CmdBundleFlagEvents)
 
 	// deploy cmd flags
 	rootCmd.AddCommand(deployCmd)
@@ -192,12 +271,30 @@ func init() {
 	deployCmd.Flags().StringArrayVarP(&bundleCfg.DeployOpts.Packages, "packages", "p", []string{}, lang.CmdBundleDeployFlagPackages)
 	deployCmd.Flags().BoolVarP(&bundleCfg.DeployOpts.Resume, "resume", "r", false, lang.CmdBundleDeployFlagResume)
 	deployCmd.Flags().IntVar(&bundleCfg.DeployOpts.Retries, "retries", 3, lang.CmdBundleDeployFlagRetries)
+	deployCmd.Flags().IntVar(&bundleCfg.DeployOpts.Concurrency, "deploy-concurrency", 1, lang.CmdBundleDeployFlagConcurrency)
+	deployCmd.Flags().BoolVar(&bundleCfg.DeployOpts.RollbackOnFailure, "rollback-on-failure", false, lang.CmdBundleDeployFlagRollbackOnFailure)
+	deployCmd.Flags().BoolVar(&bundleCfg.DeployOpts.Force, "force", false, lang.CmdBundleDeployFlagForce)
+	deployCmd.Flags().BoolVar(&bundleCfg.DeployOpts.DryRun, "dry-run", false, lang.CmdBundleDeployFlagDryRun)
+	deployCmd.Flags().StringVar(&bundleCfg.DeployOpts.PlanOut, "plan-out", "", lang.CmdBundleDeployFlagPlanOut)
+	deployCmd.Flags().StringVar(&bundleCfg.DeployOpts.PlanFile, "plan", "", lang.CmdBundleDeployFlagPlan)
+	deployCmd.Flags().StringVar(&bundleCfg.DeployOpts.ReportPath, "report", "", lang.CmdBundleDeployFlagReport)
+	deployCmd.Flags().StringVar(&eventsPath, "events", "", lang.CmdBundleFlagEvents)
+	deployCmd.Flags().BoolVar(&config.CommonOptions.NoSearch, "no-search", false, lang.CmdBundleFlagNoSearch)
+	deployCmd.Flags().StringSliceVarP(&bundleCfg.DeployOpts.PublicKeyPaths, "key", "k", v.GetStringSlice(V_BNDL_DEPLOY_KEY), lang.CmdBundleDeployFlagKey)
+	deployCmd.Flags().IntVar(&bundleCfg.DeployOpts.KeyThreshold, "key-threshold", v.GetInt(V_BNDL_DEPLOY_KEY_THRESHOLD), lang.CmdBundleFlagKeyThreshold)
+	deployCmd.Flags().BoolVar(&bundleCfg.DeployOpts.RequireManifestSignature, "require-manifest-signature", v.GetBool(V_BNDL_DEPLOY_REQUIRE_MANIFEST_SIGNATURE), lang.CmdBundleFlagRequireManifestSignature)
+	deployCmd.MarkFlagsMutuallyExclusive("dry-run", "plan")
+	deployCmd.MarkFlagsMutuallyExclusive("dry-run", "report")
 
 	// inspect cmd flags
 	rootCmd.AddCommand(inspectCmd)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.IncludeSBOM, "sbom", "s", false, lang.CmdPackageInspectFlagSBOM)
 	inspectCmd.Flags().BoolVarP(&bundleCfg.InspectOpts.ExtractSBOM, "extract", "e", false, lang.CmdPackageInspectFlagExtractSBOM)
-	inspectCmd.Flags().StringVarP(&bundleCfg.InspectOpts.PublicKeyPath, "key", "k", v.GetString(V_BNDL_INSPECT_KEY), lang.CmdBundleInspectFlagKey)
+	inspectCmd.Flags().StringSliceVarP(&bundleCfg.InspectOpts.PublicKeyPaths, "key", "k", v.GetStringSlice(V_BNDL_INSPECT_KEY), lang.CmdBundleInspectFlagKey)
+	inspectCmd.Flags().IntVar(&bundleCfg.InspectOpts.KeyThreshold, "key-threshold", v.GetInt(V_BNDL_INSPECT_KEY_THRESHOLD), lang.CmdBundleFlagKeyThreshold)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.RequireManifestSignature, "require-manifest-signature", v.GetBool(V_BNDL_INSPECT_REQUIRE_MANIFEST_SIGNATURE), lang.CmdBundleFlagRequireManifestSignature)
+	inspectCmd.Flags().BoolVar(&config.CommonOptions.NoSearch, "no-search", false, lang.CmdBundleFlagNoSearch)
+	inspectCmd.Flags().BoolVar(&bundleCfg.InspectOpts.ListReferrers, "referrers", false, lang.CmdBundleInspectFlagReferrers)
 
 	// remove cmd flags
 	rootCmd.AddCommand(removeCmd)
@@ -205,14 +302,40 @@ func init() {
 	removeCmd.Flags().BoolVarP(&config.CommonOptions.Confirm, "confirm", "c", false, lang.CmdBundleRemoveFlagConfirm)
 	_ = removeCmd.MarkFlagRequired("confirm")
 	removeCmd.Flags().StringArrayVarP(&bundleCfg.RemoveOpts.Packages, "packages", "p", []string{}, lang.CmdBundleRemoveFlagPackages)
+	removeCmd.Flags().StringVar(&bundleCfg.RemoveOpts.BundleName, "bundle", "", lang.CmdBundleRemoveFlagBundle)
+	removeCmd.Flags().StringVar(&bundleCfg.RemoveOpts.ReportPath, "report", "", lang.CmdBundleRemoveFlagReport)
+	removeCmd.Flags().StringVar(&eventsPath, "events", "", lang.CmdBundleFlagEvents)
+	removeCmd.Flags().BoolVar(&config.CommonOptions.NoSearch, "no-search", false, lang.CmdBundleFlagNoSearch)
+
+	// list and status cmds
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(statusCmd)
 
 	// publish cmd flags
 	rootCmd.AddCommand(publishCmd)
+	publishCmd.Flags().StringArrayVar(&bundleCfg.PublishOpts.Tags, "tag", []string{}, lang.CmdBundleFlagTag)
+	publishCmd.Flags().StringVar(&bundleCfg.PublishOpts.Repository, "repository", "", lang.CmdBundleFlagRepository)
+	publishCmd.Flags().StringVar(&eventsPath, "events", "", lang.CmdBundleFlagEvents)
+
+	// attach cmd flags
+	rootCmd.AddCommand(attachCmd)
+	attachCmd.Flags().StringVarP(&bundleCfg.AttachOpts.ArtifactType, "artifact-type", "t", "", lang.CmdBundleAttachFlagArtifactType)
+	_ = attachCmd.MarkFlagRequired("artifact-type")
 
 	// pull cmd flags
 	rootCmd.AddCommand(pullCmd)
 	pullCmd.Flags().StringVarP(&bundleCfg.PullOpts.OutputDirectory, "output", "o", v.GetString(V_BNDL_PULL_OUTPUT), lang.CmdBundlePullFlagOutput)
-	pullCmd.Flags().StringVarP(&bundleCfg.PullOpts.PublicKeyPath, "key", "k", v.GetString(V_BNDL_PULL_KEY), lang.CmdBundlePullFlagKey)
+	pullCmd.Flags().StringSliceVarP(&bundleCfg.PullOpts.PublicKeyPaths, "key", "k", v.GetStringSlice(V_BNDL_PULL_KEY), lang.CmdBundlePullFlagKey)
+	pullCmd.Flags().IntVar(&bundleCfg.PullOpts.KeyThreshold, "key-threshold", v.GetInt(V_BNDL_PULL_KEY_THRESHOLD), lang.CmdBundleFlagKeyThreshold)
+	pullCmd.Flags().BoolVar(&bundleCfg.PullOpts.RequireManifestSignature, "require-manifest-signature", v.GetBool(V_BNDL_PULL_REQUIRE_MANIFEST_SIGNATURE), lang.CmdBundleFlagRequireManifestSignature)
+	pullCmd.Flags().StringVar(&bundleCfg.PullOpts.Format, "format", types.PullFormatTarball, lang.CmdBundlePullFlagFormat)
+	pullCmd.Flags().StringArrayVarP(&bundleCfg.PullOpts.Packages, "packages", "p", []string{}, lang.CmdBundlePullFlagPackages)
+	pullCmd.Flags().StringSliceVar(&bundleCfg.PullOpts.SigningKeyPaths, "signing-key", []string{}, lang.CmdBundlePullFlagSigningKey)
+	pullCmd.Flags().StringVar(&bundleCfg.PullOpts.SigningKeyPassword, "signing-key-password", "", lang.CmdBundlePullFlagSigningKeyPassword)
+	pullCmd.Flags().BoolVar(&bundleCfg.PullOpts.KeepSignature, "keep-signature", false, lang.CmdBundlePullFlagKeepSignature)
+	pullCmd.Flags().BoolVar(&bundleCfg.PullOpts.Referrers, "referrers", false, lang.CmdBundlePullFlagReferrers)
+	pullCmd.Flags().StringVar(&eventsPath, "events", "", lang.CmdBundleFlagEvents)
+	pullCmd.Flags().BoolVar(&config.CommonOptions.NoSearch, "no-search", false, lang.CmdBundleFlagNoSearch)
 
 	// logs cmd
 	rootCmd.AddCommand(logsCmd)
@@ -45,6 +45,91 @@ optionx:
 			},
 			wantErr: true,
 		},
+		{
+			name: "Package options",
+			args: args{
+				configFile: []byte(`
+package_options:
+  postgres:
+    timeout: 40m
+    retries: 5
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Package options typo",
+			args: args{
+				configFile: []byte(`
+package_options:
+  postgres:
+    timeoutx: 40m
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Registry mirrors",
+			args: args{
+				configFile: []byte(`
+registry_mirrors:
+  - prefix: ghcr.io/
+    mirrors:
+      - registry.enclave.mil/mirror/
+    skip_upstream: true
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Registry mirror without mirrors",
+			args: args{
+				configFile: []byte(`
+registry_mirrors:
+  - prefix: ghcr.io/
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr:     true,
+			errContains: "registry_mirrors[0] (ghcr.io/) must have at least one mirror",
+		},
+		{
+			name: "Registries",
+			args: args{
+				configFile: []byte(`
+registries:
+  registry.enclave.mil:
+    username: ci
+    password_env: REGISTRY_PASSWORD
+    ca_file: /etc/ssl/enclave-ca.pem
+    cert_file: /etc/ssl/client.pem
+    key_file: /etc/ssl/client-key.pem
+  localhost:5000:
+    plain_http: true
+  registry.lab:
+    insecure_skip_verify: true
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Registry password set twice",
+			args: args{
+				configFile: []byte(`
+registries:
+  registry.enclave.mil:
+    password: hunter2
+    password_file: /run/secrets/password
+`),
+				bundleCfg: &types.BundleConfig{},
+			},
+			wantErr:     true,
+			errContains: "registries.registry.enclave.mil: only one of password, password_env and password_file can be set",
+		},
 	}
 
 	for _, tt := range tests {
@@ -25,18 +25,29 @@ const (
 	V_TMP_DIR              = "options.tmp_dir"
 	V_INSECURE             = "options.insecure"
 	V_BNDL_OCI_CONCURRENCY = "options.oci_concurrency"
+	V_MAX_CACHE_SIZE       = "options.max_cache_size"
+	V_SEARCH_PATHS         = "options.search_paths"
 
 	// Bundle create config keys
 	V_BNDL_CREATE_OUTPUT               = "create.output"
 	V_BNDL_CREATE_SIGNING_KEY          = "create.signing-key"
 	V_BNDL_CREATE_SIGNING_KEY_PASSWORD = "create.signing-key-password"
 
+	// Bundle deploy config keys
+	V_BNDL_DEPLOY_KEY                        = "bundle.deploy.key"
+	V_BNDL_DEPLOY_KEY_THRESHOLD              = "bundle.deploy.key_threshold"
+	V_BNDL_DEPLOY_REQUIRE_MANIFEST_SIGNATURE = "bundle.deploy.require_manifest_signature"
+
 	// Bundle inspect config keys
-	V_BNDL_INSPECT_KEY = "bundle.inspect.key"
+	V_BNDL_INSPECT_KEY                        = "bundle.inspect.key"
+	V_BNDL_INSPECT_KEY_THRESHOLD              = "bundle.inspect.key_threshold"
+	V_BNDL_INSPECT_REQUIRE_MANIFEST_SIGNATURE = "bundle.inspect.require_manifest_signature"
 
 	// Bundle pull config keys
-	V_BNDL_PULL_OUTPUT = "bundle.pull.output"
-	V_BNDL_PULL_KEY    = "bundle.pull.key"
+	V_BNDL_PULL_OUTPUT                     = "bundle.pull.output"
+	V_BNDL_PULL_KEY                        = "bundle.pull.key"
+	V_BNDL_PULL_KEY_THRESHOLD              = "bundle.pull.key_threshold"
+	V_BNDL_PULL_REQUIRE_MANIFEST_SIGNATURE = "bundle.pull.require_manifest_signature"
 )
 
 var (
@@ -16,6 +16,9 @@ const (
 	// ZarfYAML is the string for zarf.yaml
 	ZarfYAML = "zarf.yaml"
 
+	// ZarfYAMLSignature is the name of the signature of a Zarf pkg's zarf.yaml
+	ZarfYAMLSignature = "zarf.yaml.sig"
+
 	// BlobsDir is the string for the blobs/sha256 dir in an OCI artifact
 	BlobsDir = "blobs/sha256"
 
@@ -37,9 +40,36 @@ const (
 	// BundleYAMLSignature is the name of the bundle's metadata signature file
 	BundleYAMLSignature = "uds-bundle.yaml.sig"
 
+	// BundleYAMLOriginal is the name of the original uds-bundle.yaml kept in bundles pulled with --packages and --keep-signature
+	BundleYAMLOriginal = "uds-bundle.original.yaml"
+
+	// BundleYAMLOriginalSignature is the name of the original uds-bundle.yaml's signature kept in bundles pulled with --packages and --keep-signature
+	BundleYAMLOriginalSignature = "uds-bundle.original.yaml.sig"
+
+	// BundleManifestPayload is the name of the signed payload that pins the digest of a bundle's root manifest
+	BundleManifestPayload = "uds-bundle.manifest.json"
+
+	// BundleManifestSignature is the name of the signature of a bundle's BundleManifestPayload
+	BundleManifestSignature = "uds-bundle.manifest.sig"
+
 	// PublicKeyFile is the name of the public key file
 	PublicKeyFile = "public.key"
 
+	// BundleReferrers is the name of the folder the artifacts attached to a bundle are extracted to
+	BundleReferrers = "bundle-referrers"
+
+	// BundleSignatureArtifactType is the artifact type of signatures attached to a bundle
+	BundleSignatureArtifactType = "application/vnd.uds.bundle.signature.v1"
+
+	// BundleSBOMArtifactType is the artifact type of SBOMs attached to a bundle
+	BundleSBOMArtifactType = "application/vnd.uds.bundle.sbom.v1"
+
+	// BundleAttestationArtifactType is the artifact type of attestations attached to a bundle
+	BundleAttestationArtifactType = "application/vnd.uds.bundle.attestation.v1"
+
+	// BundleReleaseNotesArtifactType is the artifact type of release notes attached to a bundle
+	BundleReleaseNotesArtifactType = "application/vnd.uds.bundle.release-notes.v1"
+
 	// ChecksumsTxt is the name of the checksums.txt file in a Zarf pkg
 	ChecksumsTxt = "checksums.txt"
 
@@ -49,6 +79,15 @@ const (
 	// UDSCacheLayers is the directory in the cache containing cached bundle layers
 	UDSCacheLayers = "layers"
 
+	// UDSCacheLayersOwners is the file in the cache recording which bundles use each cached layer
+	UDSCacheLayersOwners = "layers.json"
+
+	// UDSCacheLock is the file in the cache that processes lock to coordinate writes to the cache
+	UDSCacheLock = "cache.lock"
+
+	// UDSCachePackages is the directory in the cache containing the OCI store of pulled bundles
+	UDSCachePackages = "packages"
+
 	// EnvVarPrefix is the prefix for environment variables to override bundle helm variables
 	EnvVarPrefix = "UDS_"
 
@@ -96,7 +135,7 @@ func GetArch(archs ...string) string {
 
 var (
 	// BundleAlwaysPull is a list of paths that will always be pulled from the remote repository.
-	BundleAlwaysPull = []string{BundleYAML, BundleYAMLSignature}
+	BundleAlwaysPull = []string{BundleYAML, BundleYAMLSignature, BundleYAMLOriginal, BundleYAMLOriginalSignature}
 )
 
 // DefaultZarfInitOptions set these in the case of deploying a Zarf init pkg
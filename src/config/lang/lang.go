@@ -20,42 +20,75 @@ const (
 	CmdBundleLogsShort = "View most recent UDS CLI logs"
 
 	// bundle
-	CmdBundleShort           = "Commands for creating, deploying, removing, pulling, and inspecting bundles"
-	CmdBundleFlagConcurrency = "Number of concurrent layer operations to perform when interacting with a remote bundle."
+	CmdBundleShort                        = "Commands for creating, deploying, removing, pulling, and inspecting bundles"
+	CmdBundleFlagConcurrency              = "Number of concurrent layer operations to perform when interacting with a remote bundle."
+	CmdBundleFlagEvents                   = "Write a JSON-lines stream of events (ie. packages started and finished, layers pulled, errors) to a file, or to stdout with '-'"
+	CmdBundleFlagTag                      = "Tag to publish the bundle with, can be repeated to publish several tags. Defaults to the bundle's version"
+	CmdBundleFlagRepository               = "Name of the repository to publish the bundle to. Defaults to the bundle's name"
+	CmdBundleFlagKeyThreshold             = "Number of the keys passed with --key that must have signed the bundle"
+	CmdBundleFlagRequireManifestSignature = "Refuse bundles where only the uds-bundle.yaml is signed, since that signature doesn't cover the bundle's packages"
+	CmdBundleFlagNoSearch                 = "Require a fully qualified bundle ref instead of searching the search_paths in the uds-config for it"
 
 	// bundle create
 	CmdBundleCreateShort = "Create a bundle from a given directory or the current directory"
 	//CmdBundleCreateFlagConfirm            = "Confirm bundle creation without prompting"
 	CmdBundleCreateFlagOutput             = "Specify the output (an oci:// URL) for the created bundle"
-	CmdBundleCreateFlagSigningKey         = "Path to private key file for signing bundles"
+	CmdBundleCreateFlagSigningKey         = "Paths to private key files for signing bundles, comma separated or repeated. Each key signs the bundle's root manifest, which covers every package in the bundle"
 	CmdBundleCreateFlagSigningKeyPassword = "Password to the private key file used for signing bundles"
 
 	// bundle deploy
-	CmdBundleDeployShort        = "Deploy a bundle from a local tarball or oci:// URL"
-	CmdBundleDeployFlagConfirm  = "Confirms bundle deployment without prompting. ONLY use with bundles you trust. Skips prompts to review SBOM, configure variables, select optional components and review potential breaking changes."
-	CmdBundleDeployFlagPackages = "Specify which zarf packages you would like to deploy from the bundle. By default all zarf packages in the bundle are deployed."
-	CmdBundleDeployFlagResume   = "Only deploys packages from the bundle which haven't already been deployed"
-	CmdBundleDeployFlagSet      = "Specify deployment variables to set on the command line (KEY=value)"
-	CmdBundleDeployFlagRetries  = "Specify the number of retries for package deployments (applies to all pkgs in a bundle)"
+	CmdBundleDeployShort                 = "Deploy a bundle from a local tarball or oci:// URL"
+	CmdBundleDeployFlagConfirm           = "Confirms bundle deployment without prompting. ONLY use with bundles you trust. Skips prompts to review SBOM, configure variables, select optional components and review potential breaking changes."
+	CmdBundleDeployFlagPackages          = "Specify which zarf packages you would like to deploy from the bundle. By default all zarf packages in the bundle are deployed."
+	CmdBundleDeployFlagResume            = "Only deploys packages from the bundle which haven't been fully deployed at the version and digest in the bundle"
+	CmdBundleDeployFlagSet               = "Specify deployment variables to set on the command line (KEY=value)"
+	CmdBundleDeployFlagRetries           = "Specify the number of retries for package deployments (applies to all pkgs in a bundle)"
+	CmdBundleDeployFlagRollbackOnFailure = "Roll back the packages that were upgraded during the deploy and remove newly installed packages if any package fails to deploy"
+	CmdBundleDeployFlagForce             = "Redeploy every package, including packages that haven't changed since they were last deployed"
+	CmdBundleDeployFlagDryRun            = "Resolve variables and chart overrides and print what would be installed, upgraded or skipped without deploying anything"
+	CmdBundleDeployFlagPlanOut           = "Save the plan from --dry-run to a JSON file"
+	CmdBundleDeployFlagPlan              = "Deploy using a plan saved with --plan-out, refusing to deploy if the bundle, variables or overrides no longer match the plan"
+	CmdBundleDeployFlagReport            = "Write a report of the result, duration, components and Helm releases of each package to a file, as JUnit XML if the file ends in .xml and as JSON otherwise"
+	CmdBundleDeployFlagKey               = "Paths to public key files that will be used to validate a signed bundle before deploying it, comma separated or repeated"
+	CmdBundleDeployFlagConcurrency       = "Number of packages to deploy at the same time. Packages are only deployed once the packages they depend on have been deployed. When greater than 1, each package deploys in its own process and its output is printed line by line prefixed with the package name, without progress bars or spinners"
 
 	// bundle inspect
 	CmdBundleInspectShort            = "Display the metadata of a bundle"
-	CmdBundleInspectFlagKey          = "Path to a public key file that will be used to validate a signed bundle"
+	CmdBundleInspectFlagKey          = "Paths to public key files that will be used to validate a signed bundle, comma separated or repeated"
 	CmdPackageInspectFlagSBOM        = "Create a tarball of SBOMs contained in the bundle"
 	CmdPackageInspectFlagExtractSBOM = "Create a folder of SBOMs contained in the bundle"
+	CmdBundleInspectFlagReferrers    = "List the signatures, SBOMs, attestations and other artifacts attached to the bundle instead of its metadata. Use with --extract to save them to a folder"
 
 	// bundle remove
 	CmdBundleRemoveShort        = "Remove a bundle that has been deployed already"
 	CmdBundleRemoveFlagConfirm  = "REQUIRED. Confirm the removal action to prevent accidental deletions"
 	CmdBundleRemoveFlagPackages = "Specify which zarf packages you would like to remove from the bundle. By default all zarf packages in the bundle are removed."
+	CmdBundleRemoveFlagReport   = "Write a report of the result, duration, components and Helm releases of each removed package to a file, as JUnit XML if the file ends in .xml and as JSON otherwise"
+	CmdBundleRemoveFlagBundle   = "Name of a deployed bundle to remove using the package list recorded in the cluster instead of a bundle tarball or OCI ref"
+
+	// bundle list
+	CmdBundleListShort = "List the bundles that have been deployed to the cluster"
+
+	// bundle status
+	CmdBundleStatusShort = "Display the health and version of each package in a deployed bundle"
 
 	// bundle publish
-	CmdPublishShort = "Publish a bundle from the local file system to a remote registry"
+	CmdPublishShort = "Publish a bundle from the local file system or another registry to a remote registry"
+
+	// bundle attach
+	CmdBundleAttachShort            = "Attach files to a published bundle as an OCI referrer, such as a signature, SBOM, attestation or release notes"
+	CmdBundleAttachFlagArtifactType = "Type of the attached artifact, either signature, sbom, attestation, release-notes or a custom media type"
 
 	// bundle pull
-	CmdBundlePullShort      = "Pull a bundle from a remote registry and save to the local file system"
-	CmdBundlePullFlagOutput = "Specify the output directory for the pulled bundle"
-	CmdBundlePullFlagKey    = "Path to a public key file that will be used to validate a signed bundle"
+	CmdBundlePullShort                  = "Pull a bundle from a remote registry and save to the local file system"
+	CmdBundlePullFlagOutput             = "Specify the output directory for the pulled bundle"
+	CmdBundlePullFlagKey                = "Paths to public key files that will be used to validate a signed bundle, comma separated or repeated"
+	CmdBundlePullFlagFormat             = "Format to save the pulled bundle as, either 'tarball' for a .tar.zst or 'oci-layout' for an OCI image layout directory that can be inspected, deployed, removed and published without extracting it"
+	CmdBundlePullFlagPackages           = "Specify which zarf packages you would like to pull from the bundle. By default all zarf packages in the bundle are pulled."
+	CmdBundlePullFlagSigningKey         = "Paths to private key files used to sign the bundle pulled with --packages, comma separated or repeated"
+	CmdBundlePullFlagSigningKeyPassword = "Password to the private key file used to sign the bundle pulled with --packages"
+	CmdBundlePullFlagKeepSignature      = "Keep the original signature of a signed bundle pulled with --packages. The original uds-bundle.yaml is kept alongside the pulled one and is verified instead when using --key"
+	CmdBundlePullFlagReferrers          = "Also pull the signatures, SBOMs, attestations and other artifacts attached to the bundle, so they are published with it"
 
 	// cmd viper setup
 	CmdViperErrLoadingConfigFile = "failed to load config file: %s"
@@ -69,10 +102,26 @@ const (
 	CmdVersionShort = "Shows the version of the running UDS-CLI binary"
 	CmdVersionLong  = "Displays the version of the UDS-CLI release that the current binary was built from."
 
+	// uds cache
+	CmdCacheShort              = "Manage the UDS cache of image layers and pulled bundles"
+	CmdCacheListShort          = "List the files in the UDS cache with their size, last use and the bundles using them"
+	CmdCachePruneShort         = "Remove the least recently used files from the UDS cache"
+	CmdCachePruneFlagOlderThan = "Remove files that haven't been used for longer than this duration (ie. 72h, 30d)"
+	CmdCachePruneFlagMaxSize   = "Remove the least recently used files until the cache is no larger than this size (ie. 500MB, 10GiB)"
+	CmdCachePruneErrNoLimits   = "at least one of --older-than or --max-size must be set"
+	CmdCacheClearShort         = "Remove every image layer and pulled bundle from the UDS cache"
+	CmdCacheClearFlagConfirm   = "Confirm clearing the cache without prompting"
+	CmdCacheClearPrompt        = "Clear the UDS cache at %s?"
+	CmdCacheErrEnforceMaxSize  = "Failed to prune the UDS cache to max_cache_size: %s"
+
 	// uds-cli internal
-	CmdInternalShort             = "Internal cmds used by UDS-CLI"
-	CmdInternalConfigSchemaShort = "Generates a JSON schema for the uds-bundle.yaml configuration"
-	CmdInternalConfigSchemaErr   = "Unable to generate the uds-bundle.yaml schema"
+	CmdInternalShort              = "Internal cmds used by UDS-CLI"
+	CmdInternalConfigSchemaShort  = "Generates a JSON schema for the uds-bundle.yaml configuration"
+	CmdInternalConfigSchemaErr    = "Unable to generate the uds-bundle.yaml schema"
+	CmdInternalEventsSchemaShort  = "Generates a JSON schema for the events written with --events"
+	CmdInternalEventsSchemaErr    = "Unable to generate the events schema"
+	CmdInternalDeployPackageShort = "Deploys a single package from a bundle for a concurrent bundle deploy, reading the package from stdin"
+	CmdInternalDeployPackageErr   = "Failed to deploy package: %s"
 
 	// uds run
 	CmdRunShort = "Run a task using maru-runner"
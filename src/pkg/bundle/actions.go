@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/actions"
+	"github.com/defenseunicorns/zarf/src/pkg/variables"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+)
+
+// actionResult is the outcome of a single bundle or package lifecycle action
+type actionResult struct {
+	// scope is the name of the bundle or package the action belongs to
+	scope       string
+	hook        string
+	description string
+	duration    time.Duration
+	err         error
+}
+
+// actionLog collects the results of lifecycle actions so they can be reported once an operation finishes
+type actionLog struct {
+	mu      sync.Mutex
+	results []actionResult
+}
+
+func (l *actionLog) add(result actionResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, result)
+}
+
+// print shows a table of the actions that ran, if any
+func (l *actionLog) print() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.results) == 0 {
+		return
+	}
+	var rows [][]string
+	for _, r := range l.results {
+		result := "succeeded"
+		if r.err != nil {
+			result = fmt.Sprintf("failed: %s", r.err.Error())
+		}
+		rows = append(rows, []string{r.scope, r.hook, r.description, r.duration.Round(time.Second).String(), result})
+	}
+	message.Table([]string{"Scope", "Hook", "Action", "Duration", "Result"}, rows)
+}
+
+// runActions runs op wrapped in a set of lifecycle actions using the same semantics as Zarf component actions
+//
+// before actions run first, then op, then the after and onSuccess actions; if any of those fail the onFailure actions run
+// and the original error is returned. vars are exposed to the actions as ZARF_VAR_* environment variables and are re-read
+// before each phase, so op can add to them (ie. exported variables) for the after actions to use
+func runActions(log *actionLog, scope, hook string, set zarfTypes.ZarfComponentActionSet, vars map[string]string, op func() error) error {
+	variableConfig := variables.New("zarf", nil, nil, slog.New(message.ZarfHandler{}))
+	variableConfig.SetApplicationTemplates(make(map[string]*variables.TextTemplate))
+
+	run := func(phase string, list []zarfTypes.ZarfComponentAction) error {
+		for name, value := range vars {
+			variableConfig.SetVariable(name, value, false, false, variables.RawVariableType)
+		}
+		for _, action := range list {
+			description := action.Description
+			if description == "" && action.Wait != nil {
+				description = "wait"
+			} else if description == "" {
+				description = helpers.Truncate(action.Cmd, 60, false)
+			}
+			start := time.Now()
+			err := actions.Run(set.Defaults, []zarfTypes.ZarfComponentAction{action}, variableConfig)
+			log.add(actionResult{scope: scope, hook: fmt.Sprintf("%s.%s", hook, phase), description: description, duration: time.Since(start), err: err})
+			if err != nil {
+				return fmt.Errorf("%s %s.%s action %q failed: %w", scope, hook, phase, description, err)
+			}
+		}
+		return nil
+	}
+
+	err := run("before", set.Before)
+	if err == nil {
+		err = op()
+	}
+	if err == nil {
+		err = run("after", set.After)
+	}
+	if err == nil {
+		err = run("onSuccess", set.OnSuccess)
+	}
+	if err != nil {
+		if failureErr := run("onFailure", set.OnFailure); failureErr != nil {
+			message.WarnErr(failureErr, failureErr.Error())
+		}
+		return err
+	}
+	return nil
+}
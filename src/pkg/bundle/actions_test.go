@@ -0,0 +1,64 @@
+package bundle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunActions(t *testing.T) {
+	t.Run("actions run around the operation with variables", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		set := zarfTypes.ZarfComponentActionSet{
+			Before: []zarfTypes.ZarfComponentAction{{Cmd: "echo before-$ZARF_VAR_FOO >> " + out}},
+			After:  []zarfTypes.ZarfComponentAction{{Cmd: "echo after-$ZARF_VAR_EXPORTED >> " + out}},
+		}
+		vars := map[string]string{"FOO": "bar"}
+		var log actionLog
+		err := runActions(&log, "pkg", "onDeploy", set, vars, func() error {
+			vars["EXPORTED"] = "value"
+			return nil
+		})
+		require.NoError(t, err)
+		contents, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "before-bar\nafter-value\n", string(contents))
+		require.Len(t, log.results, 2)
+		require.Equal(t, "onDeploy.before", log.results[0].hook)
+	})
+
+	t.Run("onFailure runs when the operation fails", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out")
+		set := zarfTypes.ZarfComponentActionSet{
+			After:     []zarfTypes.ZarfComponentAction{{Cmd: "echo after >> " + out}},
+			OnFailure: []zarfTypes.ZarfComponentAction{{Cmd: "echo failure >> " + out}},
+		}
+		var log actionLog
+		err := runActions(&log, "pkg", "onDeploy", set, nil, func() error {
+			return errors.New("deploy failed")
+		})
+		require.EqualError(t, err, "deploy failed")
+		contents, err := os.ReadFile(out)
+		require.NoError(t, err)
+		require.Equal(t, "failure\n", string(contents))
+	})
+
+	t.Run("a failed before action skips the operation", func(t *testing.T) {
+		set := zarfTypes.ZarfComponentActionSet{
+			Before: []zarfTypes.ZarfComponentAction{{Cmd: "exit 1"}},
+		}
+		called := false
+		var log actionLog
+		err := runActions(&log, "pkg", "onDeploy", set, nil, func() error {
+			called = true
+			return nil
+		})
+		require.ErrorContains(t, err, "onDeploy.before")
+		require.False(t, called)
+		require.Error(t, log.results[0].err)
+	})
+}
@@ -18,11 +18,11 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/cluster"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -35,6 +35,14 @@ type Bundle struct {
 	bundle types.UDSBundle
 	// tmp is the temporary directory used by the Bundle cleaned up with ClearPaths()
 	tmp string
+	// rootDigest is the digest of the bundle's root manifest, set when deploying
+	rootDigest string
+	// configKey is the key package configs are hashed with, read from the bundle's state secret when deploying
+	configKey []byte
+	// deployPlan is the deploy plan loaded from --plan, nil if the deploy isn't checked against a plan
+	deployPlan *types.DeployPlan
+	// report is the --report of a deploy, started by PreDeployValidation so that the deploy is reported however early it fails
+	report *types.Report
 }
 
 // New creates a new Bundle
@@ -104,12 +112,17 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 		return fmt.Errorf("error validating bundle vars: %s", err)
 	}
 
+	if err := validateDependencies(bundle.Packages); err != nil {
+		return fmt.Errorf("error validating package dependencies: %s", err)
+	}
+
 	// validate access to packages as well as components referenced in the package
+	var verified []string
 	for idx, pkg := range bundle.Packages {
 
 		spinner.Updatef("Validating Bundle Package: %s", pkg.Name)
 		if pkg.Name == "" {
-			return fmt.Errorf("%s is missing required field: name", pkg)
+			return fmt.Errorf("%v is missing required field: name", pkg)
 		}
 
 		if pkg.Repository == "" && pkg.Path == "" {
@@ -123,6 +136,12 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 		if pkg.Ref == "" {
 			return fmt.Errorf("%s .packages[%s] is missing required field: ref", config.BundleYAML, pkg.Repository)
 		}
+
+		if pkg.Timeout != "" {
+			if _, err := time.ParseDuration(pkg.Timeout); err != nil {
+				return fmt.Errorf("%s .packages[%s].timeout is not a valid duration: %s", config.BundleYAML, pkg.Name, err)
+			}
+		}
 		var zarfYAML zarfTypes.ZarfPackage
 		var url string
 		// if using a remote repository
@@ -137,7 +156,7 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 				Architecture: config.GetArch(),
 				OS:           oci.MultiOS,
 			}
-			remote, err := zoci.NewRemote(url, platform)
+			remote, err := boci.NewMirroredRemote(context.TODO(), url, platform)
 			if err != nil {
 				return err
 			}
@@ -173,13 +192,14 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 
 		message.Debug("Validating package:", message.JSONValue(pkg))
 
-		// todo: need to packager.ValidatePackageSignature (or come up with a bundle-level signature scheme)
-		publicKeyPath := filepath.Join(b.tmp, config.PublicKeyFile)
 		if pkg.PublicKey != "" {
-			if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
+			ok, err := verifyPkgSignature(f, pkg, b.tmp)
+			if err != nil {
 				return err
 			}
-			defer os.Remove(publicKeyPath)
+			if ok {
+				verified = append(verified, pkg.Name)
+			}
 		}
 
 		if len(pkg.OptionalComponents) > 0 {
@@ -205,9 +225,29 @@ func (b *Bundle) ValidateBundleResources(spinner *message.Spinner) error {
 		}
 
 	}
+	// record which packages were verified so it shows up in the bundle's build data
+	bundle.Build.VerifiedPackages = verified
 	return nil
 }
 
+// verifyPkgSignature verifies the signature of a package in the bundle with the package's publicKey, returning false if verification was skipped
+func verifyPkgSignature(f fetcher.Fetcher, pkg types.Package, tmp string) (bool, error) {
+	if config.CommonOptions.Insecure {
+		message.Warnf("Skipping signature verification of package %s because --insecure is set", pkg.Name)
+		return false, nil
+	}
+	publicKeyPath := filepath.Join(tmp, config.PublicKeyFile)
+	if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
+		return false, err
+	}
+	defer os.Remove(publicKeyPath)
+	if err := f.VerifyPkgSignature(publicKeyPath); err != nil {
+		return false, fmt.Errorf("unable to verify the signature of package %s: %w", pkg.Name, err)
+	}
+	message.Debugf("Verified the signature of package %s", pkg.Name)
+	return true, nil
+}
+
 func getPkgPath(pkg types.Package, arch string, srcDir string) string {
 	var fullPkgName string
 	var path string
@@ -287,6 +327,23 @@ func GetDeployedPackageNames() []string {
 	return deployedPackageNames
 }
 
+// getDeployedPackages returns the Zarf deployment records of the packages deployed to the cluster by name
+func getDeployedPackages() (map[string]*zarfTypes.DeployedPackage, error) {
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return nil, err
+	}
+	packages, err := c.GetDeployedZarfPackages(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	deployedPackages := make(map[string]*zarfTypes.DeployedPackage)
+	for i := range packages {
+		deployedPackages[packages[i].Name] = &packages[i]
+	}
+	return deployedPackages, nil
+}
+
 // validateOverrides ensures that the overrides have matching components and charts in the zarf package
 func validateOverrides(pkg types.Package, zarfYAML zarfTypes.ZarfPackage) error {
 	for componentName, chartsValues := range pkg.Overrides {
@@ -1,10 +1,15 @@
 package bundle
 
 import (
+	"errors"
+	"os"
 	"testing"
 
+	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	zarfSources "github.com/defenseunicorns/zarf/src/pkg/packager/sources"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/require"
 )
 
@@ -217,3 +222,57 @@ func Test_getPkgPath(t *testing.T) {
 		})
 	}
 }
+
+// fakeFetcher is a fetcher.Fetcher that verifies package signatures with verify
+type fakeFetcher struct {
+	verify func(publicKeyPath string) error
+}
+
+func (f *fakeFetcher) Fetch() ([]ocispec.Descriptor, error) {
+	return nil, nil
+}
+
+func (f *fakeFetcher) GetPkgMetadata() (zarfTypes.ZarfPackage, error) {
+	return zarfTypes.ZarfPackage{}, nil
+}
+
+func (f *fakeFetcher) VerifyPkgSignature(publicKeyPath string) error {
+	return f.verify(publicKeyPath)
+}
+
+func Test_verifyPkgSignature(t *testing.T) {
+	pkg := types.Package{Name: "nginx", PublicKey: "public key"}
+	tests := []struct {
+		name      string
+		insecure  bool
+		verifyErr error
+		verified  bool
+		err       string
+	}{
+		{name: "valid signature", verified: true},
+		{name: "unsigned package", verifyErr: zarfSources.ErrPkgKeyButNoSig, err: "unable to verify the signature of package nginx"},
+		{name: "invalid signature", verifyErr: errors.New("invalid signature"), err: "invalid signature"},
+		{name: "insecure skips verification", insecure: true, verifyErr: errors.New("not called")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insecure := config.CommonOptions.Insecure
+			config.CommonOptions.Insecure = tt.insecure
+			defer func() { config.CommonOptions.Insecure = insecure }()
+
+			f := &fakeFetcher{verify: func(publicKeyPath string) error {
+				key, err := os.ReadFile(publicKeyPath)
+				require.NoError(t, err)
+				require.Equal(t, pkg.PublicKey, string(key))
+				return tt.verifyErr
+			}}
+			verified, err := verifyPkgSignature(f, pkg, t.TempDir())
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.verified, verified)
+		})
+	}
+}
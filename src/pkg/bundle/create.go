@@ -11,10 +11,10 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	zarfConfig "github.com/defenseunicorns/zarf/src/config"
-	"github.com/defenseunicorns/zarf/src/pkg/interactive"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
 	"github.com/pterm/pterm"
@@ -23,11 +23,16 @@ import (
 
 // Create creates a bundle
 func (b *Bundle) Create() error {
+	// tags and repositories only apply to bundles created in a registry
+	if (len(b.cfg.CreateOpts.Tags) > 0 || b.cfg.CreateOpts.Repository != "") && !utils.IsRegistryURL(b.cfg.CreateOpts.Output) {
+		return fmt.Errorf("--tag and --repository can only be used when the output is an OCI registry")
+	}
 
 	// read the bundle's metadata into memory
 	if err := utils.ReadYAMLStrict(filepath.Join(b.cfg.CreateOpts.SourceDirectory, b.cfg.CreateOpts.BundleFile), &b.bundle); err != nil {
 		return err
 	}
+	events.SetBundle(b.bundle.Metadata.Name)
 
 	// Populate values from valuesFiles if provided
 	if err := b.processValuesFiles(); err != nil {
@@ -64,33 +69,16 @@ func (b *Bundle) Create() error {
 	validateSpinner.Successf("Bundle Validated")
 	pterm.Print()
 
-	// sign the bundle if a signing key was provided
-	if b.cfg.CreateOpts.SigningKeyPath != "" {
-		// write the bundle to disk so we can sign it
-		bundlePath := filepath.Join(b.tmp, config.BundleYAML)
-		if err := zarfUtils.WriteYaml(bundlePath, &b.bundle, 0600); err != nil {
-			return err
-		}
-
-		getSigCreatePassword := func(_ bool) ([]byte, error) {
-			if b.cfg.CreateOpts.SigningKeyPassword != "" {
-				return []byte(b.cfg.CreateOpts.SigningKeyPassword), nil
-			}
-			return interactive.PromptSigPassword()
-		}
-		// sign the bundle
-		signaturePath := filepath.Join(b.tmp, config.BundleYAMLSignature)
-		_, err := zarfUtils.CosignSignBlob(bundlePath, signaturePath, b.cfg.CreateOpts.SigningKeyPath, getSigCreatePassword)
-		if err != nil {
-			return err
-		}
-	}
-
 	opts := bundler.Options{
-		Bundle:    &b.bundle,
-		Output:    b.cfg.CreateOpts.Output,
-		TmpDstDir: b.tmp,
-		SourceDir: b.cfg.CreateOpts.SourceDirectory,
+		Bundle:     &b.bundle,
+		Output:     b.cfg.CreateOpts.Output,
+		TmpDstDir:  b.tmp,
+		SourceDir:  b.cfg.CreateOpts.SourceDirectory,
+		Tags:       b.cfg.CreateOpts.Tags,
+		Repository: b.cfg.CreateOpts.Repository,
+		// the bundle is signed once its root manifest is built, so the signatures cover every package in it
+		SigningKeyPaths:    b.cfg.CreateOpts.SigningKeyPaths,
+		SigningKeyPassword: signingKeyPassword(b.cfg.CreateOpts.SigningKeyPassword),
 	}
 	bundlerClient := bundler.NewBundler(&opts)
 	return bundlerClient.Create()
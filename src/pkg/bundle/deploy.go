@@ -8,23 +8,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/state"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	zarfConfig "github.com/defenseunicorns/zarf/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/layout"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/defenseunicorns/zarf/src/pkg/packager"
 	"github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/defenseunicorns/zarf/src/pkg/variables"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	goyaml "github.com/goccy/go-yaml"
 	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
 )
@@ -36,120 +44,538 @@ type PkgOverrideMap map[string]map[string]map[string]interface{}
 var templatedVarRegex = regexp.MustCompile(`\${([^}]+)}`)
 
 // Deploy deploys a bundle
-func (b *Bundle) Deploy() error {
-	resume := b.cfg.DeployOpts.Resume
+func (b *Bundle) Deploy() (err error) {
+	b.startDeployReport()
+	var recorder *stateRecorder
+	defer func() { b.writeDeployReport(recorder, err) }()
+
+	packagesToDeploy, err := b.selectPackages()
+	if err != nil {
+		return err
+	}
+
+	// refuse to deploy if anything changed since the plan was made, before anything is written to the cluster
+	if b.cfg.DeployOpts.PlanFile != "" {
+		if err := b.verifyPlan(packagesToDeploy); err != nil {
+			return err
+		}
+	}
+	if err := b.loadConfigKey(); err != nil {
+		return err
+	}
+
+	recorder = b.newStateRecorder(packagesToDeploy)
+	return deployPackages(packagesToDeploy, b, recorder)
+}
+
+// selectPackages returns the packages to deploy based on the --packages and --resume flags, sorted so that each package comes after its dependencies
+func (b *Bundle) selectPackages() ([]types.Package, error) {
+	packages := b.bundle.Packages
 
 	// Check if --packages flag is set and zarf packages have been specified
-	var packagesToDeploy []types.Package
 	if len(b.cfg.DeployOpts.Packages) != 0 {
 		userSpecifiedPackages := strings.Split(strings.ReplaceAll(b.cfg.DeployOpts.Packages[0], " ", ""), ",")
 
+		packages = nil
 		for _, pkg := range b.bundle.Packages {
 			if slices.Contains(userSpecifiedPackages, pkg.Name) {
-				packagesToDeploy = append(packagesToDeploy, pkg)
+				packages = append(packages, pkg)
 			}
 		}
 
 		// Check if invalid packages were specified
-		if len(userSpecifiedPackages) != len(packagesToDeploy) {
-			return fmt.Errorf("invalid zarf packages specified by --packages")
+		if len(userSpecifiedPackages) != len(packages) {
+			return nil, fmt.Errorf("invalid zarf packages specified by --packages")
 		}
-		return deployPackages(packagesToDeploy, resume, b)
 	}
 
-	return deployPackages(b.bundle.Packages, resume, b)
-}
-
-func deployPackages(packages []types.Package, resume bool, b *Bundle) error {
-	// map of Zarf pkgs and their vars
-	bundleExportedVars := make(map[string]map[string]string)
-
 	var packagesToDeploy []types.Package
-
-	if resume {
-		deployedPackageNames := GetDeployedPackageNames()
+	if b.cfg.DeployOpts.Resume {
+		deployedPackages, err := getDeployedPackages()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the packages deployed to the cluster to resume the deploy: %w", err)
+		}
+		var previous *types.BundleState
+		if client, err := state.NewClient(); err == nil {
+			previous = loadPreviousState(client, b.bundle.Metadata.Name)
+		}
+		recorder := &stateRecorder{previous: previous}
 		for _, pkg := range packages {
-			if !slices.Contains(deployedPackageNames, pkg.Name) {
-				packagesToDeploy = append(packagesToDeploy, pkg)
+			var recorded *types.PackageState
+			if prev, ok := recorder.previousPackage(pkg.Name); ok {
+				recorded = &prev
+			}
+			var version string
+			if deployedPackages[pkg.Name] != nil {
+				if version, err = b.zarfPackageVersion(pkg, recorded); err != nil {
+					return nil, err
+				}
+			}
+			reason := resumeReason(pkg, version, deployedPackages[pkg.Name], recorded)
+			if reason == "" {
+				message.Infof("Skipping package %s, it is already deployed", pkg.Name)
+				continue
 			}
+			message.Infof("Resuming package %s, %s", pkg.Name, reason)
+			packagesToDeploy = append(packagesToDeploy, pkg)
 		}
 	} else {
 		packagesToDeploy = packages
 	}
 
-	// deploy each package
-	for _, pkg := range packagesToDeploy {
-		sha := strings.Split(pkg.Ref, "@sha256:")[1] // using appended SHA from create!
-		pkgTmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
-		if err != nil {
-			return err
+	// ensure packages are deployed after the packages they depend on
+	return sortPackages(packagesToDeploy)
+}
+
+// resumeReason returns why a package needs to be deployed when resuming a bundle deploy, or "" if it's fully deployed at the bundle's version
+//
+// version is the metadata.version of the Zarf package in the bundle, deployed is the package's deployment record from Zarf
+// and recorded is its record in the bundle's state, either can be nil
+func resumeReason(pkg types.Package, version string, deployed *zarfTypes.DeployedPackage, recorded *types.PackageState) string {
+	if deployed == nil {
+		return "it isn't deployed"
+	}
+	if deployedVersion := deployed.Data.Metadata.Version; deployedVersion != version {
+		return fmt.Sprintf("version %s is deployed but the bundle has %s", deployedVersion, version)
+	}
+	// Zarf doesn't record the digest of the package it deployed, so the digest comes from the bundle's state when there is one
+	if recorded != nil {
+		if digest := packageDigest(pkg); recorded.Digest != "" && digest != "" && recorded.Digest != digest {
+			return fmt.Sprintf("digest %s is deployed but the bundle has %s", recorded.Digest, digest)
+		}
+		if recorded.Status == types.StatusFailed || recorded.Status == types.StatusDeploying {
+			return "its last deploy didn't finish"
 		}
-		defer os.RemoveAll(pkgTmp)
+	}
+	if health := packageHealth(deployed); health != "Healthy" {
+		return fmt.Sprintf("it is partially deployed: %s", health)
+	}
+	return ""
+}
 
-		publicKeyPath := filepath.Join(b.tmp, config.PublicKeyFile)
-		if pkg.PublicKey != "" {
-			if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
-				return err
+// zarfPackageVersion returns the metadata.version of a Zarf package in the bundle, which can differ from its ref's tag
+// (flavored packages are tagged <version>-<flavor>)
+//
+// the version recorded in the bundle's state is used if the recorded package has the same digest, otherwise the version
+// is read from the package's zarf.yaml in the bundle
+func (b *Bundle) zarfPackageVersion(pkg types.Package, recorded *types.PackageState) (string, error) {
+	if recorded != nil && recorded.Version != "" && recorded.Digest != "" && recorded.Digest == packageDigest(pkg) {
+		return recorded.Version, nil
+	}
+	_, sha, ok := strings.Cut(pkg.Ref, "@sha256:")
+	if !ok {
+		return "", fmt.Errorf("the ref of package %s is missing its digest: %s", pkg.Name, pkg.Ref)
+	}
+	pkgTmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(pkgTmp)
+
+	source, err := sources.New(b.cfg.DeployOpts.Source, pkg, zarfTypes.ZarfPackageOptions{PackageSource: pkgTmp}, sha, nil)
+	if err != nil {
+		return "", err
+	}
+	zarfPkg, _, err := source.LoadPackageMetadata(layout.New(pkgTmp), false, true)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the version of package %s: %w", pkg.Name, err)
+	}
+	return zarfPkg.Metadata.Version, nil
+}
+
+func deployPackages(packagesToDeploy []types.Package, b *Bundle, recorder *stateRecorder) error {
+	// record the state of each package before deploying it so the bundle can be rolled back on failure
+	var tracker *rollbackTracker
+	if b.cfg.DeployOpts.RollbackOnFailure {
+		tracker = &rollbackTracker{}
+	}
+	deploy := func(pkg types.Package, bundleExportedVars map[string]map[string]string) (map[string]string, error) {
+		if tracker != nil {
+			// releases are rolled back with the same timeout the package is deployed with
+			timeout, _, err := b.loadDeploySettings(pkg)
+			if err == nil {
+				err = tracker.record(pkg, timeout)
+			}
+			if err != nil {
+				recorder.packageFailed(pkg.Name, err)
+				return nil, err
 			}
-			defer os.Remove(publicKeyPath)
+		}
+		pkgExportedVars, err := b.deployPackage(pkg, bundleExportedVars, recorder)
+		if err != nil {
+			recorder.packageFailed(pkg.Name, err)
+		}
+		return pkgExportedVars, err
+	}
+
+	// Automatically confirm the package deployments, once since packages can deploy concurrently
+	zarfConfig.CommonOptions.Confirm = true
+
+	// bundle-level actions get the bundle's shared variables and the variables exported by its packages
+	bundleVars := b.loadVariables(types.Package{}, recorder.exportedVars())
+	err := runActions(&recorder.actions, b.bundle.Metadata.Name, "onDeploy", b.bundle.Actions.OnDeploy, bundleVars, func() error {
+		var err error
+		if b.cfg.DeployOpts.Concurrency <= 1 {
+			err = deploySequentially(packagesToDeploy, deploy)
 		} else {
-			publicKeyPath = ""
+			err = deployConcurrently(packagesToDeploy, b.cfg.DeployOpts.Concurrency, deploy)
 		}
 
-		pkgVars := b.loadVariables(pkg, bundleExportedVars)
+		if err != nil && tracker != nil {
+			if rollbackErr := tracker.rollback(b.cfg.DeployOpts.Source); rollbackErr != nil {
+				return fmt.Errorf("%w (rollback failed: %s)", err, rollbackErr.Error())
+			}
+			return fmt.Errorf("%w (bundle deployment was rolled back)", err)
+		}
+		maps.Copy(bundleVars, b.loadVariables(types.Package{}, recorder.exportedVars()))
+		return err
+	})
+	recorder.finish(err)
+	recorder.printSummary()
+	return err
+}
 
-		opts := zarfTypes.ZarfPackageOptions{
-			PackageSource:      pkgTmp,
-			OptionalComponents: strings.Join(pkg.OptionalComponents, ","),
-			PublicKeyPath:      publicKeyPath,
-			SetVariables:       pkgVars,
-			Retries:            b.cfg.DeployOpts.Retries,
+// deployFunc deploys a package using the variables exported by the packages it depends on and returns the variables it exports
+type deployFunc func(pkg types.Package, bundleExportedVars map[string]map[string]string) (map[string]string, error)
+
+// dependencyExports returns the variables exported by the packages a package depends on
+//
+// concurrent deploys only pass a package the exports of the packages it imports from or declares in dependsOn, since which
+// other packages have finished deploying changes from run to run. sequential deploys pass every earlier package's exports
+func dependencyExports(pkg types.Package, bundleExportedVars map[string]map[string]string) map[string]map[string]string {
+	exportedVars := make(map[string]map[string]string)
+	for _, dep := range packageDependencies(pkg) {
+		if vars, ok := bundleExportedVars[dep]; ok {
+			exportedVars[dep] = vars
 		}
+	}
+	return exportedVars
+}
 
-		valuesOverrides, nsOverrides, err := b.loadChartOverrides(pkg, pkgVars)
+// deploySequentially deploys packages one at a time in order
+func deploySequentially(packages []types.Package, deploy deployFunc) error {
+	// map of Zarf pkgs and their vars
+	bundleExportedVars := make(map[string]map[string]string)
+	for _, pkg := range packages {
+		pkgExportedVars, err := deploy(pkg, bundleExportedVars)
 		if err != nil {
 			return err
 		}
+		bundleExportedVars[pkg.Name] = pkgExportedVars
+	}
+	return nil
+}
 
-		zarfDeployOpts := zarfTypes.ZarfDeployOptions{
-			ValuesOverridesMap: valuesOverrides,
-			Timeout:            config.HelmTimeout,
-		}
+// deployConcurrently deploys packages as soon as the packages they depend on have been deployed
+//
+// each package is deployed in its own process (see deployInChildProcess), and its output is prefixed with its name
+func deployConcurrently(packages []types.Package, concurrency int, deploy deployFunc) error {
+	var mu sync.Mutex
+	bundleExportedVars := make(map[string]map[string]string)
+	deployed := make(map[string]chan struct{}, len(packages))
+	for _, pkg := range packages {
+		deployed[pkg.Name] = make(chan struct{})
+	}
+	sem := make(chan struct{}, concurrency)
+	eg, ctx := errgroup.WithContext(context.TODO())
+
+	for _, pkg := range packages {
+		pkg := pkg
+		eg.Go(func() error {
+			// wait for dependencies that are part of this deploy
+			for _, dep := range packageDependencies(pkg) {
+				if ch, ok := deployed[dep]; ok {
+					select {
+					case <-ch:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-sem }()
 
-		pkgCfg := zarfTypes.PackagerConfig{
-			PkgOpts:    opts,
-			InitOpts:   config.DefaultZarfInitOptions,
-			DeployOpts: zarfDeployOpts,
-		}
+			mu.Lock()
+			exportedVars := dependencyExports(pkg, bundleExportedVars)
+			mu.Unlock()
+
+			message.Infof("[%s] Deploying package", pkg.Name)
+			pkgExportedVars, err := deploy(pkg, exportedVars)
+			if err != nil {
+				message.Warnf("[%s] Failed to deploy package", pkg.Name)
+				return fmt.Errorf("failed to deploy package %s: %w", pkg.Name, err)
+			}
+			message.Successf("[%s] Deployed package", pkg.Name)
+
+			mu.Lock()
+			bundleExportedVars[pkg.Name] = pkgExportedVars
+			mu.Unlock()
+			close(deployed[pkg.Name])
+			return nil
+		})
+	}
+	return eg.Wait()
+}
 
-		// Automatically confirm the package deployment
-		zarfConfig.CommonOptions.Confirm = true
+// deployPackage deploys a single Zarf package from the bundle and returns the variables it exports
+//
+// packages that haven't changed since they were last deployed are skipped unless --force is set
+func (b *Bundle) deployPackage(pkg types.Package, bundleExportedVars map[string]map[string]string, recorder *stateRecorder) (map[string]string, error) {
+	pkgVars := b.loadVariables(pkg, bundleExportedVars)
 
-		source, err := sources.New(b.cfg.DeployOpts.Source, pkg, opts, sha, nsOverrides)
+	timeout, retries, err := b.loadDeploySettings(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	valuesOverrides, nsOverrides, err := b.loadChartOverrides(pkg, pkgVars)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash, err := packageConfigHash(b.configKey, pkg, pkgVars, valuesOverrides, nsOverrides)
+	if err != nil {
+		return nil, err
+	}
+	if b.deployPlan != nil {
+		if err := b.verifyPlannedPackage(pkg, bundleExportedVars, recorder.deployed); err != nil {
+			return nil, err
+		}
+	}
+	return b.deployUnlessUnchanged(pkg, configHash, recorder, isPackageHealthy, func() (map[string]string, error) {
+		emitOverrideEvents(pkg)
+
+		d := packageDeploy{
+			Source:             b.cfg.DeployOpts.Source,
+			Package:            pkg,
+			Variables:          pkgVars,
+			ValuesOverrides:    valuesOverrides,
+			NamespaceOverrides: nsOverrides,
+			Timeout:            timeout,
+			Retries:            retries,
+		}
+		var result packageDeployResult
+		if b.cfg.DeployOpts.Concurrency > 1 {
+			// Zarf keeps the state of a deploy in globals, so concurrent deploys each run in their own process
+			recorder.packageStarted(pkg.Name)
+			result, err = deployInChildProcess(d, &recorder.actions)
+		} else {
+			result, err = deployZarfPackage(d, &recorder.actions, func() { recorder.packageStarted(pkg.Name) })
+		}
+		emitVariableEvents(pkg.Name, result.Variables)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		recorder.packageSucceeded(pkg.Name, result.Version, configHash, result.Exports, result.SensitiveExports)
+		return result.Exports, nil
+	})
+}
+
+// packageDeploy is a deploy of a single Zarf package from a bundle, with its variables and chart overrides already resolved
+type packageDeploy struct {
+	// Source is the bundle the package is deployed from
+	Source             string
+	Package            types.Package
+	Variables          map[string]string
+	ValuesOverrides    PkgOverrideMap
+	NamespaceOverrides sources.NamespaceOverrideMap
+	Timeout            time.Duration
+	Retries            int
+}
+
+// packageDeployResult is the outcome of a single Zarf package deploy
+type packageDeployResult struct {
+	Version          string
+	Exports          map[string]string
+	SensitiveExports []string
+	// Variables are the variables Zarf set while deploying the package
+	Variables []resolvedVariable
+}
+
+// resolvedVariable is a variable Zarf set while deploying a package
+type resolvedVariable struct {
+	Name      string
+	Value     string
+	Sensitive bool
+}
+
+// deployZarfPackage deploys a Zarf package wrapped in its onDeploy actions, calling started once the before actions have run
+func deployZarfPackage(d packageDeploy, log *actionLog, started func()) (packageDeployResult, error) {
+	pkg := d.Package
+	var result packageDeployResult
+	sha := strings.Split(pkg.Ref, "@sha256:")[1] // using appended SHA from create!
+	pkgTmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(pkgTmp)
+
+	// write the public key next to the package so concurrent deploys don't clobber each other's keys
+	publicKeyPath := ""
+	if pkg.PublicKey != "" {
+		publicKeyPath = filepath.Join(pkgTmp, config.PublicKeyFile)
+		if err := os.WriteFile(publicKeyPath, []byte(pkg.PublicKey), helpers.ReadWriteUser); err != nil {
+			return result, err
 		}
+	}
+
+	opts := zarfTypes.ZarfPackageOptions{
+		PackageSource:      pkgTmp,
+		OptionalComponents: strings.Join(pkg.OptionalComponents, ","),
+		PublicKeyPath:      publicKeyPath,
+		SetVariables:       d.Variables,
+		Retries:            d.Retries,
+	}
+
+	pkgCfg := zarfTypes.PackagerConfig{
+		PkgOpts:  opts,
+		InitOpts: config.DefaultZarfInitOptions,
+		DeployOpts: zarfTypes.ZarfDeployOptions{
+			ValuesOverridesMap: d.ValuesOverrides,
+			Timeout:            d.Timeout,
+		},
+	}
 
-		pkgClient := packager.NewOrDie(&pkgCfg, packager.WithSource(source), packager.WithTemp(opts.PackageSource))
+	source, err := sources.New(d.Source, pkg, opts, sha, d.NamespaceOverrides)
+	if err != nil {
+		return result, err
+	}
 
+	pkgClient := packager.NewOrDie(&pkgCfg, packager.WithSource(source), packager.WithTemp(opts.PackageSource))
+
+	result.Exports = make(map[string]string)
+	actionVars := maps.Clone(d.Variables)
+	err = runActions(log, pkg.Name, "onDeploy", pkg.Actions.OnDeploy, actionVars, func() error {
+		started()
 		if err := pkgClient.Deploy(context.TODO()); err != nil {
 			return err
 		}
 
 		// save exported vars
-		pkgExportedVars := make(map[string]string)
 		variableConfig := pkgClient.GetVariableConfig()
+		result.Variables = resolvedVariables(variableConfig)
 		for _, exp := range pkg.Exports {
 			// ensure if variable exists in package
 			setVariable, ok := variableConfig.GetSetVariable(exp.Name)
 			if !ok {
 				return fmt.Errorf("cannot export variable %s because it does not exist in package %s", exp.Name, pkg.Name)
 			}
-			pkgExportedVars[strings.ToUpper(exp.Name)] = setVariable.Value
+			result.Exports[strings.ToUpper(exp.Name)] = setVariable.Value
+			if setVariable.Sensitive {
+				result.SensitiveExports = append(result.SensitiveExports, strings.ToUpper(exp.Name))
+			}
 		}
-		bundleExportedVars[pkg.Name] = pkgExportedVars
+		maps.Copy(actionVars, result.Exports)
+		return nil
+	})
+	if err != nil {
+		return result, err
 	}
-	return nil
+	result.Version = pkgCfg.Pkg.Metadata.Version
+	return result, nil
+}
+
+// deployUnlessUnchanged deploys a package with deploy, unless it hasn't changed since it was last deployed and is still healthy
+//
+// skipped packages don't run their onDeploy actions either: neither the package nor the variables passed to its actions
+// have changed, so the actions would only repeat what they did when the package was last deployed. --force redeploys
+// the package and runs its actions
+func (b *Bundle) deployUnlessUnchanged(pkg types.Package, configHash string, recorder *stateRecorder, healthy func(name string) bool, deploy func() (map[string]string, error)) (map[string]string, error) {
+	if !b.cfg.DeployOpts.Force {
+		if exports, ok := recorder.unchanged(pkg, configHash); ok && healthy(pkg.Name) {
+			message.Infof("Skipping package %s and its actions, it hasn't changed since it was last deployed (use --force to redeploy it)", pkg.Name)
+			recorder.packageSkipped(pkg.Name)
+			return exports, nil
+		}
+	}
+	return deploy()
+}
+
+// emitOverrideEvents emits an override.applied event for each chart override in a package
+func emitOverrideEvents(pkg types.Package) {
+	if !events.Enabled() {
+		return
+	}
+	for componentName, component := range pkg.Overrides {
+		for chartName, chart := range component {
+			for _, v := range chart.Values {
+				events.OverrideApplied(pkg.Name, componentName, chartName, v.Path)
+			}
+			for _, v := range chart.Variables {
+				events.OverrideApplied(pkg.Name, componentName, chartName, v.Path)
+			}
+			if chart.Namespace != "" {
+				events.OverrideApplied(pkg.Name, componentName, chartName, chart.Namespace)
+			}
+		}
+	}
+}
+
+// resolvedVariables returns the variables Zarf set while deploying a package
+func resolvedVariables(variableConfig *variables.VariableConfig) []resolvedVariable {
+	templates := variableConfig.GetAllTemplates()
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	// templates are keyed by ###ZARF_VAR_<NAME>###, so sort for a stable order
+	slices.Sort(keys)
+	var resolved []resolvedVariable
+	for _, key := range keys {
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "###ZARF_VAR_"), "###")
+		if name == key {
+			continue
+		}
+		resolved = append(resolved, resolvedVariable{Name: name, Value: templates[key].Value, Sensitive: templates[key].Sensitive})
+	}
+	return resolved
+}
+
+// emitVariableEvents emits a variable.resolved event for each variable Zarf set while deploying a package
+func emitVariableEvents(pkgName string, resolved []resolvedVariable) {
+	if !events.Enabled() {
+		return
+	}
+	for _, v := range resolved {
+		events.VariableResolved(pkgName, v.Name, v.Value, v.Sensitive)
+	}
+}
+
+// loadDeploySettings returns the Helm timeout and number of retries for a package
+//
+// settings from the package_options in uds-config.yaml take precedence over the package's settings in the bundle,
+// which take precedence over the defaults. An explicit --retries takes precedence over all of them
+func (b *Bundle) loadDeploySettings(pkg types.Package) (time.Duration, int, error) {
+	timeout, retries := config.HelmTimeout, b.cfg.DeployOpts.Retries
+
+	timeoutStr := pkg.Timeout
+	if pkg.Retries != nil {
+		retries = *pkg.Retries
+	}
+	if opts, ok := b.cfg.DeployOpts.PackageOptions[pkg.Name]; ok {
+		if opts.Timeout != "" {
+			timeoutStr = opts.Timeout
+		}
+		if opts.Retries != nil {
+			retries = *opts.Retries
+		}
+	}
+	if b.cfg.DeployOpts.RetriesSet {
+		retries = b.cfg.DeployOpts.Retries
+	}
+
+	if timeoutStr != "" {
+		var err error
+		timeout, err = time.ParseDuration(timeoutStr)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid timeout %q for package %s: %w", timeoutStr, pkg.Name, err)
+		}
+	}
+	return timeout, retries, nil
 }
 
 // loadVariables loads and sets precedence for config-level and imported variables
@@ -276,7 +702,14 @@ func (b *Bundle) loadChartOverrides(pkg types.Package, pkgVars map[string]string
 }
 
 // PreDeployValidation validates the bundle before deployment
-func (b *Bundle) PreDeployValidation() (string, string, string, error) {
+func (b *Bundle) PreDeployValidation() (_ string, _ string, _ string, err error) {
+	b.startDeployReport()
+	// the deploy ends here if the bundle isn't valid, so the failure is reported here
+	defer func() {
+		if err != nil {
+			b.writeDeployReport(nil, err)
+		}
+	}()
 
 	// Check that provided oci source path is valid, and update it if it's missing the full path
 	source, err := CheckOCISourcePath(b.cfg.DeployOpts.Source)
@@ -304,9 +737,16 @@ func (b *Bundle) PreDeployValidation() (string, string, string, error) {
 	}
 
 	// validate the sig (if present)
-	if err := ValidateBundleSignature(loaded[config.BundleYAML], loaded[config.BundleYAMLSignature], b.cfg.DeployOpts.PublicKeyPath); err != nil {
+	if err := verifyBundle(provider, loaded, b.cfg.DeployOpts.PublicKeyPaths, b.cfg.DeployOpts.KeyThreshold, b.cfg.DeployOpts.RequireManifestSignature); err != nil {
+		return "", "", "", err
+	}
+
+	// the root manifest's digest identifies exactly what was deployed in the bundle's state
+	rootDesc, err := provider.getBundleRootDesc()
+	if err != nil {
 		return "", "", "", err
 	}
+	b.rootDigest = rootDesc.Digest.String()
 
 	// read in file at config.BundleYAML
 	message.Debugf("Reading YAML at %s", loaded[config.BundleYAML])
@@ -321,6 +761,7 @@ func (b *Bundle) PreDeployValidation() (string, string, string, error) {
 	}
 
 	bundleName := b.bundle.Metadata.Name
+	events.SetBundle(bundleName)
 	return bundleName, string(bundleYAML), source, err
 }
 
@@ -1,10 +1,15 @@
 package bundle
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/defenseunicorns/uds-cli/src/types"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/cli/values"
 )
@@ -429,3 +434,259 @@ func TestHelmOverrideVariablePrecedence(t *testing.T) {
 		})
 	}
 }
+
+func TestDeployExports(t *testing.T) {
+	packages := []types.Package{
+		{Name: "database"},
+		{Name: "cache"},
+		{Name: "app", DependsOn: []string{"database"}},
+		{Name: "api", Imports: []types.BundleVariableImport{{Name: "CACHE_URL", Package: "cache"}}},
+		{Name: "monitoring"},
+	}
+	exports := map[string]map[string]string{
+		"database": {"DB_URL": "db"},
+		"cache":    {"CACHE_URL": "cache"},
+	}
+
+	received := func(schedule func(deploy deployFunc) error) map[string]map[string]map[string]string {
+		var mu sync.Mutex
+		received := make(map[string]map[string]map[string]string)
+		deploy := func(pkg types.Package, bundleExportedVars map[string]map[string]string) (map[string]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			copied := make(map[string]map[string]string, len(bundleExportedVars))
+			for name, vars := range bundleExportedVars {
+				copied[name] = vars
+			}
+			received[pkg.Name] = copied
+			return exports[pkg.Name], nil
+		}
+		require.NoError(t, schedule(deploy))
+		return received
+	}
+
+	// sequential deploys pass every package the exports of the packages deployed before it
+	sequential := received(func(deploy deployFunc) error { return deploySequentially(packages, deploy) })
+	require.Empty(t, sequential["database"])
+	require.Equal(t, map[string]map[string]string{"database": exports["database"]}, sequential["cache"])
+	require.Equal(t, map[string]map[string]string{"database": exports["database"], "cache": exports["cache"]}, sequential["app"])
+	require.Equal(t, map[string]map[string]string{"database": exports["database"], "cache": exports["cache"], "app": nil}, sequential["api"])
+
+	// concurrent deploys only pass the exports of the packages a package depends on
+	concurrent := received(func(deploy deployFunc) error { return deployConcurrently(packages, 3, deploy) })
+	require.Empty(t, concurrent["database"])
+	require.Empty(t, concurrent["cache"])
+	require.Empty(t, concurrent["monitoring"])
+	require.Equal(t, map[string]map[string]string{"database": exports["database"]}, concurrent["app"])
+	require.Equal(t, map[string]map[string]string{"cache": exports["cache"]}, concurrent["api"])
+}
+
+func TestDeployUnlessUnchanged(t *testing.T) {
+	pkg := types.Package{
+		Name: "nginx",
+		Ref:  "0.0.1@sha256:abc",
+		Actions: types.BundleActions{OnDeploy: zarfTypes.ZarfComponentActionSet{
+			After: []zarfTypes.ZarfComponentAction{{Cmd: "true"}},
+		}},
+	}
+	previous := &types.BundleState{Packages: []types.PackageState{
+		{Name: "nginx", Digest: "sha256:abc", ConfigHash: "hash", Status: types.StatusSucceeded, Exports: map[string]string{"URL": "nginx"}},
+	}}
+
+	tests := []struct {
+		name       string
+		force      bool
+		configHash string
+		healthy    bool
+		deployed   bool
+	}{
+		{name: "unchanged and healthy packages and their actions are skipped", configHash: "hash", healthy: true},
+		{name: "changed config", configHash: "other-hash", healthy: true, deployed: true},
+		{name: "unhealthy package", configHash: "hash", deployed: true},
+		{name: "--force", force: true, configHash: "hash", healthy: true, deployed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bundle{cfg: &types.BundleConfig{DeployOpts: types.BundleDeployOptions{Force: tt.force}}}
+			recorder := &stateRecorder{
+				state:    &types.BundleState{Packages: []types.PackageState{{Name: "nginx"}}},
+				previous: previous,
+			}
+			deployed := false
+			exports, err := b.deployUnlessUnchanged(pkg, tt.configHash, recorder, func(string) bool { return tt.healthy }, func() (map[string]string, error) {
+				deployed = true
+				return map[string]string{"URL": "new"}, runActions(&recorder.actions, pkg.Name, "onDeploy", pkg.Actions.OnDeploy, nil, func() error { return nil })
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.deployed, deployed)
+			if tt.deployed {
+				require.Equal(t, map[string]string{"URL": "new"}, exports)
+				require.Len(t, recorder.actions.results, 1)
+				return
+			}
+			require.Equal(t, map[string]string{"URL": "nginx"}, exports)
+			require.Empty(t, recorder.actions.results)
+			require.Equal(t, previous.Packages[0], recorder.state.Packages[0])
+		})
+	}
+}
+
+func TestLoadDeploySettings(t *testing.T) {
+	zero, one, five := 0, 1, 5
+	testCases := []struct {
+		name            string
+		pkg             types.Package
+		deployOpts      types.BundleDeployOptions
+		expectedTimeout time.Duration
+		expectedRetries int
+		wantErr         bool
+	}{
+		{
+			name:            "defaults",
+			pkg:             types.Package{Name: "foo"},
+			deployOpts:      types.BundleDeployOptions{Retries: 3},
+			expectedTimeout: 15 * time.Minute,
+			expectedRetries: 3,
+		},
+		{
+			name:            "set in the bundle",
+			pkg:             types.Package{Name: "foo", Timeout: "40m", Retries: &five},
+			deployOpts:      types.BundleDeployOptions{Retries: 3},
+			expectedTimeout: 40 * time.Minute,
+			expectedRetries: 5,
+		},
+		{
+			name: "uds-config takes precedence over the bundle",
+			pkg:  types.Package{Name: "foo", Timeout: "40m", Retries: &five},
+			deployOpts: types.BundleDeployOptions{
+				Retries: 3,
+				PackageOptions: map[string]types.PackageDeployOptions{
+					"foo": {Timeout: "1h", Retries: &one},
+					"bar": {Timeout: "1m"},
+				},
+			},
+			expectedTimeout: time.Hour,
+			expectedRetries: 1,
+		},
+		{
+			name:            "retries can be turned off in the bundle",
+			pkg:             types.Package{Name: "foo", Retries: &zero},
+			deployOpts:      types.BundleDeployOptions{Retries: 3},
+			expectedTimeout: 15 * time.Minute,
+			expectedRetries: 0,
+		},
+		{
+			name:            "retries can be turned off in uds-config",
+			pkg:             types.Package{Name: "foo", Retries: &five},
+			deployOpts:      types.BundleDeployOptions{Retries: 3, PackageOptions: map[string]types.PackageDeployOptions{"foo": {Retries: &zero}}},
+			expectedTimeout: 15 * time.Minute,
+			expectedRetries: 0,
+		},
+		{
+			name: "--retries takes precedence over uds-config and the bundle",
+			pkg:  types.Package{Name: "foo", Retries: &five},
+			deployOpts: types.BundleDeployOptions{
+				Retries:        2,
+				RetriesSet:     true,
+				PackageOptions: map[string]types.PackageDeployOptions{"foo": {Retries: &one}},
+			},
+			expectedTimeout: 15 * time.Minute,
+			expectedRetries: 2,
+		},
+		{
+			name:       "invalid timeout",
+			pkg:        types.Package{Name: "foo", Timeout: "forever"},
+			deployOpts: types.BundleDeployOptions{Retries: 3},
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Bundle{cfg: &types.BundleConfig{DeployOpts: tc.deployOpts}}
+			timeout, retries, err := b.loadDeploySettings(tc.pkg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedTimeout, timeout)
+			require.Equal(t, tc.expectedRetries, retries)
+		})
+	}
+}
+
+func TestResumeReason(t *testing.T) {
+	// the ref of a flavored package is tagged with its flavor, which isn't part of the version Zarf records
+	pkg := types.Package{Name: "nginx", Ref: "0.0.2-upstream@sha256:abc"}
+	deployed := func(version string, statuses ...zarfTypes.ComponentStatus) *zarfTypes.DeployedPackage {
+		d := &zarfTypes.DeployedPackage{Name: "nginx"}
+		d.Data.Metadata.Version = version
+		for i, status := range statuses {
+			d.DeployedComponents = append(d.DeployedComponents, zarfTypes.DeployedComponent{Name: fmt.Sprintf("component-%d", i), Status: status})
+		}
+		return d
+	}
+
+	testCases := []struct {
+		name     string
+		deployed *zarfTypes.DeployedPackage
+		recorded *types.PackageState
+		expected string
+	}{
+		{
+			name:     "not deployed",
+			expected: "it isn't deployed",
+		},
+		{
+			name:     "fully deployed",
+			deployed: deployed("0.0.2", zarfTypes.ComponentStatusSucceeded),
+			recorded: &types.PackageState{Digest: "sha256:abc", Status: types.StatusSucceeded},
+		},
+		{
+			name:     "fully deployed without bundle state",
+			deployed: deployed("0.0.2", zarfTypes.ComponentStatusSucceeded),
+		},
+		{
+			name:     "older version deployed",
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded),
+			expected: "version 0.0.1 is deployed but the bundle has 0.0.2",
+		},
+		{
+			name:     "different digest deployed",
+			deployed: deployed("0.0.2", zarfTypes.ComponentStatusSucceeded),
+			recorded: &types.PackageState{Digest: "sha256:def", Status: types.StatusSucceeded},
+			expected: "digest sha256:def is deployed but the bundle has sha256:abc",
+		},
+		{
+			name:     "interrupted deploy",
+			deployed: deployed("0.0.2", zarfTypes.ComponentStatusSucceeded),
+			recorded: &types.PackageState{Digest: "sha256:abc", Status: types.StatusDeploying},
+			expected: "its last deploy didn't finish",
+		},
+		{
+			name:     "partially deployed",
+			deployed: deployed("0.0.2", zarfTypes.ComponentStatusSucceeded, zarfTypes.ComponentStatusFailed),
+			expected: "it is partially deployed: Failed (component-1)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, resumeReason(pkg, "0.0.2", tc.deployed, tc.recorded))
+		})
+	}
+}
+
+func TestZarfPackageVersionFromState(t *testing.T) {
+	// the version recorded for the same package is used without reading the package from the bundle
+	pkg := types.Package{Name: "nginx", Ref: "0.0.2-upstream@sha256:abc"}
+	missing := filepath.Join(t.TempDir(), "uds-bundle-example-amd64-0.0.1.tar.zst")
+	b := &Bundle{cfg: &types.BundleConfig{DeployOpts: types.BundleDeployOptions{Source: missing}}}
+	version, err := b.zarfPackageVersion(pkg, &types.PackageState{Digest: "sha256:abc", Version: "0.0.2"})
+	require.NoError(t, err)
+	require.Equal(t, "0.0.2", version)
+
+	// otherwise the version comes from the bundle, which fails here since there's no bundle to read it from
+	_, err = b.zarfPackageVersion(pkg, &types.PackageState{Digest: "sha256:def", Version: "0.0.1"})
+	require.ErrorContains(t, err, "unable to read the version of package nginx")
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+)
+
+// packageDependencies returns the names of the packages that pkg depends on, including the packages it imports variables from
+func packageDependencies(pkg types.Package) []string {
+	deps := slices.Clone(pkg.DependsOn)
+	for _, imp := range pkg.Imports {
+		if !slices.Contains(deps, imp.Package) {
+			deps = append(deps, imp.Package)
+		}
+	}
+	return deps
+}
+
+// validateDependencies ensures that every dependency references a package in the bundle and that there are no cycles
+func validateDependencies(packages []types.Package) error {
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+	for _, pkg := range packages {
+		for _, dep := range pkg.DependsOn {
+			if dep == pkg.Name {
+				return fmt.Errorf("package %s cannot depend on itself", pkg.Name)
+			}
+			if !slices.Contains(names, dep) {
+				return fmt.Errorf("package %s depends on %s, which is not in the bundle", pkg.Name, dep)
+			}
+		}
+	}
+	_, err := sortPackages(packages)
+	return err
+}
+
+// sortPackages topologically sorts packages so that each package comes after its dependencies
+//
+// packages that don't depend on each other keep their order from the bundle, and dependencies on
+// packages that aren't in the list (ie. filtered out by --packages or --resume) are ignored
+func sortPackages(packages []types.Package) ([]types.Package, error) {
+	remaining := make(map[string]int, len(packages))
+	dependents := make(map[string][]string, len(packages))
+	for _, pkg := range packages {
+		remaining[pkg.Name] = 0
+	}
+	for _, pkg := range packages {
+		for _, dep := range packageDependencies(pkg) {
+			if _, ok := remaining[dep]; !ok {
+				continue
+			}
+			remaining[pkg.Name]++
+			dependents[dep] = append(dependents[dep], pkg.Name)
+		}
+	}
+
+	sorted := make([]types.Package, 0, len(packages))
+	done := make(map[string]bool, len(packages))
+	for len(sorted) < len(packages) {
+		progressed := false
+		// always pick the first ready package in bundle order to keep the sort stable
+		for _, pkg := range packages {
+			if done[pkg.Name] || remaining[pkg.Name] > 0 {
+				continue
+			}
+			sorted = append(sorted, pkg)
+			done[pkg.Name] = true
+			for _, dependent := range dependents[pkg.Name] {
+				remaining[dependent]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			var cycle []string
+			for _, pkg := range packages {
+				if !done[pkg.Name] {
+					cycle = append(cycle, pkg.Name)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle detected between packages: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return sorted, nil
+}
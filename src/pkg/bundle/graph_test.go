@@ -0,0 +1,81 @@
+package bundle
+
+import (
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortPackages(t *testing.T) {
+	testCases := []struct {
+		name          string
+		packages      []types.Package
+		expectedOrder []string
+		wantErr       bool
+	}{
+		{
+			name: "no dependencies keeps bundle order",
+			packages: []types.Package{
+				{Name: "foo"}, {Name: "bar"}, {Name: "baz"},
+			},
+			expectedOrder: []string{"foo", "bar", "baz"},
+		},
+		{
+			name: "dependsOn moves dependency first",
+			packages: []types.Package{
+				{Name: "foo", DependsOn: []string{"baz"}}, {Name: "bar"}, {Name: "baz"},
+			},
+			expectedOrder: []string{"bar", "baz", "foo"},
+		},
+		{
+			name: "imports are implicit dependencies",
+			packages: []types.Package{
+				{Name: "foo", Imports: []types.BundleVariableImport{{Name: "OUTPUT", Package: "bar"}}},
+				{Name: "bar"},
+			},
+			expectedOrder: []string{"bar", "foo"},
+		},
+		{
+			name: "dependencies outside of the list are ignored",
+			packages: []types.Package{
+				{Name: "foo", DependsOn: []string{"not-deploying"}}, {Name: "bar"},
+			},
+			expectedOrder: []string{"foo", "bar"},
+		},
+		{
+			name: "cycle",
+			packages: []types.Package{
+				{Name: "foo", DependsOn: []string{"bar"}}, {Name: "bar", DependsOn: []string{"foo"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sorted, err := sortPackages(tc.packages)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, pkg := range sorted {
+				names = append(names, pkg.Name)
+			}
+			require.Equal(t, tc.expectedOrder, names)
+		})
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	err := validateDependencies([]types.Package{{Name: "foo", DependsOn: []string{"bar"}}})
+	require.ErrorContains(t, err, "not in the bundle")
+
+	err = validateDependencies([]types.Package{{Name: "foo", DependsOn: []string{"foo"}}})
+	require.ErrorContains(t, err, "cannot depend on itself")
+
+	err = validateDependencies([]types.Package{{Name: "foo"}, {Name: "bar", DependsOn: []string{"foo"}}})
+	require.NoError(t, err)
+}
@@ -33,10 +33,15 @@ func (b *Bundle) Inspect() error {
 	}
 
 	// validate the sig (if present)
-	if err := ValidateBundleSignature(loaded[config.BundleYAML], loaded[config.BundleYAMLSignature], b.cfg.InspectOpts.PublicKeyPath); err != nil {
+	if err := verifyBundle(provider, loaded, b.cfg.InspectOpts.PublicKeyPaths, b.cfg.InspectOpts.KeyThreshold, b.cfg.InspectOpts.RequireManifestSignature); err != nil {
 		return err
 	}
 
+	// show the artifacts attached to the bundle instead of its metadata
+	if b.cfg.InspectOpts.ListReferrers {
+		return listReferrers(provider, b.cfg.InspectOpts.ExtractSBOM)
+	}
+
 	// pull sbom
 	if b.cfg.InspectOpts.IncludeSBOM {
 		err := provider.CreateBundleSBOM(b.cfg.InspectOpts.ExtractSBOM)
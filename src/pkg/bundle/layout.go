@@ -0,0 +1,238 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	av4 "github.com/mholt/archiver/v4"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// layoutBundleProvider reads a bundle straight from an OCI image layout directory (ie. a bundle pulled with --format oci-layout)
+//
+// nothing is written to the layout, so it can sit on read-only shared storage and be used by many deploys at once
+type layoutBundleProvider struct {
+	ctx context.Context
+	src string
+	dst string
+
+	// these fields are populated by loadBundleManifest as part of the provider constructor
+	bundleRootDesc ocispec.Descriptor
+	rootManifest   *oci.Manifest
+	referrerDescs  []ocispec.Descriptor
+}
+
+func (lp *layoutBundleProvider) getBundleManifest() (*oci.Manifest, error) {
+	if lp.rootManifest != nil {
+		return lp.rootManifest, nil
+	}
+	return nil, fmt.Errorf("bundle root manifest not loaded")
+}
+
+func (lp *layoutBundleProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	if lp.rootManifest != nil {
+		return lp.bundleRootDesc, nil
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("bundle root manifest not loaded")
+}
+
+// blobPath returns the path of a blob in the layout, ensuring the blob matches its digest
+func (lp *layoutBundleProvider) blobPath(desc ocispec.Descriptor) (string, error) {
+	path := filepath.Join(lp.src, config.BlobsDir, desc.Digest.Encoded())
+	if err := helpers.SHAsMatch(path, desc.Digest.Encoded()); err != nil {
+		return "", fmt.Errorf("failed to verify %s in %s: %w", desc.Digest.Encoded(), lp.src, err)
+	}
+	return path, nil
+}
+
+// loadBundleManifest loads the bundle's root manifest and desc into the layoutBundleProvider so we don't have to load it multiple times
+func (lp *layoutBundleProvider) loadBundleManifest() error {
+	b, err := os.ReadFile(filepath.Join(lp.src, ocispec.ImageIndexFile))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", ocispec.ImageIndexFile, err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", ocispec.ImageIndexFile, err)
+	}
+
+	// layouts usually hold a single bundle, otherwise use the bundle for this arch
+	bundleManifests, referrers := splitReferrers(index.Manifests)
+	var rootDesc *ocispec.Descriptor
+	for i, desc := range bundleManifests {
+		if len(bundleManifests) == 1 || (desc.Platform != nil && desc.Platform.Architecture == config.GetArch()) {
+			rootDesc = &bundleManifests[i]
+			break
+		}
+	}
+	if rootDesc == nil {
+		return fmt.Errorf("no bundle for arch %s found in %s", config.GetArch(), lp.src)
+	}
+
+	manifestPath, err := lp.blobPath(*rootDesc)
+	if err != nil {
+		return err
+	}
+	b, err = os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	var manifest *oci.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return err
+	}
+
+	lp.bundleRootDesc = *rootDesc
+	lp.rootManifest = manifest
+	lp.referrerDescs = referrers
+	return nil
+}
+
+// getReferrers gets the artifacts attached to the bundle that were pulled into the layout with it
+func (lp *layoutBundleProvider) getReferrers() ([]ocispec.Descriptor, content.ReadOnlyStorage, error) {
+	storage := blobStorage(lp.blobPath)
+	referrers, err := localReferrers(lp.ctx, storage, lp.referrerDescs, lp.bundleRootDesc)
+	return referrers, storage, err
+}
+
+// verifyBlobs checks that every blob in the layout matches its digest
+func (lp *layoutBundleProvider) verifyBlobs() error {
+	blobsDir := filepath.Join(lp.src, config.BlobsDir)
+	entries, err := os.ReadDir(blobsDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		f, err := os.Open(filepath.Join(blobsDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		err = verifyBlob(entry.Name(), f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadBundleMetadata returns the paths of a bundle's metadata in the layout, nothing is copied or extracted
+func (lp *layoutBundleProvider) LoadBundleMetadata() (types.PathMap, error) {
+	bundleRootManifest, err := lp.getBundleManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(types.PathMap)
+	for _, path := range config.BundleAlwaysPull {
+		layer := bundleRootManifest.Locate(path)
+		if oci.IsEmptyDescriptor(layer) {
+			continue
+		}
+		abs, err := lp.blobPath(layer)
+		if err != nil {
+			return nil, err
+		}
+		loaded[path] = abs
+	}
+	return loaded, nil
+}
+
+// LoadBundle loads a bundle from an OCI image layout
+func (lp *layoutBundleProvider) LoadBundle(_ types.BundlePullOptions, _ int) (*types.UDSBundle, types.PathMap, error) {
+	return nil, nil, fmt.Errorf("uds pull does not support pulling local bundles")
+}
+
+// CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
+func (lp *layoutBundleProvider) CreateBundleSBOM(extractSBOM bool) error {
+	rootManifest, err := lp.getBundleManifest()
+	if err != nil {
+		return err
+	}
+	// make tmp dir for pkg SBOM extraction
+	err = os.Mkdir(filepath.Join(lp.dst, config.BundleSBOM), 0o700)
+	if err != nil {
+		return err
+	}
+	SBOMArtifactPathMap := make(types.PathMap)
+	containsSBOMs := false
+
+	for _, layer := range rootManifest.Layers {
+		// get Zarf image manifests from bundle manifest, skipping the bundle's metadata which has title annotations
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
+			continue
+		}
+		zarfManifestPath, err := lp.blobPath(layer)
+		if err != nil {
+			return err
+		}
+		zarfManifestBytes, err := os.ReadFile(zarfManifestPath)
+		if err != nil {
+			return err
+		}
+		var zarfImageManifest *oci.Manifest
+		if err := json.Unmarshal(zarfManifestBytes, &zarfImageManifest); err != nil {
+			return err
+		}
+
+		// find sbom layer descriptor and read the sbom tar from the layout
+		sbomDesc := zarfImageManifest.Locate(config.SBOMsTar)
+		if oci.IsEmptyDescriptor(sbomDesc) {
+			message.Warnf("%s not found in Zarf pkg", config.SBOMsTar)
+			continue
+		}
+		sbomPath, err := lp.blobPath(sbomDesc)
+		if err != nil {
+			return err
+		}
+		sbomTarBytes, err := os.ReadFile(sbomPath)
+		if err != nil {
+			return err
+		}
+		extractor := utils.SBOMExtractor(lp.dst, SBOMArtifactPathMap)
+		err = av4.Tar{}.Extract(context.TODO(), bytes.NewReader(sbomTarBytes), nil, extractor)
+		if err != nil {
+			return err
+		}
+		containsSBOMs = true
+	}
+	if extractSBOM {
+		if !containsSBOMs {
+			message.Warnf("Cannot extract, no SBOMs found in bundle")
+			return nil
+		}
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		return utils.MoveExtractedSBOMs(lp.dst, currentDir)
+	}
+	return utils.CreateSBOMArtifact(SBOMArtifactPathMap)
+}
+
+// PublishBundle publishes a bundle in an OCI image layout to a remote OCI registry
+func (lp *layoutBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, tags []string) error {
+	bundleRootManifest, err := lp.getBundleManifest()
+	if err != nil {
+		return err
+	}
+	return publishFromLayout(lp.ctx, lp.src, bundleRootManifest, lp.bundleRootDesc, bundle, remote, tags)
+}
@@ -0,0 +1,104 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+// writeTestLayout writes an OCI image layout holding a bundle root manifest with only a uds-bundle.yaml
+func writeTestLayout(t *testing.T, bundleYAML []byte) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, config.BlobsDir), 0o700))
+	writeBlob := func(mediaType string, b []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, b)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.BlobsDir, desc.Digest.Encoded()), b, 0o600))
+		return desc
+	}
+
+	yamlDesc := writeBlob(zoci.ZarfLayerMediaTypeBlob, bundleYAML)
+	yamlDesc.Annotations = map[string]string{ocispec.AnnotationTitle: config.BundleYAML}
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    writeBlob(zoci.ZarfLayerMediaTypeBlob, []byte("{}")),
+		Layers:    []ocispec.Descriptor{yamlDesc},
+	}
+	manifest.SchemaVersion = 2
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	rootDesc := writeBlob(ocispec.MediaTypeImageManifest, manifestBytes)
+
+	index := ocispec.Index{MediaType: ocispec.MediaTypeImageIndex, Manifests: []ocispec.Descriptor{rootDesc}}
+	index.SchemaVersion = 2
+	indexBytes, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ocispec.ImageIndexFile), indexBytes, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ocispec.ImageLayoutFile), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o600))
+	return dir
+}
+
+func TestLayoutBundleProvider(t *testing.T) {
+	bundleYAML := []byte("kind: UDSBundle\nmetadata:\n  name: example\n")
+	dir := writeTestLayout(t, bundleYAML)
+
+	provider, err := NewBundleProvider(dir, t.TempDir())
+	require.NoError(t, err)
+	require.IsType(t, &layoutBundleProvider{}, provider)
+
+	// the metadata is read in place from the layout
+	loaded, err := provider.LoadBundleMetadata()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, config.BlobsDir), filepath.Dir(loaded[config.BundleYAML]))
+	b, err := os.ReadFile(loaded[config.BundleYAML])
+	require.NoError(t, err)
+	require.Equal(t, bundleYAML, b)
+	require.Empty(t, loaded[config.BundleYAMLSignature])
+
+	// blobs that don't match their digest are rejected
+	require.NoError(t, os.WriteFile(loaded[config.BundleYAML], []byte("tampered"), 0o600))
+	_, err = provider.LoadBundleMetadata()
+	require.Error(t, err)
+}
+
+func TestLayoutBundleProviderReferrers(t *testing.T) {
+	ctx := context.Background()
+	dir := writeTestLayout(t, []byte("kind: UDSBundle\nmetadata:\n  name: example\n"))
+	provider, err := NewBundleProvider(dir, t.TempDir())
+	require.NoError(t, err)
+	rootDesc, err := provider.getBundleRootDesc()
+	require.NoError(t, err)
+
+	// attach release notes to the bundle, which adds them to the layout's index.json
+	notes := filepath.Join(t.TempDir(), "NOTES.md")
+	require.NoError(t, os.WriteFile(notes, []byte("# 0.0.1"), 0o600))
+	store, err := ocistore.NewWithContext(ctx, dir)
+	require.NoError(t, err)
+	layers, err := boci.PushFiles(ctx, store, []string{notes})
+	require.NoError(t, err)
+	notesDesc, err := boci.Attach(ctx, store, rootDesc, config.BundleReleaseNotesArtifactType, layers)
+	require.NoError(t, err)
+
+	// the bundle is still loaded from the layout, with the release notes as its only referrer
+	provider, err = NewBundleProvider(dir, t.TempDir())
+	require.NoError(t, err)
+	reloadedDesc, err := provider.getBundleRootDesc()
+	require.NoError(t, err)
+	require.Equal(t, rootDesc.Digest, reloadedDesc.Digest)
+	referrers, storage, err := provider.getReferrers()
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	require.Equal(t, notesDesc.Digest, referrers[0].Digest)
+	manifest, err := boci.FetchReferrer(ctx, storage, referrers[0])
+	require.NoError(t, err)
+	require.Equal(t, "NOTES.md", manifest.Layers[0].Annotations[ocispec.AnnotationTitle])
+}
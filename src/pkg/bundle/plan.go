@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/state"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/cluster"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+)
+
+// sanitizedValue replaces masked values in the deploy plan
+const sanitizedValue = "**sanitized**"
+
+// DryRun prints what a deploy of the bundle would do without changing anything in the cluster, optionally saving the plan to --plan-out
+func (b *Bundle) DryRun() error {
+	packagesToDeploy, err := b.selectPackages()
+	if err != nil {
+		return err
+	}
+	// a dry run never writes to the cluster, so a bundle without a config key yet gets a new one that only this plan uses
+	keyFound, err := b.findConfigKey()
+	if err != nil {
+		return err
+	}
+	if !keyFound {
+		message.Warnf("Bundle %s has no config key in the cluster yet, so no package can be predicted to be skipped as unchanged", b.bundle.Metadata.Name)
+	}
+	plan, err := b.plan(packagesToDeploy)
+	if err != nil {
+		return err
+	}
+
+	message.HeaderInfof("📝 DEPLOY PLAN")
+	zarfUtils.ColorPrintYAML(plan, nil, false)
+
+	var rows [][]string
+	for _, pkg := range plan.Packages {
+		rows = append(rows, []string{pkg.Name, pkg.Ref, string(pkg.Action)})
+	}
+	message.HorizontalRule()
+	message.Table([]string{"Package", "Ref", "Action"}, rows)
+
+	if b.cfg.DeployOpts.PlanOut != "" {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(b.cfg.DeployOpts.PlanOut, data, helpers.ReadWriteUser); err != nil {
+			return err
+		}
+		message.Successf("Saved the deploy plan to %s", b.cfg.DeployOpts.PlanOut)
+
+		// the plan's config hashes can only be verified with the key they were made with, which is kept out of the plan
+		if !keyFound {
+			keyPath := planKeyPath(b.cfg.DeployOpts.PlanOut)
+			if err := os.WriteFile(keyPath, b.configKey, helpers.ReadWriteUser); err != nil {
+				return err
+			}
+			message.Infof("Saved the key the plan's config hashes were made with to %s, keep it with the plan to deploy with --plan", keyPath)
+		}
+	}
+	return nil
+}
+
+// planKeyPath returns the path of the config key saved with a deploy plan made for a bundle without a config key
+func planKeyPath(planPath string) string {
+	return planPath + ".key"
+}
+
+// plan resolves the variables and chart overrides of each package and works out whether it will be installed, upgraded or skipped
+func (b *Bundle) plan(packagesToDeploy []types.Package) (*types.DeployPlan, error) {
+	// the plan still works without a cluster, every package is just installed
+	recorder := &stateRecorder{}
+	if client, err := state.NewClient(); err == nil {
+		recorder.previous = loadPreviousState(client, b.bundle.Metadata.Name)
+	}
+	c, err := cluster.NewCluster()
+	if err != nil {
+		message.Debugf("Unable to connect to the cluster, all packages will be planned as installs: %s", err.Error())
+	}
+
+	plan := &types.DeployPlan{
+		Bundle:     b.bundle.Metadata.Name,
+		Version:    b.bundle.Metadata.Version,
+		Digest:     b.rootDigest,
+		Source:     b.cfg.DeployOpts.Source,
+		Concurrent: b.cfg.DeployOpts.Concurrency > 1,
+	}
+
+	// predicted are the variables each package is expected to export, recorded by its last deploy, and are only used to
+	// work out which packages will be skipped. planned are the variables the plan's config hashes are made from: the
+	// recorded exports of packages that will be skipped and placeholders for the exports of the packages that will be
+	// deployed, since their values aren't known until they are deployed
+	predicted := make(map[string]map[string]string)
+	planned := make(map[string]map[string]string)
+	for _, pkg := range packagesToDeploy {
+		_, _, configHash, err := b.resolveConfig(pkg, predicted)
+		if err != nil {
+			return nil, err
+		}
+
+		action := types.PlanActionInstall
+		if c != nil {
+			if _, err := c.GetDeployedPackage(context.TODO(), pkg.Name); err == nil {
+				action = types.PlanActionUpgrade
+			}
+		}
+		exports, unchanged := recorder.unchanged(pkg, configHash)
+		if unchanged && !b.cfg.DeployOpts.Force && isPackageHealthy(pkg.Name) {
+			action = types.PlanActionSkip
+		}
+
+		valuesOverrides, nsOverrides, plannedHash, err := b.resolveConfig(pkg, planned)
+		if err != nil {
+			return nil, err
+		}
+		// the plan is printed and saved, so values that could come from sensitive variables are masked
+		maskOverrides(pkg, valuesOverrides)
+
+		if prev, ok := recorder.previousPackage(pkg.Name); ok && prev.Exports != nil {
+			predicted[pkg.Name] = prev.Exports
+		}
+		if action == types.PlanActionSkip {
+			planned[pkg.Name] = exports
+		} else {
+			var names []string
+			for _, exp := range pkg.Exports {
+				names = append(names, strings.ToUpper(exp.Name))
+			}
+			planned[pkg.Name] = exportPlaceholders(pkg.Name, names)
+		}
+
+		plan.Packages = append(plan.Packages, types.PackagePlan{
+			Name:               pkg.Name,
+			Ref:                pkg.Ref,
+			Action:             action,
+			OptionalComponents: pkg.OptionalComponents,
+			ValuesOverrides:    valuesOverrides,
+			NamespaceOverrides: nsOverrides,
+			ConfigHash:         plannedHash,
+		})
+	}
+	return plan, nil
+}
+
+// verifyPlan ensures that the inputs to the deploy still match the plan saved at --plan
+func (b *Bundle) verifyPlan(packagesToDeploy []types.Package) error {
+	data, err := os.ReadFile(b.cfg.DeployOpts.PlanFile)
+	if err != nil {
+		return err
+	}
+	var saved types.DeployPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("unable to read the deploy plan %s: %w", b.cfg.DeployOpts.PlanFile, err)
+	}
+	// plans made before the bundle had a config key are verified with the key saved alongside them, which the deploy then
+	// records as the bundle's key
+	keyFound, err := b.findConfigKey()
+	if err != nil {
+		return err
+	}
+	if !keyFound {
+		keyPath := planKeyPath(b.cfg.DeployOpts.PlanFile)
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return fmt.Errorf("bundle %s has no config key in the cluster, so the deploy plan can only be verified with %s: %w", b.bundle.Metadata.Name, keyPath, err)
+		}
+		b.configKey = key
+	}
+	current, err := b.plan(packagesToDeploy)
+	if err != nil {
+		return err
+	}
+	if err := comparePlans(&saved, current); err != nil {
+		return fmt.Errorf("the bundle no longer matches the deploy plan %s: %w", b.cfg.DeployOpts.PlanFile, err)
+	}
+	// the config of each package is checked again right before it's deployed, with the variables that were actually exported
+	b.deployPlan = &saved
+	message.Successf("Bundle matches the deploy plan %s", b.cfg.DeployOpts.PlanFile)
+	return nil
+}
+
+// verifyPlannedPackage ensures that a package is about to be deployed with the config it was planned with
+//
+// the config is resolved from the variables actually exported by the packages deployed before it, except that the exports
+// of packages deployed during this deploy are replaced with the same placeholders the plan used
+func (b *Bundle) verifyPlannedPackage(pkg types.Package, bundleExportedVars map[string]map[string]string, deployed func(name string) bool) error {
+	i := slices.IndexFunc(b.deployPlan.Packages, func(p types.PackagePlan) bool { return p.Name == pkg.Name })
+	if i < 0 {
+		return fmt.Errorf("package %s isn't in the deploy plan %s", pkg.Name, b.cfg.DeployOpts.PlanFile)
+	}
+	exports := make(map[string]map[string]string, len(bundleExportedVars))
+	for name, vars := range bundleExportedVars {
+		if !deployed(name) {
+			exports[name] = vars
+			continue
+		}
+		var names []string
+		for varName := range vars {
+			names = append(names, varName)
+		}
+		exports[name] = exportPlaceholders(name, names)
+	}
+	_, _, configHash, err := b.resolveConfig(pkg, exports)
+	if err != nil {
+		return err
+	}
+	if configHash != b.deployPlan.Packages[i].ConfigHash {
+		return fmt.Errorf("package %s variables or overrides changed since the deploy plan %s was made", pkg.Name, b.cfg.DeployOpts.PlanFile)
+	}
+	return nil
+}
+
+// resolveConfig resolves the chart overrides of a package deployed after packages that exported exports, along with
+// the hash of its config
+func (b *Bundle) resolveConfig(pkg types.Package, exports map[string]map[string]string) (PkgOverrideMap, sources.NamespaceOverrideMap, string, error) {
+	// concurrent deploys only pass the exports of a package's dependencies
+	if b.cfg.DeployOpts.Concurrency > 1 {
+		exports = dependencyExports(pkg, exports)
+	}
+	pkgVars := b.loadVariables(pkg, exports)
+	valuesOverrides, nsOverrides, err := b.loadChartOverrides(pkg, pkgVars)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	configHash, err := packageConfigHash(b.configKey, pkg, pkgVars, valuesOverrides, nsOverrides)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return valuesOverrides, nsOverrides, configHash, nil
+}
+
+// exportPlaceholders returns placeholders for the variables a package exports, which aren't known until it's deployed
+func exportPlaceholders(pkgName string, names []string) map[string]string {
+	placeholders := make(map[string]string, len(names))
+	for _, name := range names {
+		placeholders[name] = fmt.Sprintf("<exported by %s>", pkgName)
+	}
+	return placeholders
+}
+
+// maskOverrides masks the chart override values that could come from sensitive variables, the same way Zarf masks
+// sensitive variables: values set by bundle variables and values that template variables
+func maskOverrides(pkg types.Package, overrides PkgOverrideMap) {
+	for componentName, component := range pkg.Overrides {
+		for chartName, chart := range component {
+			chartValues, ok := overrides[componentName][chartName]
+			if !ok {
+				continue
+			}
+			for _, v := range chart.Variables {
+				maskValue(chartValues, v.Path)
+			}
+			for _, v := range chart.Values {
+				if templatedVarRegex.MatchString(fmt.Sprint(v.Value)) {
+					maskValue(chartValues, v.Path)
+				}
+			}
+		}
+	}
+}
+
+// maskValue masks the value at a Helm value path, masking the whole top-level value when the path can't be followed
+func maskValue(chartValues map[string]interface{}, path string) {
+	current := chartValues
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		// list indexes and escaped dots aren't followed
+		if strings.ContainsAny(key, `[\`) {
+			root, _, _ := strings.Cut(keys[0], "[")
+			if _, ok := chartValues[root]; ok {
+				chartValues[root] = sanitizedValue
+			}
+			return
+		}
+		v, ok := current[key]
+		if !ok {
+			return
+		}
+		next, ok := v.(map[string]interface{})
+		if i == len(keys)-1 || !ok {
+			current[key] = sanitizedValue
+			return
+		}
+		current = next
+	}
+}
+
+// comparePlans returns an error describing the first input that differs between two plans
+//
+// the planned actions aren't compared since they depend on the cluster rather than the inputs to the deploy
+func comparePlans(saved, current *types.DeployPlan) error {
+	if saved.Bundle != current.Bundle {
+		return fmt.Errorf("planned bundle %s but deploying %s", saved.Bundle, current.Bundle)
+	}
+	if saved.Digest != current.Digest {
+		return fmt.Errorf("bundle digest changed from %s to %s", saved.Digest, current.Digest)
+	}
+	if saved.Concurrent != current.Concurrent {
+		if saved.Concurrent {
+			return fmt.Errorf("planned for a concurrent deploy but deploying one package at a time, set --deploy-concurrency above 1")
+		}
+		return fmt.Errorf("planned for deploying one package at a time but deploying concurrently, remove --deploy-concurrency")
+	}
+	if len(saved.Packages) != len(current.Packages) {
+		return fmt.Errorf("planned %d packages but deploying %d", len(saved.Packages), len(current.Packages))
+	}
+	for i, pkg := range current.Packages {
+		planned := saved.Packages[i]
+		if planned.Name != pkg.Name {
+			return fmt.Errorf("planned package %s but deploying %s", planned.Name, pkg.Name)
+		}
+		if planned.Ref != pkg.Ref {
+			return fmt.Errorf("package %s ref changed from %s to %s", pkg.Name, planned.Ref, pkg.Ref)
+		}
+		if !slices.Equal(planned.OptionalComponents, pkg.OptionalComponents) {
+			return fmt.Errorf("package %s optional components changed", pkg.Name)
+		}
+		if planned.ConfigHash != pkg.ConfigHash {
+			return fmt.Errorf("package %s variables or overrides changed", pkg.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/pkg/state"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparePlans(t *testing.T) {
+	saved := types.DeployPlan{
+		Bundle: "example",
+		Digest: "sha256:abc",
+		Packages: []types.PackagePlan{
+			{Name: "foo", Ref: "0.0.1@sha256:def", ConfigHash: "hash", Action: types.PlanActionInstall},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(p *types.DeployPlan)
+		wantErr string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(_ *types.DeployPlan) {},
+		},
+		{
+			name:   "action changes are ignored",
+			modify: func(p *types.DeployPlan) { p.Packages[0].Action = types.PlanActionSkip },
+		},
+		{
+			name:    "bundle digest changed",
+			modify:  func(p *types.DeployPlan) { p.Digest = "sha256:123" },
+			wantErr: "bundle digest changed",
+		},
+		{
+			name:    "package ref changed",
+			modify:  func(p *types.DeployPlan) { p.Packages[0].Ref = "0.0.2@sha256:123" },
+			wantErr: "ref changed",
+		},
+		{
+			name:    "config changed",
+			modify:  func(p *types.DeployPlan) { p.Packages[0].ConfigHash = "other" },
+			wantErr: "variables or overrides changed",
+		},
+		{
+			name:    "deploying concurrently",
+			modify:  func(p *types.DeployPlan) { p.Concurrent = true },
+			wantErr: "planned for deploying one package at a time but deploying concurrently",
+		},
+		{
+			name:    "package added",
+			modify:  func(p *types.DeployPlan) { p.Packages = append(p.Packages, types.PackagePlan{Name: "bar"}) },
+			wantErr: "planned 1 packages but deploying 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			current := saved
+			current.Packages = append([]types.PackagePlan{}, saved.Packages...)
+			tc.modify(&current)
+			err := comparePlans(&saved, &current)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestMaskOverrides(t *testing.T) {
+	pkg := types.Package{
+		Name: "app",
+		Overrides: map[string]map[string]types.BundleChartOverrides{
+			"component": {
+				"chart": {
+					Values: []types.BundleChartValue{
+						{Path: "replicas", Value: 2},
+						{Path: "db.url", Value: "postgres://${DB_PASSWORD}@db"},
+					},
+					Variables: []types.BundleChartVariable{
+						{Name: "ADMIN_PASSWORD", Path: "admin.password"},
+						{Name: "TOKENS", Path: "tokens[0]"},
+						{Name: "UNSET", Path: "unset.value"},
+					},
+				},
+			},
+		},
+	}
+	overrides := PkgOverrideMap{
+		"component": {
+			"chart": {
+				"replicas": 2,
+				"db":       map[string]interface{}{"url": "postgres://secret@db", "port": 5432},
+				"admin":    map[string]interface{}{"password": "secret", "user": "admin"},
+				"tokens":   []interface{}{"secret"},
+			},
+		},
+	}
+
+	maskOverrides(pkg, overrides)
+	require.Equal(t, PkgOverrideMap{
+		"component": {
+			"chart": {
+				"replicas": 2,
+				"db":       map[string]interface{}{"url": sanitizedValue, "port": 5432},
+				"admin":    map[string]interface{}{"password": sanitizedValue, "user": "admin"},
+				"tokens":   sanitizedValue,
+			},
+		},
+	}, overrides)
+}
+
+func TestVerifyPlannedPackage(t *testing.T) {
+	app := types.Package{
+		Name:    "app",
+		Imports: []types.BundleVariableImport{{Package: "db", Name: "PASSWORD"}, {Package: "cache", Name: "HOST"}},
+		Overrides: map[string]map[string]types.BundleChartOverrides{
+			"component": {"chart": {Values: []types.BundleChartValue{{Path: "cache", Value: "${HOST}"}}}},
+		},
+	}
+	newBundle := func(set map[string]string) *Bundle {
+		return &Bundle{cfg: &types.BundleConfig{DeployOpts: types.BundleDeployOptions{SetVariables: set, PlanFile: "plan.json"}}}
+	}
+
+	// the plan expects db to be deployed and cache to be skipped with the host it exported last time
+	planner := newBundle(map[string]string{"replicas": "2"})
+	_, _, configHash, err := planner.resolveConfig(app, map[string]map[string]string{
+		"db":    exportPlaceholders("db", []string{"PASSWORD"}),
+		"cache": {"HOST": "cache.local"},
+	})
+	require.NoError(t, err)
+	deployPlan := &types.DeployPlan{Packages: []types.PackagePlan{{Name: "db"}, {Name: "cache"}, {Name: "app", ConfigHash: configHash}}}
+	deployedDB := func(name string) bool { return name == "db" }
+
+	tests := []struct {
+		name     string
+		set      map[string]string
+		exported map[string]map[string]string
+		pkg      types.Package
+		err      string
+	}{
+		{
+			name:     "values exported by packages deployed during the deploy aren't compared",
+			set:      map[string]string{"replicas": "2"},
+			exported: map[string]map[string]string{"db": {"PASSWORD": "generated"}, "cache": {"HOST": "cache.local"}},
+			pkg:      app,
+		},
+		{
+			name:     "values exported by skipped packages are compared",
+			set:      map[string]string{"replicas": "2"},
+			exported: map[string]map[string]string{"db": {"PASSWORD": "generated"}, "cache": {"HOST": "cache.remote"}},
+			pkg:      app,
+			err:      "package app variables or overrides changed since the deploy plan plan.json was made",
+		},
+		{
+			name:     "variables set for the deploy are compared",
+			set:      map[string]string{"replicas": "3"},
+			exported: map[string]map[string]string{"db": {"PASSWORD": "generated"}, "cache": {"HOST": "cache.local"}},
+			pkg:      app,
+			err:      "package app variables or overrides changed",
+		},
+		{
+			name: "packages missing from the plan",
+			set:  map[string]string{"replicas": "2"},
+			pkg:  types.Package{Name: "other"},
+			err:  "package other isn't in the deploy plan plan.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBundle(tt.set)
+			b.deployPlan = deployPlan
+			err := b.verifyPlannedPackage(tt.pkg, tt.exported, deployedDB)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPlanFileHidesSensitiveValues(t *testing.T) {
+	pkg := types.Package{
+		Name: "app",
+		Ref:  "0.0.1@sha256:abc",
+		Overrides: map[string]map[string]types.BundleChartOverrides{
+			"component": {"chart": {Variables: []types.BundleChartVariable{{Name: "DB_PASSWORD", Path: "db.password"}}}},
+		},
+	}
+	planOut := filepath.Join(t.TempDir(), "plan.json")
+	b := &Bundle{
+		cfg: &types.BundleConfig{DeployOpts: types.BundleDeployOptions{
+			SetVariables: map[string]string{"DB_PASSWORD": "hunter2"},
+			DryRun:       true,
+			PlanOut:      planOut,
+		}},
+		bundle: types.UDSBundle{Metadata: types.UDSMetadata{Name: "example"}, Packages: []types.Package{pkg}},
+	}
+	// set the key up front so the dry run doesn't look for one in the current cluster
+	key, err := state.NewConfigKey()
+	require.NoError(t, err)
+	b.configKey = key
+	require.NoError(t, b.DryRun())
+
+	data, err := os.ReadFile(planOut)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "hunter2")
+	var plan types.DeployPlan
+	require.NoError(t, json.Unmarshal(data, &plan))
+	require.Len(t, plan.Packages, 1)
+
+	// the hash is keyed with the bundle's config key, so it can't be brute-forced from the plan alone
+	guess := func(password string) []string {
+		vars := map[string]string{"DB_PASSWORD": password}
+		overrides := PkgOverrideMap{"component": {"chart": {"db": map[string]interface{}{"password": password}}}}
+		unkeyed, err := json.Marshal(struct {
+			Variables          map[string]string
+			ValuesOverrides    PkgOverrideMap
+			NamespaceOverrides map[string]map[string]string
+			OptionalComponents []string
+		}{vars, overrides, map[string]map[string]string{}, nil})
+		require.NoError(t, err)
+		withoutKey, err := packageConfigHash(nil, pkg, vars, overrides, map[string]map[string]string{})
+		require.NoError(t, err)
+		withBundleKey, err := packageConfigHash(b.configKey, pkg, vars, overrides, map[string]map[string]string{})
+		require.NoError(t, err)
+		return []string{fmt.Sprintf("%x", sha256.Sum256(unkeyed)), withoutKey, withBundleKey}
+	}
+	for _, password := range []string{"password", "hunter1", "hunter2"} {
+		hashes := guess(password)
+		require.NotContains(t, hashes[:2], plan.Packages[0].ConfigHash)
+		// only the bundle's key, which isn't in the plan, reproduces the hash of the real value
+		require.Equal(t, password == "hunter2", hashes[2] == plan.Packages[0].ConfigHash)
+	}
+}
+
+func TestResolveConfigExports(t *testing.T) {
+	app := types.Package{Name: "app", DependsOn: []string{"db"}}
+	exports := map[string]map[string]string{
+		"db":    {"PASSWORD": "generated"},
+		"cache": {"HOST": "cache.local"},
+	}
+	configHash := func(concurrency int, exports map[string]map[string]string) string {
+		b := &Bundle{cfg: &types.BundleConfig{DeployOpts: types.BundleDeployOptions{Concurrency: concurrency}}, configKey: []byte("key")}
+		_, _, configHash, err := b.resolveConfig(app, exports)
+		require.NoError(t, err)
+		return configHash
+	}
+	dependencyOnly := map[string]map[string]string{"db": exports["db"]}
+
+	// sequential deploys use every earlier package's exports, like the deploy itself
+	require.Equal(t, configHash(0, exports), configHash(1, exports))
+	require.NotEqual(t, configHash(1, exports), configHash(1, dependencyOnly))
+
+	// concurrent deploys only use the exports of the package's dependencies
+	require.Equal(t, configHash(3, exports), configHash(3, dependencyOnly))
+	require.Equal(t, configHash(1, dependencyOnly), configHash(3, exports))
+}
+
+func TestDryRunWithoutConfigKey(t *testing.T) {
+	// no cluster, so the bundle has no config key
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	pkg := types.Package{Name: "app", Ref: "0.0.1@sha256:abc"}
+	planOut := filepath.Join(t.TempDir(), "plan.json")
+	newBundle := func(opts types.BundleDeployOptions) *Bundle {
+		opts.SetVariables = map[string]string{"DB_PASSWORD": "hunter2"}
+		return &Bundle{
+			cfg:    &types.BundleConfig{DeployOpts: opts},
+			bundle: types.UDSBundle{Metadata: types.UDSMetadata{Name: "example"}, Packages: []types.Package{pkg}},
+		}
+	}
+
+	// the plan is hashed with a new key that's saved next to it rather than in the cluster
+	planner := newBundle(types.BundleDeployOptions{DryRun: true, PlanOut: planOut})
+	require.NoError(t, planner.DryRun())
+	key, err := os.ReadFile(planOut + ".key")
+	require.NoError(t, err)
+	require.Equal(t, planner.configKey, key)
+	info, err := os.Stat(planOut + ".key")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// a deploy with the plan verifies it with the saved key
+	deployer := newBundle(types.BundleDeployOptions{PlanFile: planOut})
+	require.NoError(t, deployer.verifyPlan([]types.Package{pkg}))
+	require.Equal(t, key, deployer.configKey)
+
+	// and can't without it
+	require.NoError(t, os.Remove(planOut+".key"))
+	deployer = newBundle(types.BundleDeployOptions{PlanFile: planOut})
+	require.ErrorContains(t, deployer.verifyPlan([]types.Package{pkg}), "so the deploy plan can only be verified with")
+}
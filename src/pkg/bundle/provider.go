@@ -12,9 +12,10 @@ import (
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 )
 
 // Provider is an interface for processing bundles
@@ -30,6 +31,9 @@ type Provider interface {
 	//
 	// : if OCI ref
 	// : : pulls the metadata from the OCI ref
+	//
+	// : if OCI image layout
+	// : : returns the paths of the metadata in the layout
 	LoadBundleMetadata() (types.PathMap, error)
 
 	// LoadBundle loads a bundle into the temporary directory and returns a map of the bundle's files
@@ -40,11 +44,20 @@ type Provider interface {
 	// CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
 	CreateBundleSBOM(extractSBOM bool) error
 
-	// PublishBundle publishes a bundle to a remote OCI repo
-	PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote) error
+	// PublishBundle publishes a bundle to a remote OCI repo, tagging it with each of tags
+	PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, tags []string) error
 
 	// getBundleManifest gets the bundle's root manifest
 	getBundleManifest() (*oci.Manifest, error)
+
+	// getBundleRootDesc gets the descriptor of the bundle's root manifest
+	getBundleRootDesc() (ocispec.Descriptor, error)
+
+	// getReferrers gets the artifacts attached to the bundle's root manifest, and the storage to fetch them from
+	getReferrers() ([]ocispec.Descriptor, content.ReadOnlyStorage, error)
+
+	// verifyBlobs checks that every blob in the bundle matches its digest
+	verifyBlobs() error
 }
 
 // NewBundleProvider returns a new bundler Provider based on the source type
@@ -57,7 +70,7 @@ func NewBundleProvider(source, destination string) (Provider, error) {
 			OS:           oci.MultiOS,
 		}
 		// get remote client
-		remote, err := zoci.NewRemote(source, platform)
+		remote, err := boci.NewRemote(source, platform)
 		if err != nil {
 			return nil, err
 		}
@@ -72,6 +85,13 @@ func NewBundleProvider(source, destination string) (Provider, error) {
 
 		return &op, nil
 	}
+	if utils.IsValidLayoutPath(source) {
+		lp := layoutBundleProvider{ctx: ctx, src: source, dst: destination}
+		if err := lp.loadBundleManifest(); err != nil {
+			return nil, err
+		}
+		return &lp, nil
+	}
 	if !utils.IsValidTarballPath(source) {
 		return nil, fmt.Errorf("invalid tarball path: %s", source)
 	}
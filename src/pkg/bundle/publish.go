@@ -5,17 +5,20 @@
 package bundle
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	av3 "github.com/mholt/archiver/v3"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
 )
 
 // Publish publishes a bundle to a remote OCI registry
@@ -35,32 +38,69 @@ func (b *Bundle) Publish() error {
 	if err := utils.ReadYAMLStrict(loaded[config.BundleYAML], &b.bundle); err != nil {
 		return err
 	}
-	err = os.RemoveAll(filepath.Join(b.tmp, "blobs")) // clear tmp dir
-	if err != nil {
-		return err
-	}
+	events.SetBundle(b.bundle.Metadata.Name)
 
-	// unarchive bundle into empty tmp dir
-	err = av3.Unarchive(b.cfg.PublishOpts.Source, b.tmp) // todo: awkward to use old version of mholt/archiver
-	if err != nil {
-		return err
+	// bundles in a registry are copied straight to the destination registry
+	// and bundles in an OCI image layout are published straight from the layout
+	if !helpers.IsOCIURL(b.cfg.PublishOpts.Source) && !utils.IsValidLayoutPath(b.cfg.PublishOpts.Source) {
+		err = os.RemoveAll(filepath.Join(b.tmp, "blobs")) // clear tmp dir
+		if err != nil {
+			return err
+		}
+
+		// unarchive bundle into empty tmp dir
+		err = av3.Unarchive(b.cfg.PublishOpts.Source, b.tmp) // todo: awkward to use old version of mholt/archiver
+		if err != nil {
+			return err
+		}
 	}
 
 	// create new OCI artifact in remote
 	ociURL := b.cfg.PublishOpts.Destination
-	bundleName := b.bundle.Metadata.Name
-	bundleTag := b.bundle.Metadata.Version
+	repository := b.cfg.PublishOpts.Repository
+	if repository == "" {
+		repository = b.bundle.Metadata.Name
+	}
+	tags, err := boci.ResolveTags(b.cfg.PublishOpts.Tags, b.bundle.Metadata)
+	if err != nil {
+		return err
+	}
 	platform := ocispec.Platform{
 		Architecture: config.GetArch(),
 		OS:           oci.MultiOS,
 	}
-	remote, err := zoci.NewRemote(fmt.Sprintf("%s/%s:%s", ociURL, bundleName, bundleTag), platform)
+	remote, err := boci.NewRemote(fmt.Sprintf("%s/%s:%s", ociURL, repository, tags[0]), platform)
 	if err != nil {
 		return err
 	}
-	err = provider.PublishBundle(b.bundle, remote.OrasRemote)
+	err = provider.PublishBundle(b.bundle, remote.OrasRemote, tags)
 	if err != nil {
 		return err
 	}
+	return publishReferrers(provider, remote.Repo())
+}
+
+// publishReferrers copies the artifacts attached to the source bundle to the published bundle, and attaches the bundle's signature if it has one
+func publishReferrers(provider Provider, dst oras.GraphTarget) error {
+	ctx := context.TODO()
+	rootDesc, err := provider.getBundleRootDesc()
+	if err != nil {
+		return err
+	}
+	referrers, src, err := provider.getReferrers()
+	if err != nil {
+		return err
+	}
+	if err := boci.CopyReferrers(ctx, src, dst, rootDesc, referrers); err != nil {
+		return err
+	}
+
+	rootManifest, err := provider.getBundleManifest()
+	if err != nil {
+		return err
+	}
+	if sigDesc := rootManifest.Locate(config.BundleYAMLSignature); !oci.IsEmptyDescriptor(sigDesc) {
+		return boci.AttachSignature(ctx, dst, rootDesc, sigDesc)
+	}
 	return nil
 }
@@ -10,28 +10,45 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	zarfConfig "github.com/defenseunicorns/zarf/src/config"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	"github.com/mholt/archiver/v4"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
 )
 
 // Pull pulls a bundle and saves it locally
 func (b *Bundle) Pull() error {
 	ctx := context.TODO()
+	// check the format before touching the cache
+	if format := b.cfg.PullOpts.Format; format != "" && format != types.PullFormatTarball && format != types.PullFormatOCILayout {
+		return fmt.Errorf("invalid format %q, must be %s or %s", format, types.PullFormatTarball, types.PullFormatOCILayout)
+	}
+
 	// use uds-cache/packages as the dst dir for the pull to get auto caching
 	// we use an ORAS ocistore to make that dir look like an OCI artifact
-	cacheDir := filepath.Join(zarfConfig.GetAbsCachePath(), "packages")
+	cacheDir := filepath.Join(zarfConfig.GetAbsCachePath(), config.UDSCachePackages)
 	if err := helpers.CreateDirectory(cacheDir, 0o755); err != nil {
 		return err
 	}
 
+	// keep the cache from being pruned by another process while the bundle is pulled into it
+	unlock, err := cache.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Get validated source path
 	source, err := CheckOCISourcePath(b.cfg.PullOpts.Source)
 	if err != nil {
@@ -43,6 +60,31 @@ func (b *Bundle) Pull() error {
 	if err != nil {
 		return err
 	}
+	rootManifest, err := provider.getBundleManifest()
+	if err != nil {
+		return err
+	}
+
+	// only pull the selected packages, checking how signatures are handled before pulling anything
+	b.cfg.PullOpts.Packages = splitPackageNames(b.cfg.PullOpts.Packages)
+	if len(b.cfg.PullOpts.Packages) > 0 {
+		referrers, storage, err := provider.getReferrers()
+		if err != nil {
+			return err
+		}
+		signatures, err := boci.ManifestSignatures(ctx, storage, referrers)
+		if err != nil {
+			return err
+		}
+		if err := checkSubsetSignature(rootManifest, len(signatures) > 0, b.cfg.PullOpts); err != nil {
+			return err
+		}
+		if b.cfg.PullOpts.Referrers {
+			message.Warn("Artifacts attached to the bundle are not pulled with --packages, as they refer to the full bundle")
+		}
+	} else if len(b.cfg.PullOpts.SigningKeyPaths) > 0 || b.cfg.PullOpts.KeepSignature {
+		return fmt.Errorf("--signing-key and --keep-signature can only be used with --packages")
+	}
 
 	// pull the bundle's uds-bundle.yaml and it's Zarf pkgs
 	bundle, loaded, err := provider.LoadBundle(b.cfg.PullOpts, zarfConfig.CommonOptions.OCIConcurrency)
@@ -50,13 +92,23 @@ func (b *Bundle) Pull() error {
 		return err
 	}
 	b.bundle = *bundle
+	events.SetBundle(b.bundle.Metadata.Name)
+
+	// mark the bundle's blobs in the cache as used so they are pruned last
+	var pulled []string
+	for _, abs := range loaded {
+		pulled = append(pulled, abs)
+	}
+	if err := cache.Touch(pulled...); err != nil {
+		return err
+	}
 
 	// create a remote client just to resolve the root descriptor
 	platform := ocispec.Platform{
 		Architecture: config.GetArch(),
 		OS:           oci.MultiOS,
 	}
-	remote, err := zoci.NewRemote(b.cfg.PullOpts.Source, platform)
+	remote, err := boci.NewRemote(b.cfg.PullOpts.Source, platform)
 	if err != nil {
 		return err
 	}
@@ -67,6 +119,18 @@ func (b *Bundle) Pull() error {
 		return err
 	}
 
+	// rewrite the bundle's metadata and root manifest to only reference the selected packages, otherwise keep the bundle's signatures
+	// and any other artifacts attached to it
+	var referrers []ocispec.Descriptor
+	if len(b.cfg.PullOpts.Packages) > 0 {
+		rootDesc, referrers, err = b.writeSubsetRoot(ctx, rootManifest.Manifest, rootDesc, loaded)
+	} else {
+		referrers, err = b.pullReferrers(ctx, remote.Repo(), rootDesc)
+	}
+	if err != nil {
+		return err
+	}
+
 	// make an index.json for this bundle and write to tmp
 	index := ocispec.Index{}
 	index.SchemaVersion = 2
@@ -76,6 +140,7 @@ func (b *Bundle) Pull() error {
 	}
 	rootDesc.Annotations = annotations // maintain the tag
 	index.Manifests = append(index.Manifests, rootDesc)
+	index.Manifests = append(index.Manifests, referrers...)
 	bytes, err := json.MarshalIndent(index, "", "  ")
 	if err != nil {
 		return err
@@ -85,9 +150,37 @@ func (b *Bundle) Pull() error {
 		return err
 	}
 
+	pathMap := make(types.PathMap)
+
+	// put the index.json and oci-layout at the root of the tarball
+	pathMap[filepath.Join(b.tmp, "index.json")] = "index.json"
+	pathMap[filepath.Join(cacheDir, "oci-layout")] = "oci-layout"
+
+	// re-map the paths to be relative to the cache directory
+	for sha, abs := range loaded {
+		if slices.Contains(config.BundleAlwaysPull, sha) {
+			sha = filepath.Base(abs)
+		}
+		pathMap[abs] = filepath.Join(config.BlobsDir, sha)
+	}
+	if len(referrers) > 0 {
+		blobsDir := filepath.Join(b.tmp, config.BundleReferrers, config.BlobsDir)
+		blobs, err := os.ReadDir(blobsDir)
+		if err != nil {
+			return err
+		}
+		for _, blob := range blobs {
+			pathMap[filepath.Join(blobsDir, blob.Name())] = filepath.Join(config.BlobsDir, blob.Name())
+		}
+	}
+
+	name := fmt.Sprintf("%s%s-%s-%s", config.BundlePrefix, b.bundle.Metadata.Name, b.bundle.Metadata.Architecture, b.bundle.Metadata.Version)
+	if b.cfg.PullOpts.Format == types.PullFormatOCILayout {
+		return writeLayout(filepath.Join(b.cfg.PullOpts.OutputDirectory, name), pathMap)
+	}
+
 	// tarball the bundle
-	filename := fmt.Sprintf("%s%s-%s-%s.tar.zst", config.BundlePrefix, b.bundle.Metadata.Name, b.bundle.Metadata.Architecture, b.bundle.Metadata.Version)
-	dst := filepath.Join(b.cfg.PullOpts.OutputDirectory, filename)
+	dst := filepath.Join(b.cfg.PullOpts.OutputDirectory, name+".tar.zst")
 
 	_ = os.RemoveAll(dst)
 
@@ -104,20 +197,6 @@ func (b *Bundle) Pull() error {
 		Archival:    archiver.Tar{},
 	}
 
-	pathMap := make(types.PathMap)
-
-	// put the index.json and oci-layout at the root of the tarball
-	pathMap[filepath.Join(b.tmp, "index.json")] = "index.json"
-	pathMap[filepath.Join(cacheDir, "oci-layout")] = "oci-layout"
-
-	// re-map the paths to be relative to the cache directory
-	for sha, abs := range loaded {
-		if sha == config.BundleYAML || sha == config.BundleYAMLSignature {
-			sha = filepath.Base(abs)
-		}
-		pathMap[abs] = filepath.Join(config.BlobsDir, sha)
-	}
-
 	files, err := archiver.FilesFromDisk(nil, pathMap)
 	if err != nil {
 		return err
@@ -132,3 +211,41 @@ func (b *Bundle) Pull() error {
 
 	return nil
 }
+
+// pullReferrers copies the bundle's signatures into the tmp dir, along with the other artifacts attached to the bundle when --referrers
+// is set, returning their manifests' descriptors
+func (b *Bundle) pullReferrers(ctx context.Context, src content.ReadOnlyGraphStorage, rootDesc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	referrers, err := boci.Referrers(ctx, src, rootDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !b.cfg.PullOpts.Referrers {
+		referrers = slices.DeleteFunc(referrers, func(desc ocispec.Descriptor) bool {
+			return desc.ArtifactType != config.BundleSignatureArtifactType
+		})
+	}
+	if len(referrers) == 0 {
+		return nil, nil
+	}
+	store, err := ocistore.NewWithContext(ctx, filepath.Join(b.tmp, config.BundleReferrers))
+	if err != nil {
+		return nil, err
+	}
+	if err := boci.CopyReferrers(ctx, src, store, rootDesc, referrers); err != nil {
+		return nil, err
+	}
+	message.Debugf("Pulled %d artifacts attached to the bundle", len(referrers))
+	return referrers, nil
+}
+
+// writeLayout writes the files in pathMap to an OCI image layout directory at dst, replacing anything already at dst
+func writeLayout(dst string, pathMap types.PathMap) error {
+	_ = os.RemoveAll(dst)
+	for src, rel := range pathMap {
+		if err := helpers.CreatePathAndCopy(src, filepath.Join(dst, rel)); err != nil {
+			return err
+		}
+	}
+	message.Debug("OCI image layout saved to", dst)
+	return nil
+}
@@ -0,0 +1,21 @@
+package bundle
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	zarfConfig "github.com/defenseunicorns/zarf/src/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRejectsFormatBeforeTouchingCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache")
+	previous := zarfConfig.CommonOptions.CachePath
+	zarfConfig.CommonOptions.CachePath = cachePath
+	t.Cleanup(func() { zarfConfig.CommonOptions.CachePath = previous })
+
+	b := &Bundle{cfg: &types.BundleConfig{PullOpts: types.BundlePullOptions{Source: "localhost:888/example:0.0.1", Format: "zip"}}}
+	require.ErrorContains(t, b.Pull(), `invalid format "zip"`)
+	require.NoDirExists(t, cachePath)
+}
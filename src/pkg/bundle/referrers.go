@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// blobStorage is a read-only store for the blobs of a local bundle, backed by a func that returns the path of a blob
+type blobStorage func(desc ocispec.Descriptor) (string, error)
+
+// Fetch opens a blob of a local bundle
+func (s blobStorage) Fetch(_ context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	path, err := s(desc)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Exists checks if a blob is in a local bundle
+func (s blobStorage) Exists(_ context.Context, desc ocispec.Descriptor) (bool, error) {
+	if _, err := s(desc); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+// splitReferrers splits the manifests of a local bundle's index.json into the bundle's manifests and the artifacts attached to it
+func splitReferrers(manifests []ocispec.Descriptor) (bundles []ocispec.Descriptor, referrers []ocispec.Descriptor) {
+	for _, desc := range manifests {
+		if desc.ArtifactType != "" {
+			referrers = append(referrers, desc)
+			continue
+		}
+		bundles = append(bundles, desc)
+	}
+	return bundles, referrers
+}
+
+// localReferrers returns the entries of a local bundle's index.json that are attached to root
+func localReferrers(ctx context.Context, storage content.Fetcher, entries []ocispec.Descriptor, root ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var referrers []ocispec.Descriptor
+	for _, desc := range entries {
+		manifest, err := boci.FetchReferrer(ctx, storage, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read referrer %s: %w", desc.Digest, err)
+		}
+		if manifest.Subject != nil && manifest.Subject.Digest == root.Digest {
+			referrers = append(referrers, desc)
+		}
+	}
+	return referrers, nil
+}
+
+// Attach attaches files to a published bundle as an OCI referrer
+func (b *Bundle) Attach() error {
+	ctx := context.TODO()
+	opts := b.cfg.AttachOpts
+	artifactType, err := boci.ArtifactType(opts.ArtifactType)
+	if err != nil {
+		return err
+	}
+	if len(opts.Files) == 0 {
+		return fmt.Errorf("at least one file to attach is required")
+	}
+
+	source, err := CheckOCISourcePath(opts.Source)
+	if err != nil {
+		return err
+	}
+	if !helpers.IsOCIURL(source) {
+		return fmt.Errorf("artifacts can only be attached to bundles in a registry, publish %s first", source)
+	}
+
+	platform := ocispec.Platform{
+		Architecture: config.GetArch(),
+		OS:           oci.MultiOS,
+	}
+	remote, err := boci.NewRemote(source, platform)
+	if err != nil {
+		return err
+	}
+	rootDesc, err := remote.ResolveRoot(ctx)
+	if err != nil {
+		return err
+	}
+	layers, err := boci.PushFiles(ctx, remote.Repo(), opts.Files)
+	if err != nil {
+		return err
+	}
+	desc, err := boci.Attach(ctx, remote.Repo(), rootDesc, artifactType, layers)
+	if err != nil {
+		return err
+	}
+	message.Successf("Attached %s %s to %s", boci.ShortArtifactType(artifactType), desc.Digest, source)
+	return nil
+}
+
+// listReferrers shows the artifacts attached to a bundle, and saves them to the bundle-referrers dir if extract is true
+func listReferrers(provider Provider, extract bool) error {
+	ctx := context.TODO()
+	referrers, storage, err := provider.getReferrers()
+	if err != nil {
+		return err
+	}
+	if len(referrers) == 0 {
+		message.Info("No artifacts are attached to this bundle")
+		return nil
+	}
+
+	var rows [][]string
+	for _, desc := range referrers {
+		manifest, err := boci.FetchReferrer(ctx, storage, desc)
+		if err != nil {
+			return err
+		}
+		var files []string
+		size := int64(0)
+		for _, layer := range manifest.Layers {
+			files = append(files, layer.Annotations[ocispec.AnnotationTitle])
+			size += layer.Size
+		}
+		shortType := boci.ShortArtifactType(desc.ArtifactType)
+		rows = append(rows, []string{
+			shortType,
+			desc.Digest.String(),
+			strings.Join(files, ", "),
+			zarfUtils.ByteFormat(float64(size), 2),
+			manifest.Annotations[ocispec.AnnotationCreated],
+		})
+
+		if extract {
+			dir := filepath.Join(config.BundleReferrers, fmt.Sprintf("%s-%s", strings.ReplaceAll(shortType, "/", "_"), desc.Digest.Encoded()[:12]))
+			if err := boci.SaveReferrer(ctx, storage, manifest, dir); err != nil {
+				return err
+			}
+		}
+	}
+	message.Table([]string{"Artifact Type", "Digest", "Files", "Size", "Created"}, rows)
+	if extract {
+		message.Infof("Attached artifacts saved to %s", config.BundleReferrers)
+	}
+	return nil
+}
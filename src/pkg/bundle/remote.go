@@ -7,26 +7,31 @@ package bundle
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/cluster"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	goyaml "github.com/goccy/go-yaml"
 	"github.com/mholt/archiver/v4"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	ocistore "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
 )
 
 const (
@@ -52,6 +57,26 @@ func (op *ociProvider) getBundleManifest() (*oci.Manifest, error) {
 	return nil, fmt.Errorf("bundle root manifest not loaded")
 }
 
+func (op *ociProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	return op.ResolveRoot(context.TODO())
+}
+
+// getReferrers gets the artifacts attached to the bundle in the registry
+func (op *ociProvider) getReferrers() ([]ocispec.Descriptor, content.ReadOnlyStorage, error) {
+	ctx := context.TODO()
+	rootDesc, err := op.ResolveRoot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	referrers, err := boci.Referrers(ctx, op.Repo(), rootDesc)
+	return referrers, op.Repo(), err
+}
+
+// verifyBlobs is a no-op for bundles in a registry, their blobs are fetched by digest and checked by ORAS as they're pulled
+func (op *ociProvider) verifyBlobs() error {
+	return nil
+}
+
 // LoadBundleMetadata loads a remote bundle's metadata
 func (op *ociProvider) LoadBundleMetadata() (types.PathMap, error) {
 	ctx := context.TODO()
@@ -94,7 +119,8 @@ func (op *ociProvider) CreateBundleSBOM(extractSBOM bool) error {
 
 	// iterate through Zarf image manifests and find the Zarf pkg's sboms.tar
 	for _, layer := range root.Layers {
-		if layer.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
+		// skip the bundle's metadata, Zarf image manifests don't have title annotations
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
 			continue
 		}
 		zarfManifest, err := op.OrasRemote.FetchManifest(ctx, layer)
@@ -155,9 +181,14 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 	}
 
 	// validate the sig (if present) before pulling the whole bundle
-	if err := ValidateBundleSignature(loaded[config.BundleYAML], loaded[config.BundleYAMLSignature], opts.PublicKeyPath); err != nil {
+	if err := verifyBundle(op, loaded, opts.PublicKeyPaths, opts.KeyThreshold, opts.RequireManifestSignature); err != nil {
 		return nil, nil, err
 	}
+	if len(opts.Packages) > 0 {
+		if err := selectBundlePackages(&bundle, opts.Packages); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	var layersToPull []ocispec.Descriptor
 	estimatedBytes := int64(0)
@@ -215,62 +246,250 @@ func (op *ociProvider) LoadBundle(opts types.BundlePullOptions, _ int) (*types.U
 	return &bundle, loaded, nil
 }
 
-func (op *ociProvider) PublishBundle(_ types.UDSBundle, _ *oci.OrasRemote) error {
-	// todo: implement moving bundles from one registry to another
-	return fmt.Errorf("moving bundles in between remote registries not yet supported")
-}
-
-// Returns the validated source path based on the provided oci source path
-func getOCIValidatedSource(source string) (string, error) {
+// PublishBundle copies a bundle from its registry to another, streaming each blob without staging the bundle on disk
+//
+// the root manifest of every arch in the bundle's index is copied along with the artifacts attached to it. Blobs that
+// already exist in the destination are skipped, and blobs are mounted rather than copied when both repos are in the same registry
+func (op *ociProvider) PublishBundle(_ types.UDSBundle, remote *oci.OrasRemote, tags []string) error {
 	ctx := context.TODO()
-	originalSource := source
+	roots, err := op.platformRoots(ctx)
+	if err != nil {
+		return err
+	}
 
-	platform := ocispec.Platform{
-		Architecture: config.GetArch(),
-		OS:           oci.MultiOS,
+	srcRef, dstRef := op.Repo().Reference, remote.Repo().Reference
+	onCopied := func(_ context.Context, desc ocispec.Descriptor) error {
+		events.LayerPushed(desc)
+		return nil
 	}
-	// Check provided repository path
-	sourceWithOCI := boci.EnsureOCIPrefix(source)
-	remote, err := zoci.NewRemote(sourceWithOCI, platform)
-	if err == nil {
-		source = sourceWithOCI
-		_, err = remote.ResolveRoot(ctx)
+	onSkipped := func(_ context.Context, desc ocispec.Descriptor) error {
+		message.Debugf("Skipping %s, it already exists in %s", desc.Digest, dstRef)
+		return nil
+	}
+	mountFrom := func(_ context.Context, _ ocispec.Descriptor) ([]string, error) {
+		return []string{srcRef.Repository}, nil
+	}
+	crossRepoMount := srcRef.Registry == dstRef.Registry && srcRef.Repository != dstRef.Repository
+	if crossRepoMount {
+		message.Debugf("Performing cross repository blob mounts from %s --> %s", srcRef.Repository, dstRef.Repository)
+	}
+
+	// the destination's indexes are updated rather than replaced so archs that are only in the destination are kept
+	indexes, err := boci.GetIndexes(remote, tags)
+	if err != nil {
+		return err
+	}
+
+	var platformRoots []boci.PlatformRoot
+	for _, rootDesc := range roots {
+		platformBundle, layersToCopy, estimatedBytes, err := op.bundledLayers(ctx, rootDesc)
+		if err != nil {
+			return err
+		}
+
+		copyOpts := boci.CreateCopyOpts(layersToCopy, config.CommonOptions.OCIConcurrency)
+		copyOpts.PostCopy = onCopied
+		copyOpts.OnCopySkipped = onSkipped
+		if crossRepoMount {
+			copyOpts.MountFrom = mountFrom
+			copyOpts.OnMounted = onCopied
+		}
+
+		progressBar := message.NewProgressBar(estimatedBytes, fmt.Sprintf("Copying %s (%s) to %s", srcRef, platformBundle.Metadata.Architecture, dstRef))
+		remote.SetProgressWriter(progressBar)
+		err = oras.CopyGraph(ctx, op.Repo(), remote.Repo(), rootDesc, copyOpts.CopyGraphOptions)
+		remote.ClearProgressWriter()
+		if err != nil {
+			progressBar.Stop()
+			return err
+		}
+
+		// artifacts such as signatures and SBOMs are attached to each arch's root manifest
+		referrers, err := boci.Referrers(ctx, op.Repo(), rootDesc)
+		if err != nil {
+			progressBar.Stop()
+			return err
+		}
+		if err := boci.CopyReferrers(ctx, op.Repo(), remote.Repo(), rootDesc, referrers); err != nil {
+			progressBar.Stop()
+			return err
+		}
+		progressBar.Successf("Copied %s (%s) to %s", srcRef, platformBundle.Metadata.Architecture, dstRef)
+
+		platformRoots = append(platformRoots, boci.PlatformRoot{Bundle: platformBundle, Desc: rootDesc})
+	}
+	return boci.UpdateIndexesWithRoots(indexes, remote, platformRoots)
+}
+
+// platformRoots returns the root manifest of each arch the bundle was published for
+func (op *ociProvider) platformRoots(ctx context.Context) ([]ocispec.Descriptor, error) {
+	desc, err := op.Repo().Resolve(ctx, op.Repo().Reference.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex {
+		return []ocispec.Descriptor{desc}, nil
+	}
+	b, err := content.FetchAll(ctx, op.Repo(), desc)
+	if err != nil {
+		return nil, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, err
+	}
+	return index.Manifests, nil
+}
 
+// bundledLayers returns the bundle a root manifest was created from and the layers it holds: the bundle's config, uds-bundle.yaml
+// and signature, then the layers of each Zarf pkg that were included in the bundle
+func (op *ociProvider) bundledLayers(ctx context.Context, rootDesc ocispec.Descriptor) (*types.UDSBundle, []ocispec.Descriptor, int64, error) {
+	rootManifest, err := op.FetchManifest(ctx, rootDesc)
+	if err != nil {
+		return nil, nil, 0, err
 	}
-	// if root didn't resolve, expand the path
+	bundleYAML, err := op.FetchLayer(ctx, rootManifest.Locate(config.BundleYAML))
 	if err != nil {
-		// Check in ghcr uds bundle path
-		source = GHCRUDSBundlePath + originalSource
-		remote, err = zoci.NewRemote(source, platform)
-		if err == nil {
-			_, err = remote.ResolveRoot(ctx)
+		return nil, nil, 0, err
+	}
+	var bundle types.UDSBundle
+	if err := goyaml.Unmarshal(bundleYAML, &bundle); err != nil {
+		return nil, nil, 0, err
+	}
+
+	layers := []ocispec.Descriptor{rootManifest.Config}
+	for _, layer := range rootManifest.Layers {
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
+			layers = append(layers, layer)
 		}
+	}
+	estimatedBytes := int64(0)
+	for _, pkg := range bundle.Packages {
+		pkgLayers, estPkgBytes, err := boci.FindBundledPkgLayers(ctx, pkg, rootManifest, op.OrasRemote)
 		if err != nil {
-			message.Debugf("%s: not found", source)
-			// Check in delivery bundle path
-			source = GHCRDeliveryBundlePath + originalSource
-			remote, err = zoci.NewRemote(source, platform)
-			if err == nil {
-				_, err = remote.ResolveRoot(ctx)
+			return nil, nil, 0, err
+		}
+		layers = append(layers, pkgLayers...)
+		estimatedBytes += estPkgBytes
+	}
+	return &bundle, layers, estimatedBytes, nil
+}
+
+// searchPaths returns the paths bundle refs without a registry are searched for in, defaulting to the ghcr uds bundle, delivery bundle and packages paths
+func searchPaths() []string {
+	var paths []string
+	// search paths set with the UDS_SEARCH_PATHS env var may be comma separated
+	for _, p := range config.CommonOptions.SearchPaths {
+		for _, path := range strings.Split(p, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
 			}
-			if err != nil {
-				message.Debugf("%s: not found", source)
-				// Check in packages bundle path
-				source = GHCRPackagesPath + originalSource
-				remote, err = zoci.NewRemote(source, platform)
-				if err == nil {
-					_, err = remote.ResolveRoot(ctx)
-				}
-				if err != nil {
-					errMsg := fmt.Sprintf("%s: not found", originalSource)
-					message.Debug(errMsg)
-					return "", errors.New(errMsg)
-				}
+		}
+	}
+	if len(paths) == 0 {
+		return []string{GHCRUDSBundlePath, GHCRDeliveryBundlePath, GHCRPackagesPath}
+	}
+	return paths
+}
+
+// searchRefs returns the refs to try for the provided source in order, the source itself followed by the source in each search path, each rewritten with any matching registry mirrors
+func searchRefs(source string) ([]string, error) {
+	mirrors := config.CommonOptions.RegistryMirrors
+	sourceWithOCI := boci.EnsureOCIPrefix(source)
+	if config.CommonOptions.NoSearch {
+		ref, err := registry.ParseReference(strings.TrimPrefix(sourceWithOCI, "oci://"))
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a fully qualified bundle ref, which is required by --no-search: %w", source, err)
+		}
+		// ParseReference takes the first path segment as the registry, so short refs like org/name:tag also parse
+		if !isRegistryHost(ref.Registry) {
+			return nil, fmt.Errorf("%s is not a fully qualified bundle ref, which is required by --no-search: %s is not a registry host", source, ref.Registry)
+		}
+		return boci.MirrorRefs(sourceWithOCI, mirrors), nil
+	}
+
+	refs := boci.MirrorRefs(sourceWithOCI, mirrors)
+	// sources with a scheme are already fully qualified
+	if sourceWithOCI != source {
+		for _, path := range searchPaths() {
+			path = boci.EnsureOCIPrefix(strings.TrimSuffix(path, "/") + "/")
+			refs = append(refs, boci.MirrorRefs(path+source, mirrors)...)
+		}
+	}
+	return helpers.Unique(refs), nil
+}
+
+// isRegistryHost returns whether the first path segment of a ref looks like a registry host rather than a namespace,
+// that is it's localhost or contains a '.' or a ':port'
+func isRegistryHost(host string) bool {
+	return host == "localhost" || strings.ContainsAny(host, ".:")
+}
+
+// resolveFirst resolves refs in parallel, returning the first ref in order that resolves
+//
+// it returns as soon as every ref before the first that resolves has failed, without waiting on the refs after it
+func resolveFirst(ctx context.Context, refs []string, resolve func(ctx context.Context, ref string) error) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		idx int
+		err error
+	}
+	results := make(chan result, len(refs))
+	for i, ref := range refs {
+		go func() {
+			results <- result{idx: i, err: resolve(ctx, ref)}
+		}()
+	}
+
+	errs := make([]error, len(refs))
+	done := make([]bool, len(refs))
+	next := 0
+	for range refs {
+		r := <-results
+		done[r.idx], errs[r.idx] = true, r.err
+		for next < len(refs) && done[next] {
+			if errs[next] == nil {
+				return refs[next], nil
 			}
+			message.Debugf("%s: not found: %s", refs[next], errs[next].Error())
+			next++
 		}
 	}
-	message.Debugf("%s: found", source)
-	return source, nil
+	return "", errors.New("not found")
+}
+
+// Returns the validated source path based on the provided oci source path
+//
+// a source without a registry is searched for in the search paths from the uds-config unless --no-search is set
+func getOCIValidatedSource(source string) (string, error) {
+	platform := ocispec.Platform{
+		Architecture: config.GetArch(),
+		OS:           oci.MultiOS,
+	}
+	refs, err := searchRefs(source)
+	if err != nil {
+		return "", err
+	}
+	found, err := resolveFirst(context.TODO(), refs, func(ctx context.Context, ref string) error {
+		remote, err := boci.NewRemote(ref, platform)
+		if err != nil {
+			return err
+		}
+		_, err = remote.ResolveRoot(ctx)
+		return err
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("%s: not found in %s", source, strings.Join(refs, ", "))
+		message.Debug(errMsg)
+		return "", errors.New(errMsg)
+	}
+	if found != boci.EnsureOCIPrefix(source) {
+		message.Infof("Found %s at %s", source, found)
+	}
+	message.Debugf("%s: found", found)
+	return found, nil
 }
 
 // ValidateArch validates that the passed in arch matches the cluster arch
@@ -296,9 +515,9 @@ func ValidateArch(arch string) error {
 
 // CheckOCISourcePath checks that provided oci source path is valid, and updates it if it's missing the full path
 func CheckOCISourcePath(source string) (string, error) {
-	validTarballPath := utils.IsValidTarballPath(source)
+	validLocalPath := utils.IsValidTarballPath(source) || utils.IsValidLayoutPath(source)
 	var err error
-	if !validTarballPath {
+	if !validLocalPath {
 		source, err = getOCIValidatedSource(source)
 		if err != nil {
 			return "", err
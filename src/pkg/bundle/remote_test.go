@@ -0,0 +1,143 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		opts     types.BundleCommonOptions
+		expected []string
+		err      string
+	}{
+		{
+			name:   "default search paths",
+			source: "k3d-core-demo:0.20.0",
+			expected: []string{
+				"oci://k3d-core-demo:0.20.0",
+				GHCRUDSBundlePath + "k3d-core-demo:0.20.0",
+				GHCRDeliveryBundlePath + "k3d-core-demo:0.20.0",
+				GHCRPackagesPath + "k3d-core-demo:0.20.0",
+			},
+		},
+		{
+			name:   "configured search paths",
+			source: "example:0.0.1",
+			opts:   types.BundleCommonOptions{SearchPaths: []string{"registry.example.com/bundles", "oci://ghcr.io/example/,ghcr.io/other/"}},
+			expected: []string{
+				"oci://example:0.0.1",
+				"oci://registry.example.com/bundles/example:0.0.1",
+				"oci://ghcr.io/example/example:0.0.1",
+				"oci://ghcr.io/other/example:0.0.1",
+			},
+		},
+		{
+			name:   "search paths are mirrored",
+			source: "example:0.0.1",
+			opts: types.BundleCommonOptions{
+				SearchPaths:     []string{"ghcr.io/example/"},
+				RegistryMirrors: []types.RegistryMirror{{Prefix: "ghcr.io/", Mirrors: []string{"registry.enclave.mil/mirror/"}, SkipUpstream: true}},
+			},
+			expected: []string{"oci://example:0.0.1", "oci://registry.enclave.mil/mirror/example/example:0.0.1"},
+		},
+		{
+			name:     "sources with a scheme aren't searched for",
+			source:   "oci://ghcr.io/example/example:0.0.1",
+			expected: []string{"oci://ghcr.io/example/example:0.0.1"},
+		},
+		{
+			name:     "no search",
+			source:   "ghcr.io/example/example:0.0.1",
+			opts:     types.BundleCommonOptions{NoSearch: true},
+			expected: []string{"oci://ghcr.io/example/example:0.0.1"},
+		},
+		{
+			name:   "no search requires a fully qualified ref",
+			source: "example:0.0.1",
+			opts:   types.BundleCommonOptions{NoSearch: true},
+			err:    "example:0.0.1 is not a fully qualified bundle ref",
+		},
+		{
+			name:   "no search rejects a namespace in place of the registry",
+			source: "example/example:0.0.1",
+			opts:   types.BundleCommonOptions{NoSearch: true},
+			err:    "example/example:0.0.1 is not a fully qualified bundle ref, which is required by --no-search: example is not a registry host",
+		},
+		{
+			name:     "no search allows localhost",
+			source:   "localhost/example:0.0.1",
+			opts:     types.BundleCommonOptions{NoSearch: true},
+			expected: []string{"oci://localhost/example:0.0.1"},
+		},
+		{
+			name:     "no search allows a registry port",
+			source:   "registry:5000/example:0.0.1",
+			opts:     types.BundleCommonOptions{NoSearch: true},
+			expected: []string{"oci://registry:5000/example:0.0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := config.CommonOptions
+			config.CommonOptions = tt.opts
+			t.Cleanup(func() { config.CommonOptions = opts })
+
+			refs, err := searchRefs(tt.source)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, refs)
+		})
+	}
+}
+
+func TestResolveFirst(t *testing.T) {
+	refs := []string{"first", "second", "third"}
+
+	// the first ref in order wins even if a later ref resolves sooner
+	found, err := resolveFirst(context.Background(), refs, func(_ context.Context, ref string) error {
+		switch ref {
+		case "first":
+			return errors.New("not found")
+		case "second":
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		default:
+			return nil
+		}
+	})
+	require.NoError(t, err)
+	require.Equal(t, "second", found)
+
+	// refs after the first that resolves aren't waited on
+	block := make(chan struct{})
+	defer close(block)
+	found, err = resolveFirst(context.Background(), refs, func(ctx context.Context, ref string) error {
+		if ref == "first" {
+			return nil
+		}
+		select {
+		case <-block:
+		case <-ctx.Done():
+		}
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	require.Equal(t, "first", found)
+
+	_, err = resolveFirst(context.Background(), refs, func(_ context.Context, _ string) error {
+		return errors.New("not found")
+	})
+	require.Error(t, err)
+}
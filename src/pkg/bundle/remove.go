@@ -8,20 +8,31 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/state"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/defenseunicorns/zarf/src/pkg/packager"
+	zarfSources "github.com/defenseunicorns/zarf/src/pkg/packager/sources"
 	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	"golang.org/x/exp/slices"
 )
 
 // Remove removes packages deployed from a bundle
-func (b *Bundle) Remove() error {
+func (b *Bundle) Remove() (err error) {
+	report := b.newRemoveReport()
+	defer b.writeRemoveReport(report, &err)
+
+	// the bundle's artifact isn't needed when removing by name
+	if b.cfg.RemoveOpts.BundleName != "" {
+		return b.removeFromState(report)
+	}
 
 	// Check that provided oci source path is valid, and update it if it's missing the full path
 	source, err := CheckOCISourcePath(b.cfg.RemoveOpts.Source)
@@ -52,6 +63,8 @@ func (b *Bundle) Remove() error {
 	if err := utils.ReadYAMLStrict(loaded[config.BundleYAML], &b.bundle); err != nil {
 		return err
 	}
+	events.SetBundle(b.bundle.Metadata.Name)
+	setReportBundle(report, b.bundle.Metadata.Name, b.bundle.Metadata.Version)
 
 	// Check if --packages flag is set and zarf packages have been specified
 	var packagesToRemove []types.Package
@@ -68,47 +81,154 @@ func (b *Bundle) Remove() error {
 		if len(userSpecifiedPackages) != len(packagesToRemove) {
 			return fmt.Errorf("invalid zarf packages specified by --packages")
 		}
-		return removePackages(packagesToRemove, b)
+		return removePackages(packagesToRemove, b, report)
 	}
-	return removePackages(b.bundle.Packages, b)
+	return removePackages(b.bundle.Packages, b, report)
 }
 
-func removePackages(packagesToRemove []types.Package, b *Bundle) error {
-	// Get deployed packages
-	deployedPackageNames := GetDeployedPackageNames()
+func removePackages(packagesToRemove []types.Package, b *Bundle, report *types.Report) (err error) {
+	// remove packages in the reverse order they were deployed in
+	packagesToRemove, err = sortPackages(packagesToRemove)
+	if err != nil {
+		return err
+	}
 
-	for i := len(packagesToRemove) - 1; i >= 0; i-- {
+	var log actionLog
+	defer log.print()
+	err = runActions(&log, b.bundle.Metadata.Name, "onRemove", b.bundle.Actions.OnRemove, b.loadVariables(types.Package{}, nil), func() error {
+		return removeEach(report, packagesToRemove, func(pkg types.Package) error {
+			return runActions(&log, pkg.Name, "onRemove", pkg.Actions.OnRemove, b.loadVariables(pkg, nil), func() error {
+				return removePackage(pkg, b.cfg.RemoveOpts.Source)
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
 
-		pkg := packagesToRemove[i]
+	removeState(b.bundle.Metadata.Name, packagesToRemove)
+	return nil
+}
 
-		if slices.Contains(deployedPackageNames, pkg.Name) {
-			opts := zarfTypes.ZarfPackageOptions{
-				PackageSource: b.cfg.RemoveOpts.Source,
-			}
-			pkgCfg := zarfTypes.PackagerConfig{
-				PkgOpts: opts,
-			}
-			pkgTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
-			if err != nil {
-				return err
-			}
+// newRemoveReport starts the remove report, returning nil if --report isn't set
+func (b *Bundle) newRemoveReport() *types.Report {
+	if b.cfg.RemoveOpts.ReportPath == "" {
+		return nil
+	}
+	return &types.Report{
+		Operation: "remove",
+		StartedAt: time.Now(),
+	}
+}
 
-			sha := strings.Split(pkg.Ref, "sha256:")[1]
-			source, err := sources.New(b.cfg.RemoveOpts.Source, pkg, opts, sha, nil)
-			if err != nil {
-				return err
-			}
+// setReportBundle sets the bundle a report is for once it's known, if report isn't nil
+func setReportBundle(report *types.Report, bundleName, version string) {
+	if report == nil {
+		return
+	}
+	report.Bundle, report.Version = bundleName, version
+}
 
-			pkgClient := packager.NewOrDie(&pkgCfg, packager.WithSource(source), packager.WithTemp(pkgTmp))
-			defer pkgClient.ClearTempPaths()
+// writeRemoveReport finishes and writes the remove report with the outcome of the remove
+func (b *Bundle) writeRemoveReport(report *types.Report, err *error) {
+	if report == nil {
+		return
+	}
+	report.Source = b.cfg.RemoveOpts.Source
+	finishReport(report, *err)
+	if reportErr := writeReport(b.cfg.RemoveOpts.ReportPath, report); reportErr != nil {
+		message.WarnErr(reportErr, reportErr.Error())
+	}
+}
 
-			if err := pkgClient.Remove(context.TODO()); err != nil {
-				return err
-			}
-		} else {
-			message.Warnf("Skipping removal of %s. Package not deployed", pkg.Name)
+// removeFromState removes a deployed bundle's packages using the package list and order recorded in the cluster
+func (b *Bundle) removeFromState(report *types.Report) error {
+	client, err := state.NewClient()
+	if err != nil {
+		return err
+	}
+	bundleState, err := client.Get(context.TODO(), b.cfg.RemoveOpts.BundleName)
+	if err != nil {
+		return err
+	}
+	events.SetBundle(bundleState.Name)
+	setReportBundle(report, bundleState.Name, bundleState.Version)
+
+	// packages are recorded in the order they were deployed in
+	var packagesToRemove []types.Package
+	for _, pkgState := range bundleState.Packages {
+		packagesToRemove = append(packagesToRemove, types.Package{Name: pkgState.Name, Ref: pkgState.Ref})
+	}
+
+	// Check if --packages flag is set and zarf packages have been specified
+	if len(b.cfg.RemoveOpts.Packages) != 0 {
+		userSpecifiedPackages := strings.Split(strings.ReplaceAll(b.cfg.RemoveOpts.Packages[0], " ", ""), ",")
+		packagesToRemove = slices.DeleteFunc(packagesToRemove, func(pkg types.Package) bool {
+			return !slices.Contains(userSpecifiedPackages, pkg.Name)
+		})
+
+		// Check if invalid packages were specified
+		if len(userSpecifiedPackages) != len(packagesToRemove) {
+			return fmt.Errorf("invalid zarf packages specified by --packages")
 		}
 	}
 
+	err = removeEach(report, packagesToRemove, func(pkg types.Package) error {
+		return removeClusterPackage(pkg.Name)
+	})
+	if err != nil {
+		return err
+	}
+
+	removeState(bundleState.Name, packagesToRemove)
 	return nil
 }
+
+// removeClusterPackage removes a deployed Zarf package using only the package's deployment record in the cluster
+func removeClusterPackage(name string) error {
+	opts := zarfTypes.ZarfPackageOptions{
+		PackageSource: name,
+	}
+	pkgCfg := zarfTypes.PackagerConfig{
+		PkgOpts: opts,
+	}
+	pkgTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+
+	pkgSource, err := zarfSources.NewClusterSource(&opts)
+	if err != nil {
+		return err
+	}
+
+	pkgClient := packager.NewOrDie(&pkgCfg, packager.WithSource(pkgSource), packager.WithTemp(pkgTmp))
+	defer pkgClient.ClearTempPaths()
+
+	return pkgClient.Remove(context.TODO())
+}
+
+// removePackage removes a single Zarf package that was deployed from the bundle at source
+func removePackage(pkg types.Package, source string) error {
+	opts := zarfTypes.ZarfPackageOptions{
+		PackageSource: source,
+	}
+	pkgCfg := zarfTypes.PackagerConfig{
+		PkgOpts: opts,
+	}
+	pkgTmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+
+	sha := strings.Split(pkg.Ref, "sha256:")[1]
+	pkgSource, err := sources.New(source, pkg, opts, sha, nil)
+	if err != nil {
+		return err
+	}
+
+	pkgClient := packager.NewOrDie(&pkgCfg, packager.WithSource(pkgSource), packager.WithTemp(pkgTmp))
+	defer pkgClient.ClearTempPaths()
+
+	return pkgClient.Remove(context.TODO())
+}
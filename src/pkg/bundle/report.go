@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/cluster"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"golang.org/x/exp/slices"
+)
+
+// junitTestSuites is the root of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// startDeployReport starts the deploy report if --report is set and the report hasn't been started yet
+func (b *Bundle) startDeployReport() {
+	if b.cfg.DeployOpts.ReportPath == "" || b.report != nil {
+		return
+	}
+	b.report = &types.Report{Operation: "deploy", StartedAt: time.Now()}
+}
+
+// writeDeployReport finishes and writes the deploy report with the outcome of the deploy
+//
+// the result of each package comes from recorder, which is nil if the deploy failed before any packages were deployed
+func (b *Bundle) writeDeployReport(recorder *stateRecorder, err error) {
+	if b.report == nil {
+		return
+	}
+	report := &types.Report{
+		Operation: "deploy",
+		Bundle:    b.bundle.Metadata.Name,
+		Version:   b.bundle.Metadata.Version,
+		Source:    b.cfg.DeployOpts.Source,
+	}
+	if recorder != nil {
+		report = recorder.report()
+	}
+	report.StartedAt = b.report.StartedAt
+	finishReport(report, err)
+	// the report is only written once, by whichever step of the deploy ended it
+	b.report = nil
+	if reportErr := writeReport(b.cfg.DeployOpts.ReportPath, report); reportErr != nil {
+		message.WarnErr(reportErr, reportErr.Error())
+	}
+}
+
+// writeReport writes a report as JUnit XML if path ends in .xml and as JSON otherwise
+func writeReport(path string, report *types.Report) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		data, err = junitReport(report)
+	} else {
+		data, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, helpers.ReadWriteUser); err != nil {
+		return fmt.Errorf("unable to write the %s report: %w", report.Operation, err)
+	}
+	message.Successf("Wrote the %s report to %s", report.Operation, path)
+	return nil
+}
+
+// junitReport converts a report to JUnit XML with a test case for each package so CI systems can show which package failed
+func junitReport(report *types.Report) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("%s %s", report.Operation, report.Bundle),
+		Tests:     len(report.Packages),
+		Time:      fmt.Sprintf("%.3f", report.DurationSeconds),
+		Timestamp: report.StartedAt.Format(time.RFC3339),
+	}
+	for _, pkg := range report.Packages {
+		tc := junitTestCase{
+			ClassName: report.Bundle,
+			Name:      pkg.Name,
+			Time:      fmt.Sprintf("%.3f", pkg.DurationSeconds),
+		}
+		switch pkg.Result {
+		case types.StatusFailed:
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: fmt.Sprintf("failed to %s package %s", report.Operation, pkg.Name), Text: pkg.Error}
+		case types.StatusSkipped, types.StatusUnchanged:
+			suite.Skipped++
+			tc.Skipped = &struct{}{}
+		}
+		var out []string
+		if len(pkg.Components) > 0 {
+			out = append(out, fmt.Sprintf("components: %s", strings.Join(pkg.Components, ", ")))
+		}
+		if len(pkg.HelmReleases) > 0 {
+			out = append(out, fmt.Sprintf("helm releases: %s", strings.Join(pkg.HelmReleases, ", ")))
+		}
+		tc.SystemOut = strings.Join(out, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// report builds the deploy report from the recorded state of each package
+func (r *stateRecorder) report() *types.Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &types.Report{
+		Operation:  "deploy",
+		Bundle:     r.state.Name,
+		Version:    r.state.Version,
+		Source:     r.state.Source,
+		StartedAt:  r.state.StartedAt,
+		FinishedAt: r.state.FinishedAt,
+		Result:     r.state.Status,
+		Error:      r.state.Error,
+	}
+	report.DurationSeconds = report.FinishedAt.Sub(report.StartedAt).Seconds()
+	for _, pkg := range r.state.Packages {
+		pkgReport := types.PackageReport{
+			Name:   pkg.Name,
+			Ref:    pkg.Ref,
+			Result: r.packageResult(pkg),
+			Error:  pkg.Error,
+		}
+		if d, ok := r.packageDuration(pkg); ok {
+			pkgReport.StartedAt, pkgReport.FinishedAt = pkg.StartedAt, pkg.FinishedAt
+			pkgReport.DurationSeconds = d.Seconds()
+		}
+		if pkgReport.Result != types.StatusSkipped {
+			pkgReport.Components, pkgReport.HelmReleases = packageResources(pkg.Name)
+		}
+		report.Packages = append(report.Packages, pkgReport)
+	}
+	return report
+}
+
+// removeEach removes packages in the reverse order they were deployed in, adding the result of each to report if it isn't nil
+func removeEach(report *types.Report, packages []types.Package, remove func(pkg types.Package) error) error {
+	deployedPackageNames := GetDeployedPackageNames()
+	for i := len(packages) - 1; i >= 0; i-- {
+		pkg := packages[i]
+		pkgReport := types.PackageReport{Name: pkg.Name, Ref: pkg.Ref, StartedAt: time.Now(), Result: types.StatusSkipped}
+
+		var err error
+		if slices.Contains(deployedPackageNames, pkg.Name) {
+			// the package's resources have to be read before its deployment record is removed
+			if report != nil {
+				pkgReport.Components, pkgReport.HelmReleases = packageResources(pkg.Name)
+			}
+			events.PackageStarted(pkg.Name)
+			err = remove(pkg)
+			pkgReport.Result = types.StatusSucceeded
+			if err != nil {
+				pkgReport.Result = types.StatusFailed
+				pkgReport.Error = err.Error()
+			}
+			events.PackageFinished(pkg.Name, pkgReport.Result, time.Since(pkgReport.StartedAt), err)
+		} else {
+			message.Warnf("Skipping removal of %s. Package not deployed", pkg.Name)
+		}
+
+		if report != nil {
+			pkgReport.FinishedAt = time.Now()
+			pkgReport.DurationSeconds = pkgReport.FinishedAt.Sub(pkgReport.StartedAt).Seconds()
+			report.Packages = append(report.Packages, pkgReport)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packageResources returns the components and Helm releases of a deployed package, or nothing if it isn't deployed
+func packageResources(name string) ([]string, []string) {
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return nil, nil
+	}
+	deployed, err := c.GetDeployedPackage(context.TODO(), name)
+	if err != nil {
+		return nil, nil
+	}
+	return deployedResources(deployed)
+}
+
+func deployedResources(deployed *zarfTypes.DeployedPackage) ([]string, []string) {
+	var components, releases []string
+	for _, component := range deployed.DeployedComponents {
+		components = append(components, component.Name)
+		for _, chart := range component.InstalledCharts {
+			releases = append(releases, fmt.Sprintf("%s/%s", chart.Namespace, chart.ChartName))
+		}
+	}
+	return components, releases
+}
+
+// finishReport sets the outcome and duration of a report
+func finishReport(report *types.Report, err error) {
+	report.FinishedAt = time.Now()
+	report.DurationSeconds = report.FinishedAt.Sub(report.StartedAt).Seconds()
+	report.Result = types.StatusSucceeded
+	if err != nil {
+		report.Result = types.StatusFailed
+		report.Error = err.Error()
+	}
+}
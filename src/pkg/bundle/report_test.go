@@ -0,0 +1,144 @@
+package bundle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *types.Report {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &types.Report{
+		Operation:       "deploy",
+		Bundle:          "example",
+		Version:         "0.0.1",
+		StartedAt:       start,
+		FinishedAt:      start.Add(90 * time.Second),
+		DurationSeconds: 90,
+		Result:          types.StatusFailed,
+		Error:           "failed to deploy package podinfo",
+		Packages: []types.PackageReport{
+			{Name: "init", Result: types.StatusUnchanged, Components: []string{"zarf-registry"}},
+			{Name: "nginx", Result: types.StatusSucceeded, DurationSeconds: 30, Components: []string{"nginx"}, HelmReleases: []string{"nginx/nginx"}},
+			{Name: "podinfo", Result: types.StatusFailed, DurationSeconds: 60, Error: "timed out waiting for podinfo"},
+		},
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	data, err := junitReport(testReport())
+	require.NoError(t, err)
+
+	out := string(data)
+	require.Contains(t, out, `<testsuite name="deploy example" tests="3" failures="1" skipped="1" time="90.000" timestamp="2024-01-01T00:00:00Z">`)
+	require.Contains(t, out, `<testcase classname="example" name="init" time="0.000">`)
+	require.Contains(t, out, `<failure message="failed to deploy package podinfo">timed out waiting for podinfo</failure>`)
+	require.Contains(t, out, "<system-out>components: nginx&#xA;helm releases: nginx/nginx</system-out>")
+}
+
+func TestWriteReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contains string
+	}{
+		{name: "JSON", file: "report.json", contains: `"helmReleases": [`},
+		{name: "JUnit XML", file: "report.xml", contains: "<testsuites>"},
+		{name: "uppercase extension", file: "REPORT.XML", contains: "<testsuites>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, writeReport(path, testReport()))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Contains(t, string(data), tt.contains)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, writeReport(path, testReport()))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var report types.Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Equal(t, *testReport(), report)
+}
+
+func TestEarlyFailuresAreReported(t *testing.T) {
+	// a tarball that isn't a bundle fails when its metadata is loaded
+	notABundle := filepath.Join(t.TempDir(), "uds-bundle-example-amd64-0.0.1.tar.zst")
+	require.NoError(t, os.WriteFile(notABundle, []byte("not a bundle"), 0o600))
+	example := types.UDSBundle{
+		Metadata: types.UDSMetadata{Name: "example", Version: "0.0.1"},
+		Packages: []types.Package{{Name: "foo"}},
+	}
+
+	tests := []struct {
+		name      string
+		cfg       types.BundleConfig
+		run       func(b *Bundle) error
+		operation string
+		bundle    string
+		source    string
+		err       string
+	}{
+		{
+			name: "deploy with an invalid --packages",
+			cfg:  types.BundleConfig{DeployOpts: types.BundleDeployOptions{Packages: []string{"bar"}}},
+			run: func(b *Bundle) error {
+				b.bundle = example
+				return b.Deploy()
+			},
+			operation: "deploy",
+			bundle:    "example",
+			err:       "invalid zarf packages specified by --packages",
+		},
+		{
+			name: "deploy of a bundle that can't be loaded",
+			cfg:  types.BundleConfig{DeployOpts: types.BundleDeployOptions{Source: notABundle}},
+			run: func(b *Bundle) error {
+				_, _, _, err := b.PreDeployValidation()
+				return err
+			},
+			operation: "deploy",
+			source:    notABundle,
+		},
+		{
+			name:      "remove of a bundle that can't be loaded",
+			cfg:       types.BundleConfig{RemoveOpts: types.BundleRemoveOptions{Source: notABundle}},
+			run:       func(b *Bundle) error { return b.Remove() },
+			operation: "remove",
+			source:    notABundle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report.json")
+			tt.cfg.DeployOpts.ReportPath = path
+			tt.cfg.RemoveOpts.ReportPath = path
+			b := &Bundle{cfg: &tt.cfg, tmp: t.TempDir()}
+			err := tt.run(b)
+			require.Error(t, err)
+
+			data, readErr := os.ReadFile(path)
+			require.NoError(t, readErr)
+			var report types.Report
+			require.NoError(t, json.Unmarshal(data, &report))
+			require.Equal(t, tt.operation, report.Operation)
+			require.Equal(t, tt.bundle, report.Bundle)
+			require.Equal(t, tt.source, report.Source)
+			require.Equal(t, types.StatusFailed, report.Result)
+			require.Equal(t, err.Error(), report.Error)
+			if tt.err != "" {
+				require.Equal(t, tt.err, report.Error)
+			}
+			require.False(t, report.StartedAt.IsZero())
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/cluster"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// rollbackClient is the cluster and Helm operations used to roll back a bundle deploy
+type rollbackClient interface {
+	// getDeployedPackage returns Zarf's deployment record of a package, nil if Zarf has no record of the package
+	getDeployedPackage(name string) (*zarfTypes.DeployedPackage, error)
+	// recordPackageDeployment replaces Zarf's deployment record of a package
+	recordPackageDeployment(deployed *zarfTypes.DeployedPackage) error
+	getReleaseRevision(chart zarfTypes.InstalledChart) (int, error)
+	rollbackRelease(chart zarfTypes.InstalledChart, revision int, timeout time.Duration) error
+	uninstallRelease(chart zarfTypes.InstalledChart, timeout time.Duration) error
+	removePackage(pkg types.Package, source string) error
+}
+
+// clusterRollbackClient is the rollbackClient for the current cluster
+type clusterRollbackClient struct {
+	c *cluster.Cluster
+}
+
+func (r clusterRollbackClient) getDeployedPackage(name string) (*zarfTypes.DeployedPackage, error) {
+	deployed, err := r.c.GetDeployedPackage(context.TODO(), name)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the deployment record of package %s: %w", name, err)
+	}
+	return deployed, nil
+}
+
+func (r clusterRollbackClient) recordPackageDeployment(deployed *zarfTypes.DeployedPackage) error {
+	_, err := r.c.RecordPackageDeployment(context.TODO(), deployed.Data, deployed.DeployedComponents, deployed.ConnectStrings, deployed.Generation)
+	return err
+}
+
+func (clusterRollbackClient) getReleaseRevision(chart zarfTypes.InstalledChart) (int, error) {
+	return getReleaseRevision(chart)
+}
+
+func (clusterRollbackClient) rollbackRelease(chart zarfTypes.InstalledChart, revision int, timeout time.Duration) error {
+	return rollbackRelease(chart, revision, timeout)
+}
+
+func (clusterRollbackClient) uninstallRelease(chart zarfTypes.InstalledChart, timeout time.Duration) error {
+	return uninstallRelease(chart, timeout)
+}
+
+func (clusterRollbackClient) removePackage(pkg types.Package, source string) error {
+	return removePackage(pkg, source)
+}
+
+// rollbackPoint records the state of a package before it was deployed
+type rollbackPoint struct {
+	pkg types.Package
+	// previous is the package's deployment record from before the deploy, nil if the package was newly installed
+	previous *zarfTypes.DeployedPackage
+	// revisions maps the package's Helm releases to their revision from before the deploy
+	revisions map[zarfTypes.InstalledChart]int
+	// timeout is the package's Helm timeout, which its releases are rolled back with as well
+	timeout time.Duration
+}
+
+// rollbackTracker tracks the packages that were touched during a bundle deploy so they can be rolled back on failure
+//
+// only the packages' Helm releases and Zarf deployment records are restored. Zarf deploys manifests as Helm charts, but
+// images pushed to the Zarf registry, git repos and other resources created outside of Helm are left as they are
+type rollbackTracker struct {
+	mu     sync.Mutex
+	client rollbackClient
+	points []rollbackPoint
+}
+
+// getClient returns the tracker's client, connecting to the cluster the first time it is called
+func (r *rollbackTracker) getClient() (rollbackClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client == nil {
+		c, err := cluster.NewCluster()
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to the cluster to record rollback information: %w", err)
+		}
+		r.client = clusterRollbackClient{c: c}
+	}
+	return r.client, nil
+}
+
+// record saves the currently deployed state of a package, it must be called before the package is deployed with timeout
+func (r *rollbackTracker) record(pkg types.Package, timeout time.Duration) error {
+	client, err := r.getClient()
+	if err != nil {
+		return err
+	}
+	point := rollbackPoint{pkg: pkg, revisions: make(map[zarfTypes.InstalledChart]int), timeout: timeout}
+
+	deployed, err := client.getDeployedPackage(pkg.Name)
+	if err != nil {
+		return err
+	}
+	if deployed != nil {
+		point.previous = deployed
+		for _, component := range deployed.DeployedComponents {
+			for _, chart := range component.InstalledCharts {
+				revision, err := client.getReleaseRevision(chart)
+				if err != nil {
+					return err
+				}
+				point.revisions[chart] = revision
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = append(r.points, point)
+	return nil
+}
+
+// rollback restores the packages touched during the deploy in reverse order and prints a summary of what was restored
+func (r *rollbackTracker) rollback(source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message.HeaderInfof("↩️ ROLLING BACK BUNDLE DEPLOYMENT")
+
+	var errs []error
+	var summary [][]string
+	for i := len(r.points) - 1; i >= 0; i-- {
+		point := r.points[i]
+		result, err := point.rollback(r.client, source)
+		if err != nil {
+			message.WarnErrf(err, "Failed to roll back package %s: %s", point.pkg.Name, err.Error())
+			errs = append(errs, fmt.Errorf("package %s: %w", point.pkg.Name, err))
+			result = fmt.Sprintf("failed: %s", err.Error())
+		}
+		summary = append(summary, []string{point.pkg.Name, result})
+	}
+
+	message.HeaderInfof("📋 ROLLBACK SUMMARY")
+	message.Table([]string{"Package", "Result"}, summary)
+
+	return errors.Join(errs...)
+}
+
+// rollback rolls back a single package, removing it if it was newly installed, and describes what was done
+func (p rollbackPoint) rollback(client rollbackClient, source string) (string, error) {
+	if p.previous != nil {
+		restored, err := p.restore(client)
+		return fmt.Sprintf("restored %d Helm release(s) to generation %d", restored, p.previous.Generation), err
+	}
+	// Zarf only records a package once one of its components has deployed, and removing it relies on that record
+	current, err := client.getDeployedPackage(p.pkg.Name)
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "nothing to remove (newly installed, but never recorded by Zarf)", nil
+	}
+	if err := client.removePackage(p.pkg, source); err != nil {
+		return "", err
+	}
+	return "removed (newly installed)", nil
+}
+
+// restore rolls back the package's Helm releases that changed and restores the package's deployment record
+func (p rollbackPoint) restore(client rollbackClient) (int, error) {
+	restored := 0
+
+	// releases installed by the failed deploy that weren't previously part of the package need to be uninstalled
+	current, err := client.getDeployedPackage(p.pkg.Name)
+	if err != nil {
+		return restored, err
+	}
+	if current != nil {
+		for _, component := range current.DeployedComponents {
+			for _, chart := range component.InstalledCharts {
+				if _, ok := p.revisions[chart]; ok {
+					continue
+				}
+				if err := client.uninstallRelease(chart, p.timeout); err != nil {
+					return restored, err
+				}
+				restored++
+			}
+		}
+	}
+
+	for chart, revision := range p.revisions {
+		current, err := client.getReleaseRevision(chart)
+		if err != nil {
+			return restored, err
+		}
+		if current == revision {
+			continue // release wasn't touched by the deploy
+		}
+		message.Infof("Rolling back Helm release %s in namespace %s to revision %d", chart.ChartName, chart.Namespace, revision)
+		if err := client.rollbackRelease(chart, revision, p.timeout); err != nil {
+			return restored, err
+		}
+		restored++
+	}
+
+	// put the package's deployment record back the way it was
+	if err := client.recordPackageDeployment(p.previous); err != nil {
+		return restored, err
+	}
+	return restored, nil
+}
+
+// helmActionConfig creates a Helm action configuration for the given namespace
+func helmActionConfig(namespace string) (*action.Configuration, error) {
+	settings := cli.New()
+	settings.SetNamespace(namespace)
+	actionConfig := new(action.Configuration)
+	err := actionConfig.Init(settings.RESTClientGetter(), namespace, "", message.Debugf)
+	return actionConfig, err
+}
+
+// getReleaseRevision returns the current revision of a Helm release, 0 if the release isn't installed
+func getReleaseRevision(chart zarfTypes.InstalledChart) (int, error) {
+	actionConfig, err := helmActionConfig(chart.Namespace)
+	if err != nil {
+		return 0, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(chart.ChartName)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rel.Version, nil
+}
+
+// rollbackRelease rolls back a Helm release to the given revision, uninstalling it if the revision is 0
+func rollbackRelease(chart zarfTypes.InstalledChart, revision int, timeout time.Duration) error {
+	if revision == 0 {
+		return uninstallRelease(chart, timeout)
+	}
+	actionConfig, err := helmActionConfig(chart.Namespace)
+	if err != nil {
+		return err
+	}
+	client := action.NewRollback(actionConfig)
+	client.Version = revision
+	client.Wait = true
+	client.Timeout = timeout
+	return client.Run(chart.ChartName)
+}
+
+// uninstallRelease uninstalls a Helm release
+func uninstallRelease(chart zarfTypes.InstalledChart, timeout time.Duration) error {
+	actionConfig, err := helmActionConfig(chart.Namespace)
+	if err != nil {
+		return err
+	}
+	message.Infof("Uninstalling Helm release %s in namespace %s", chart.ChartName, chart.Namespace)
+	client := action.NewUninstall(actionConfig)
+	client.Wait = true
+	client.Timeout = timeout
+	_, err = client.Run(chart.ChartName)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil
+	}
+	return err
+}
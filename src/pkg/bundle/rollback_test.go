@@ -0,0 +1,210 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRollbackClient is a rollbackClient backed by in-memory deployment records and Helm release revisions
+type fakeRollbackClient struct {
+	deployed  map[string]*zarfTypes.DeployedPackage
+	revisions map[zarfTypes.InstalledChart]int
+	lookupErr error
+	// calls records the operations that changed the cluster in order
+	calls []string
+	// timeouts records the timeout each release was rolled back or uninstalled with
+	timeouts map[string]time.Duration
+}
+
+func (f *fakeRollbackClient) getDeployedPackage(name string) (*zarfTypes.DeployedPackage, error) {
+	if f.lookupErr != nil {
+		return nil, f.lookupErr
+	}
+	return f.deployed[name], nil
+}
+
+func (f *fakeRollbackClient) recordPackageDeployment(deployed *zarfTypes.DeployedPackage) error {
+	f.calls = append(f.calls, fmt.Sprintf("record %s@%d", deployed.Name, deployed.Generation))
+	f.deployed[deployed.Name] = deployed
+	return nil
+}
+
+func (f *fakeRollbackClient) getReleaseRevision(chart zarfTypes.InstalledChart) (int, error) {
+	return f.revisions[chart], nil
+}
+
+func (f *fakeRollbackClient) rollbackRelease(chart zarfTypes.InstalledChart, revision int, timeout time.Duration) error {
+	f.calls = append(f.calls, fmt.Sprintf("rollback %s to %d", chart.ChartName, revision))
+	f.recordTimeout(chart, timeout)
+	f.revisions[chart] = revision
+	return nil
+}
+
+func (f *fakeRollbackClient) uninstallRelease(chart zarfTypes.InstalledChart, timeout time.Duration) error {
+	f.calls = append(f.calls, fmt.Sprintf("uninstall %s", chart.ChartName))
+	f.recordTimeout(chart, timeout)
+	delete(f.revisions, chart)
+	return nil
+}
+
+func (f *fakeRollbackClient) recordTimeout(chart zarfTypes.InstalledChart, timeout time.Duration) {
+	if f.timeouts == nil {
+		f.timeouts = make(map[string]time.Duration)
+	}
+	f.timeouts[chart.ChartName] = timeout
+}
+
+func (f *fakeRollbackClient) removePackage(pkg types.Package, _ string) error {
+	f.calls = append(f.calls, fmt.Sprintf("remove %s", pkg.Name))
+	delete(f.deployed, pkg.Name)
+	return nil
+}
+
+// deployedWithCharts returns a deployment record of a package with a component that installed the charts
+func deployedWithCharts(name string, generation int, charts ...zarfTypes.InstalledChart) *zarfTypes.DeployedPackage {
+	return &zarfTypes.DeployedPackage{
+		Name:               name,
+		Generation:         generation,
+		DeployedComponents: []zarfTypes.DeployedComponent{{Name: name, InstalledCharts: charts}},
+	}
+}
+
+func TestRollbackTracker(t *testing.T) {
+	dbChart := zarfTypes.InstalledChart{Namespace: "db", ChartName: "postgres"}
+	dbOperator := zarfTypes.InstalledChart{Namespace: "db", ChartName: "operator"}
+	appChart := zarfTypes.InstalledChart{Namespace: "app", ChartName: "app"}
+	cacheChart := zarfTypes.InstalledChart{Namespace: "cache", ChartName: "redis"}
+
+	tests := []struct {
+		name string
+		// before is the cluster before the deploy, deploy changes it the way the failed deploy did
+		before   func() *fakeRollbackClient
+		deploy   func(f *fakeRollbackClient)
+		packages []string
+		calls    []string
+		err      string
+	}{
+		{
+			name: "upgraded releases are rolled back and untouched releases are left alone",
+			before: func() *fakeRollbackClient {
+				return &fakeRollbackClient{
+					deployed:  map[string]*zarfTypes.DeployedPackage{"database": deployedWithCharts("database", 1, dbChart, dbOperator)},
+					revisions: map[zarfTypes.InstalledChart]int{dbChart: 3, dbOperator: 1},
+				}
+			},
+			deploy: func(f *fakeRollbackClient) {
+				f.revisions[dbChart] = 4
+				f.deployed["database"] = deployedWithCharts("database", 2, dbChart, dbOperator)
+			},
+			packages: []string{"database"},
+			calls:    []string{"rollback postgres to 3", "record database@1"},
+		},
+		{
+			name: "releases added by the upgrade are uninstalled",
+			before: func() *fakeRollbackClient {
+				return &fakeRollbackClient{
+					deployed:  map[string]*zarfTypes.DeployedPackage{"database": deployedWithCharts("database", 1, dbChart)},
+					revisions: map[zarfTypes.InstalledChart]int{dbChart: 3},
+				}
+			},
+			deploy: func(f *fakeRollbackClient) {
+				f.revisions[dbOperator] = 1
+				f.deployed["database"] = deployedWithCharts("database", 2, dbChart, dbOperator)
+			},
+			packages: []string{"database"},
+			calls:    []string{"uninstall operator", "record database@1"},
+		},
+		{
+			name: "first installs are removed",
+			before: func() *fakeRollbackClient {
+				return &fakeRollbackClient{
+					deployed:  map[string]*zarfTypes.DeployedPackage{},
+					revisions: map[zarfTypes.InstalledChart]int{},
+				}
+			},
+			deploy: func(f *fakeRollbackClient) {
+				f.revisions[appChart] = 1
+				f.deployed["app"] = deployedWithCharts("app", 1, appChart)
+			},
+			packages: []string{"app"},
+			calls:    []string{"remove app"},
+		},
+		{
+			name: "first installs that Zarf never recorded aren't removed",
+			before: func() *fakeRollbackClient {
+				return &fakeRollbackClient{
+					deployed:  map[string]*zarfTypes.DeployedPackage{},
+					revisions: map[zarfTypes.InstalledChart]int{},
+				}
+			},
+			deploy:   func(_ *fakeRollbackClient) {},
+			packages: []string{"app"},
+		},
+		{
+			name: "packages are rolled back in the reverse order they were deployed",
+			before: func() *fakeRollbackClient {
+				return &fakeRollbackClient{
+					deployed: map[string]*zarfTypes.DeployedPackage{
+						"database": deployedWithCharts("database", 1, dbChart),
+						"cache":    deployedWithCharts("cache", 5, cacheChart),
+					},
+					revisions: map[zarfTypes.InstalledChart]int{dbChart: 3, cacheChart: 7},
+				}
+			},
+			deploy: func(f *fakeRollbackClient) {
+				f.revisions[dbChart] = 4
+				f.revisions[cacheChart] = 8
+				f.revisions[appChart] = 1
+				f.deployed["app"] = deployedWithCharts("app", 1, appChart)
+			},
+			packages: []string{"database", "cache", "app"},
+			calls:    []string{"remove app", "rollback redis to 7", "record cache@5", "rollback postgres to 3", "record database@1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.before()
+			tracker := &rollbackTracker{client: client}
+			for _, name := range tt.packages {
+				require.NoError(t, tracker.record(types.Package{Name: name}, time.Minute))
+			}
+			tt.deploy(client)
+
+			require.NoError(t, tracker.rollback("bundle.tar.zst"))
+			require.Equal(t, tt.calls, client.calls)
+		})
+	}
+}
+
+func TestRollbackTrackerLookupError(t *testing.T) {
+	// a failed lookup must not be mistaken for a package that was never deployed
+	client := &fakeRollbackClient{lookupErr: errors.New("connection refused")}
+	tracker := &rollbackTracker{client: client}
+	require.ErrorContains(t, tracker.record(types.Package{Name: "database"}, time.Minute), "connection refused")
+	require.Empty(t, tracker.points)
+}
+
+func TestRollbackTrackerUsesPackageTimeout(t *testing.T) {
+	dbChart := zarfTypes.InstalledChart{Namespace: "db", ChartName: "postgres"}
+	migrations := zarfTypes.InstalledChart{Namespace: "db", ChartName: "migrations"}
+	client := &fakeRollbackClient{
+		deployed:  map[string]*zarfTypes.DeployedPackage{"database": deployedWithCharts("database", 1, dbChart)},
+		revisions: map[zarfTypes.InstalledChart]int{dbChart: 3},
+	}
+	tracker := &rollbackTracker{client: client}
+	require.NoError(t, tracker.record(types.Package{Name: "database"}, 30*time.Minute))
+
+	client.revisions[dbChart] = 4
+	client.revisions[migrations] = 1
+	client.deployed["database"] = deployedWithCharts("database", 2, dbChart, migrations)
+
+	// releases are rolled back and uninstalled with the package's timeout rather than the default
+	require.NoError(t, tracker.rollback("bundle.tar.zst"))
+	require.Equal(t, map[string]time.Duration{"postgres": 30 * time.Minute, "migrations": 30 * time.Minute}, client.timeouts)
+}
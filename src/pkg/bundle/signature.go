@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/interactive"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/opencontainers/go-digest"
+)
+
+// verifyBundle verifies a bundle's signatures with the public keys, requiring valid signatures from at least threshold of them
+//
+// signatures of the root manifest cover every package manifest and layer in the bundle, so the blobs of local bundles are also checked
+// against their digests to catch tampered layers before anything is deployed. bundles where only the uds-bundle.yaml is signed are
+// verified as before with a warning, unless requireManifest is set or threshold is above 1, since a uds-bundle.yaml signature doesn't
+// cover the bundle's packages and is only ever made with a single key
+func verifyBundle(provider Provider, loaded types.PathMap, keyPaths []string, threshold int, requireManifest bool) error {
+	ctx := context.TODO()
+	referrers, storage, err := provider.getReferrers()
+	if err != nil {
+		return err
+	}
+	signatures, err := boci.ManifestSignatures(ctx, storage, referrers)
+	if err != nil {
+		return err
+	}
+	yamlSigned := loaded[config.BundleYAMLSignature] != "" || loaded[config.BundleYAMLOriginalSignature] != ""
+	signed := len(signatures) > 0 || yamlSigned
+
+	if len(keyPaths) == 0 {
+		if signed {
+			return fmt.Errorf("bundle is signed, but no public key was provided")
+		}
+		return nil
+	}
+	if !signed {
+		return fmt.Errorf("bundle is not signed, but a public key was provided")
+	}
+	if err := boci.ValidateKeys(keyPaths, threshold); err != nil {
+		return err
+	}
+
+	if len(signatures) == 0 {
+		if requireManifest || threshold > 1 {
+			return fmt.Errorf("the bundle's root manifest is not signed, only its %s is, which doesn't cover its packages or meet --require-manifest-signature or a --key-threshold above 1", config.BundleYAML)
+		}
+		message.Warnf("The bundle's root manifest is not signed, so its packages are NOT verified. Only its %s signature is "+
+			"checked, sign the bundle again to cover its packages, or pass --require-manifest-signature to refuse bundles like this", config.BundleYAML)
+		return validateSignature(loaded, keyPaths, threshold)
+	}
+
+	rootDesc, err := provider.getBundleRootDesc()
+	if err != nil {
+		return err
+	}
+	if err := boci.VerifyBundle(ctx, storage, signatures, rootDesc, keyPaths, threshold); err != nil {
+		return err
+	}
+	spinner := message.NewProgressSpinner("Verifying the bundle's packages")
+	defer spinner.Stop()
+	if err := provider.verifyBlobs(); err != nil {
+		return fmt.Errorf("the bundle's signature is valid, but its contents don't match it: %w", err)
+	}
+	spinner.Successf("Verified bundle %s", rootDesc.Digest)
+	return nil
+}
+
+// verifyBlob checks that the blob read from r matches the sha256 digest it is named after
+func verifyBlob(name string, r io.Reader) error {
+	expected := digest.NewDigestFromEncoded(digest.SHA256, name)
+	if err := expected.Validate(); err != nil {
+		return fmt.Errorf("unexpected blob %s: %w", name, err)
+	}
+	actual, err := digest.SHA256.FromReader(r)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return fmt.Errorf("blob %s does not match its digest, got %s", expected, actual)
+	}
+	return nil
+}
+
+// signingKeyPassword returns the password func for signing keys, prompting for the password if it isn't set
+func signingKeyPassword(password string) func(bool) ([]byte, error) {
+	return func(_ bool) ([]byte, error) {
+		if password != "" {
+			return []byte(password), nil
+		}
+		return interactive.PromptSigPassword()
+	}
+}
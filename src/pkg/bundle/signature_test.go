@@ -0,0 +1,31 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBundleRequiresManifestSignature(t *testing.T) {
+	provider, err := NewBundleProvider(writeTestLayout(t, []byte("kind: UDSBundle\nmetadata:\n  name: example\n")), t.TempDir())
+	require.NoError(t, err)
+	loaded, err := provider.LoadBundleMetadata()
+	require.NoError(t, err)
+
+	// only the uds-bundle.yaml is signed, there are no signatures of the root manifest
+	signature := filepath.Join(t.TempDir(), config.BundleYAMLSignature)
+	require.NoError(t, os.WriteFile(signature, []byte("signature"), 0o600))
+	loaded[config.BundleYAMLSignature] = signature
+	keys := []string{"awskms:///alias/a", "awskms:///alias/b"}
+
+	require.ErrorContains(t, verifyBundle(provider, loaded, keys[:1], 1, true), "the bundle's root manifest is not signed")
+	require.ErrorContains(t, verifyBundle(provider, loaded, keys, 2, false), "the bundle's root manifest is not signed")
+
+	// otherwise the uds-bundle.yaml signature is still checked
+	err = verifyBundle(provider, loaded, keys[:1], 1, false)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "the bundle's root manifest is not signed")
+}
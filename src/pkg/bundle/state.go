@@ -0,0 +1,502 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
+	"github.com/defenseunicorns/uds-cli/src/pkg/sources"
+	"github.com/defenseunicorns/uds-cli/src/pkg/state"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/cluster"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+)
+
+// stateRecorder records the progress of a bundle deploy in the cluster
+//
+// recording is best effort, a bundle deploy never fails because its state couldn't be saved
+type stateRecorder struct {
+	mu     sync.Mutex
+	client *state.Client
+	state  *types.BundleState
+	// previous is the bundle's state from before the deploy, nil if the bundle hasn't been deployed before
+	previous *types.BundleState
+	// actions are the results of the bundle and package lifecycle actions that ran during the deploy
+	actions actionLog
+	// exports are the variables exported by the packages deployed during the deploy, including sensitive ones that aren't recorded
+	exports map[string]map[string]string
+}
+
+// newStateRecorder creates the bundle's state record with the packages that are about to be deployed
+func (b *Bundle) newStateRecorder(packagesToDeploy []types.Package) *stateRecorder {
+	// without a cluster connection the state is still tracked in memory for the deploy summary
+	client, err := state.NewClient()
+	if err != nil {
+		message.Debugf("Unable to connect to the cluster, bundle state will not be recorded: %s", err.Error())
+	}
+
+	hostname, _ := os.Hostname()
+	bundleState := &types.BundleState{
+		Name:         b.bundle.Metadata.Name,
+		Version:      b.bundle.Metadata.Version,
+		Architecture: b.bundle.Metadata.Architecture,
+		Digest:       b.rootDigest,
+		Source:       b.cfg.DeployOpts.Source,
+		User:         os.Getenv("USER"),
+		Terminal:     hostname,
+		CLIVersion:   config.CLIVersion,
+		StartedAt:    time.Now(),
+		Status:       types.StatusDeploying,
+	}
+
+	// packages that aren't part of this deploy keep their previous record (ie. when using --packages or --resume)
+	var previous *types.BundleState
+	if client != nil {
+		previous = loadPreviousState(client, bundleState.Name)
+	}
+	packages, _ := sortPackages(b.bundle.Packages)
+	for _, pkg := range packages {
+		pkgState := types.PackageState{
+			Name:   pkg.Name,
+			Ref:    pkg.Ref,
+			Digest: packageDigest(pkg),
+			Status: types.StatusSkipped,
+		}
+		deploying := slices.ContainsFunc(packagesToDeploy, func(p types.Package) bool { return p.Name == pkg.Name })
+		if !deploying && previous != nil {
+			if i := slices.IndexFunc(previous.Packages, func(p types.PackageState) bool { return p.Name == pkg.Name }); i >= 0 {
+				pkgState = previous.Packages[i]
+			}
+		}
+		bundleState.Packages = append(bundleState.Packages, pkgState)
+	}
+
+	r := &stateRecorder{client: client, state: bundleState, previous: previous}
+	r.save()
+	return r
+}
+
+// loadPreviousState returns the bundle's currently recorded state, nil if the bundle hasn't been deployed before
+func loadPreviousState(client *state.Client, bundleName string) *types.BundleState {
+	previous, err := client.Get(context.TODO(), bundleName)
+	if err != nil {
+		if !errors.Is(err, state.ErrNotFound) {
+			message.Debugf("Unable to read the previous state of bundle %s: %s", bundleName, err.Error())
+		}
+		return nil
+	}
+	return previous
+}
+
+// unchanged returns the previously exported variables of a package if it was last deployed successfully with the same digest and config
+//
+// packages that export sensitive variables are never unchanged, since the values of those variables aren't recorded
+func (r *stateRecorder) unchanged(pkg types.Package, configHash string) (map[string]string, bool) {
+	prev, ok := r.previousPackage(pkg.Name)
+	if !ok || prev.Status != types.StatusSucceeded || prev.ConfigHash == "" || len(prev.SensitiveExports) > 0 {
+		return nil, false
+	}
+	if prev.Digest != packageDigest(pkg) || prev.ConfigHash != configHash {
+		return nil, false
+	}
+	return prev.Exports, true
+}
+
+func (r *stateRecorder) previousPackage(name string) (types.PackageState, bool) {
+	if r.previous == nil {
+		return types.PackageState{}, false
+	}
+	i := slices.IndexFunc(r.previous.Packages, func(p types.PackageState) bool { return p.Name == name })
+	if i < 0 {
+		return types.PackageState{}, false
+	}
+	return r.previous.Packages[i], true
+}
+
+// packageStarted marks a package as deploying
+func (r *stateRecorder) packageStarted(name string) {
+	events.PackageStarted(name)
+	r.updatePackage(name, func(p *types.PackageState) {
+		p.StartedAt = time.Now()
+		p.FinishedAt = time.Time{}
+		p.Status = types.StatusDeploying
+		p.Error = ""
+	})
+}
+
+// packageSkipped keeps the previous record of a package that was skipped because it hasn't changed
+func (r *stateRecorder) packageSkipped(name string) {
+	prev, ok := r.previousPackage(name)
+	if !ok {
+		return
+	}
+	r.updatePackage(name, func(p *types.PackageState) {
+		*p = prev
+	})
+	events.PackageFinished(name, types.StatusUnchanged, 0, nil)
+}
+
+// packageSucceeded marks a package as deployed along with its Zarf package version, the config it was deployed with and the variables it exported
+//
+// only the names of the exported variables in sensitive are recorded
+func (r *stateRecorder) packageSucceeded(name, version, configHash string, exports map[string]string, sensitive []string) {
+	recorded := maps.Clone(exports)
+	maps.DeleteFunc(recorded, func(name string, _ string) bool { return slices.Contains(sensitive, name) })
+	r.updatePackage(name, func(p *types.PackageState) {
+		p.FinishedAt = time.Now()
+		p.Status = types.StatusSucceeded
+		p.Version = version
+		p.ConfigHash = configHash
+		p.Exports = recorded
+		p.SensitiveExports = sensitive
+		if r.exports == nil {
+			r.exports = make(map[string]map[string]string)
+		}
+		r.exports[name] = exports
+		events.PackageFinished(name, p.Status, p.FinishedAt.Sub(p.StartedAt), nil)
+	})
+}
+
+// deployed returns whether a package was deployed during the deploy, rather than skipped or not deployed yet
+func (r *stateRecorder) deployed(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.exports[name]
+	return ok
+}
+
+// packageFailed marks a package as failed
+func (r *stateRecorder) packageFailed(name string, err error) {
+	r.updatePackage(name, func(p *types.PackageState) {
+		p.FinishedAt = time.Now()
+		p.Status = types.StatusFailed
+		p.Error = err.Error()
+		var duration time.Duration
+		if !p.StartedAt.IsZero() {
+			duration = p.FinishedAt.Sub(p.StartedAt)
+		}
+		events.PackageFinished(name, p.Status, duration, err)
+	})
+}
+
+// finish records the outcome of the bundle deploy
+func (r *stateRecorder) finish(err error) {
+	r.mu.Lock()
+	r.state.FinishedAt = time.Now()
+	r.state.Status = types.StatusSucceeded
+	if err != nil {
+		r.state.Status = types.StatusFailed
+		r.state.Error = err.Error()
+	}
+	r.mu.Unlock()
+	r.save()
+}
+
+func (r *stateRecorder) updatePackage(name string, update func(p *types.PackageState)) {
+	r.mu.Lock()
+	for i := range r.state.Packages {
+		if r.state.Packages[i].Name == name {
+			update(&r.state.Packages[i])
+		}
+	}
+	r.mu.Unlock()
+	r.save()
+}
+
+func (r *stateRecorder) save() {
+	if r.client == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.client.Save(context.TODO(), r.state); err != nil {
+		message.Warnf("Unable to record the state of bundle %s: %s", r.state.Name, err.Error())
+	}
+}
+
+// exportedVars returns the variables exported by each package, including packages skipped or not deployed this time
+func (r *stateRecorder) exportedVars() map[string]map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exported := make(map[string]map[string]string)
+	for _, pkg := range r.state.Packages {
+		if pkg.Exports != nil {
+			exported[pkg.Name] = pkg.Exports
+		}
+	}
+	maps.Copy(exported, r.exports)
+	return exported
+}
+
+// printSummary prints the result of each package and the lifecycle actions that ran during the deploy
+func (r *stateRecorder) printSummary() {
+	r.mu.Lock()
+	var rows [][]string
+	for _, pkg := range r.state.Packages {
+		duration := ""
+		if d, ok := r.packageDuration(pkg); ok {
+			duration = d.Round(time.Second).String()
+		}
+		rows = append(rows, []string{pkg.Name, pkg.Ref, string(r.packageResult(pkg)), duration})
+	}
+	r.mu.Unlock()
+
+	message.HeaderInfof("📋 DEPLOY SUMMARY")
+	message.Table([]string{"Package", "Ref", "Result", "Duration"}, rows)
+	r.actions.print()
+}
+
+// packageResult returns the outcome of a package in this deploy
+func (r *stateRecorder) packageResult(pkg types.PackageState) types.BundleStatus {
+	// packages that were skipped because they're unchanged keep their record from an earlier deploy
+	if pkg.Status == types.StatusSucceeded && pkg.FinishedAt.Before(r.state.StartedAt) {
+		return types.StatusUnchanged
+	}
+	return pkg.Status
+}
+
+// packageDuration returns how long a package took to deploy, if it was deployed during this deploy
+func (r *stateRecorder) packageDuration(pkg types.PackageState) (time.Duration, bool) {
+	if pkg.StartedAt.IsZero() || pkg.FinishedAt.IsZero() || pkg.StartedAt.Before(r.state.StartedAt) {
+		return 0, false
+	}
+	return pkg.FinishedAt.Sub(pkg.StartedAt), true
+}
+
+// removeState removes the records of the removed packages from the bundle's state, deleting the state if no packages remain
+func removeState(bundleName string, removed []types.Package) {
+	client, err := state.NewClient()
+	if err != nil {
+		message.Debugf("Unable to connect to the cluster, bundle state will not be updated: %s", err.Error())
+		return
+	}
+	ctx := context.TODO()
+	bundleState, err := client.Get(ctx, bundleName)
+	if err != nil {
+		message.Debugf("Unable to read the state of bundle %s: %s", bundleName, err.Error())
+		return
+	}
+
+	bundleState.Packages = slices.DeleteFunc(bundleState.Packages, func(p types.PackageState) bool {
+		return slices.ContainsFunc(removed, func(pkg types.Package) bool { return pkg.Name == p.Name })
+	})
+	if len(bundleState.Packages) == 0 {
+		err = client.Delete(ctx, bundleName)
+	} else {
+		err = client.Save(ctx, bundleState)
+	}
+	if err != nil {
+		message.Warnf("Unable to update the state of bundle %s: %s", bundleName, err.Error())
+	}
+}
+
+// packageConfigHash hashes the resolved variables, chart overrides and optional components a package is deployed with
+//
+// the hash is an HMAC with the bundle's config key, since it's recorded in the bundle's state and in deploy plans and
+// the variables can be sensitive
+func packageConfigHash(key []byte, pkg types.Package, pkgVars map[string]string, valuesOverrides PkgOverrideMap, nsOverrides sources.NamespaceOverrideMap) (string, error) {
+	// maps are marshalled with sorted keys so the hash is stable
+	b, err := json.Marshal(struct {
+		Variables          map[string]string
+		ValuesOverrides    PkgOverrideMap
+		NamespaceOverrides sources.NamespaceOverrideMap
+		OptionalComponents []string
+	}{pkgVars, valuesOverrides, nsOverrides, pkg.OptionalComponents})
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return fmt.Sprintf("%x", mac.Sum(nil)), nil
+}
+
+// loadConfigKey reads the key the bundle's package configs are hashed with from the bundle's state secret. If the bundle
+// doesn't have one yet, the key its deploy plan was made with (already in b.configKey) or a new random key is saved
+//
+// without a cluster connection the configs are hashed with a new random key, so no package is skipped as unchanged
+func (b *Bundle) loadConfigKey() error {
+	newKey := b.configKey
+	if newKey == nil {
+		key, err := state.NewConfigKey()
+		if err != nil {
+			return err
+		}
+		newKey = key
+	}
+	b.configKey = newKey
+	client, err := state.NewClient()
+	if err != nil {
+		return nil
+	}
+	key, err := client.ConfigKey(context.TODO(), b.bundle.Metadata.Name, newKey)
+	if err != nil {
+		message.Debugf("Unable to read the config key of bundle %s, no packages will be skipped: %s", b.bundle.Metadata.Name, err.Error())
+		return nil
+	}
+	b.configKey = key
+	return nil
+}
+
+// findConfigKey reads the key the bundle's package configs are hashed with without writing to the cluster, returning
+// false if the bundle doesn't have one yet
+//
+// in that case the configs are hashed with a new random key, so no package can be predicted to be skipped as unchanged
+func (b *Bundle) findConfigKey() (bool, error) {
+	if b.configKey != nil {
+		return true, nil
+	}
+	if client, err := state.NewClient(); err == nil {
+		key, err := client.FindConfigKey(context.TODO(), b.bundle.Metadata.Name)
+		if err != nil {
+			message.Debugf("Unable to read the config key of bundle %s: %s", b.bundle.Metadata.Name, err.Error())
+		}
+		if key != nil {
+			b.configKey = key
+			return true, nil
+		}
+	}
+	key, err := state.NewConfigKey()
+	if err != nil {
+		return false, err
+	}
+	b.configKey = key
+	return false, nil
+}
+
+// isPackageHealthy checks that a package is still deployed to the cluster and none of its components are failed or in progress
+func isPackageHealthy(name string) bool {
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return false
+	}
+	deployed, err := c.GetDeployedPackage(context.TODO(), name)
+	if err != nil {
+		return false
+	}
+	return packageHealth(deployed) == "Healthy"
+}
+
+// packageDigest returns the digest of a bundled Zarf package from its ref
+func packageDigest(pkg types.Package) string {
+	if _, sha, ok := strings.Cut(pkg.Ref, "@sha256:"); ok {
+		return "sha256:" + sha
+	}
+	return ""
+}
+
+// List prints the bundles that have been deployed to the cluster
+func List() error {
+	client, err := state.NewClient()
+	if err != nil {
+		return err
+	}
+	states, err := client.List(context.TODO())
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		message.Infof("No bundles have been deployed to the cluster")
+		return nil
+	}
+
+	var rows [][]string
+	for _, s := range states {
+		rows = append(rows, []string{
+			s.Name,
+			s.Version,
+			string(s.Status),
+			fmt.Sprintf("%d", len(s.Packages)),
+			s.StartedAt.Format(time.RFC1123),
+			fmt.Sprintf("%s@%s", s.User, s.Terminal),
+		})
+	}
+	message.Table([]string{"Bundle", "Version", "Status", "Packages", "Deployed", "By"}, rows)
+	return nil
+}
+
+// Status prints the health of each package in a deployed bundle and compares its version to what the bundle expects
+func Status(bundleName string) error {
+	client, err := state.NewClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	bundleState, err := client.Get(ctx, bundleName)
+	if err != nil {
+		return err
+	}
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return err
+	}
+
+	message.HeaderInfof("📦 BUNDLE %s", bundleState.Name)
+	message.Table([]string{"Field", "Value"}, [][]string{
+		{"Version", bundleState.Version},
+		{"Digest", bundleState.Digest},
+		{"Source", bundleState.Source},
+		{"Status", string(bundleState.Status)},
+		{"Deployed", bundleState.StartedAt.Format(time.RFC1123)},
+		{"By", fmt.Sprintf("%s@%s (uds %s)", bundleState.User, bundleState.Terminal, bundleState.CLIVersion)},
+	})
+
+	var rows [][]string
+	for _, pkgState := range bundleState.Packages {
+		var deployed *zarfTypes.DeployedPackage
+		if d, err := c.GetDeployedPackage(ctx, pkgState.Name); err == nil {
+			deployed = d
+		}
+		rows = append(rows, packageStatus(pkgState, deployed))
+	}
+	message.HeaderInfof("📋 PACKAGES")
+	message.Table([]string{"Package", "Expected", "Deployed", "Last Deploy", "Health"}, rows)
+	return nil
+}
+
+// packageStatus returns a package's row in the bundle status table, deployed is nil if the package isn't deployed
+//
+// the version Zarf recorded is compared with the Zarf package version recorded in the bundle's state rather than the
+// package's tag, which has a flavor suffix for flavored packages. states recorded before the version was tracked show the tag
+func packageStatus(pkgState types.PackageState, deployed *zarfTypes.DeployedPackage) []string {
+	expected := pkgState.Version
+	if expected == "" {
+		expected, _, _ = strings.Cut(pkgState.Ref, "@")
+	}
+	deployedVersion, health := "-", "Not Deployed"
+	if deployed != nil {
+		deployedVersion = deployed.Data.Metadata.Version
+		health = packageHealth(deployed)
+		if pkgState.Version != "" && deployedVersion != pkgState.Version {
+			health = fmt.Sprintf("%s (version mismatch)", health)
+		}
+	}
+	return []string{pkgState.Name, expected, deployedVersion, string(pkgState.Status), health}
+}
+
+// packageHealth summarizes the status of a deployed package's components
+func packageHealth(deployed *zarfTypes.DeployedPackage) string {
+	for _, status := range []zarfTypes.ComponentStatus{zarfTypes.ComponentStatusFailed, zarfTypes.ComponentStatusDeploying, zarfTypes.ComponentStatusRemoving} {
+		for _, component := range deployed.DeployedComponents {
+			if component.Status == status {
+				return fmt.Sprintf("%s (%s)", status, component.Name)
+			}
+		}
+	}
+	return "Healthy"
+}
@@ -0,0 +1,135 @@
+package bundle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageConfigHash(t *testing.T) {
+	pkg := types.Package{Name: "foo", OptionalComponents: []string{"bar"}}
+	overrides := PkgOverrideMap{"component": {"chart": {"replicas": 2}}}
+
+	key := []byte("key")
+	hash, err := packageConfigHash(key, pkg, map[string]string{"A": "1", "B": "2"}, overrides, nil)
+	require.NoError(t, err)
+	same, err := packageConfigHash(key, pkg, map[string]string{"B": "2", "A": "1"}, overrides, nil)
+	require.NoError(t, err)
+	require.Equal(t, hash, same)
+
+	changedVar, err := packageConfigHash(key, pkg, map[string]string{"A": "1", "B": "3"}, overrides, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changedVar)
+
+	changedOverride, err := packageConfigHash(key, pkg, map[string]string{"A": "1", "B": "2"}, PkgOverrideMap{"component": {"chart": {"replicas": 3}}}, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changedOverride)
+
+	otherKey, err := packageConfigHash([]byte("other-key"), pkg, map[string]string{"A": "1", "B": "2"}, overrides, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherKey)
+}
+
+func TestStateRecorderUnchanged(t *testing.T) {
+	pkg := types.Package{Name: "foo", Ref: "0.0.1@sha256:abc"}
+	recorder := &stateRecorder{previous: &types.BundleState{Packages: []types.PackageState{
+		{Name: "foo", Digest: "sha256:abc", ConfigHash: "hash", Status: types.StatusSucceeded, Exports: map[string]string{"OUTPUT": "value"}},
+		{Name: "failed", Digest: "sha256:abc", ConfigHash: "hash", Status: types.StatusFailed},
+	}}}
+
+	exports, ok := recorder.unchanged(pkg, "hash")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"OUTPUT": "value"}, exports)
+
+	_, ok = recorder.unchanged(pkg, "other-hash")
+	require.False(t, ok)
+
+	_, ok = recorder.unchanged(types.Package{Name: "foo", Ref: "0.0.2@sha256:def"}, "hash")
+	require.False(t, ok)
+
+	_, ok = recorder.unchanged(types.Package{Name: "failed", Ref: "0.0.1@sha256:abc"}, "hash")
+	require.False(t, ok)
+
+	_, ok = (&stateRecorder{}).unchanged(pkg, "hash")
+	require.False(t, ok)
+}
+
+func TestPackageStatus(t *testing.T) {
+	deployed := func(version string, statuses ...zarfTypes.ComponentStatus) *zarfTypes.DeployedPackage {
+		d := &zarfTypes.DeployedPackage{Name: "nginx"}
+		d.Data.Metadata.Version = version
+		for i, status := range statuses {
+			d.DeployedComponents = append(d.DeployedComponents, zarfTypes.DeployedComponent{Name: fmt.Sprintf("component-%d", i), Status: status})
+		}
+		return d
+	}
+	tests := []struct {
+		name     string
+		pkgState types.PackageState
+		deployed *zarfTypes.DeployedPackage
+		expected []string
+	}{
+		{
+			name:     "healthy",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.1@sha256:abc", Version: "0.0.1", Status: types.StatusSucceeded},
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded),
+			expected: []string{"nginx", "0.0.1", "0.0.1", "Succeeded", "Healthy"},
+		},
+		{
+			name:     "flavored package",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.1-upstream@sha256:abc", Version: "0.0.1", Status: types.StatusSucceeded},
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded),
+			expected: []string{"nginx", "0.0.1", "0.0.1", "Succeeded", "Healthy"},
+		},
+		{
+			name:     "version mismatch",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.2-upstream@sha256:abc", Version: "0.0.2", Status: types.StatusSucceeded},
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded),
+			expected: []string{"nginx", "0.0.2", "0.0.1", "Succeeded", "Healthy (version mismatch)"},
+		},
+		{
+			name:     "state without a version",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.1-upstream@sha256:abc", Status: types.StatusSucceeded},
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded),
+			expected: []string{"nginx", "0.0.1-upstream", "0.0.1", "Succeeded", "Healthy"},
+		},
+		{
+			name:     "failed component",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.1@sha256:abc", Version: "0.0.1", Status: types.StatusFailed},
+			deployed: deployed("0.0.1", zarfTypes.ComponentStatusSucceeded, zarfTypes.ComponentStatusFailed),
+			expected: []string{"nginx", "0.0.1", "0.0.1", "Failed", "Failed (component-1)"},
+		},
+		{
+			name:     "not deployed",
+			pkgState: types.PackageState{Name: "nginx", Ref: "0.0.1@sha256:abc", Version: "0.0.1", Status: types.StatusSucceeded},
+			expected: []string{"nginx", "0.0.1", "-", "Succeeded", "Not Deployed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, packageStatus(tt.pkgState, tt.deployed))
+		})
+	}
+}
+
+func TestStateRecorderSensitiveExports(t *testing.T) {
+	recorder := &stateRecorder{state: &types.BundleState{Packages: []types.PackageState{{Name: "database"}}}}
+	exports := map[string]string{"DB_HOST": "postgres", "DB_PASSWORD": "secret"}
+	recorder.packageSucceeded("database", "0.0.1", "hash", exports, []string{"DB_PASSWORD"})
+
+	// sensitive values are only kept in memory for the rest of the deploy
+	recorded := recorder.state.Packages[0]
+	require.Equal(t, map[string]string{"DB_HOST": "postgres"}, recorded.Exports)
+	require.Equal(t, []string{"DB_PASSWORD"}, recorded.SensitiveExports)
+	require.Equal(t, map[string]map[string]string{"database": exports}, recorder.exportedVars())
+
+	// the package is redeployed next time so its sensitive exports are set again
+	next := &stateRecorder{previous: recorder.state}
+	recorded.Status, recorded.Digest = types.StatusSucceeded, "sha256:abc"
+	next.previous.Packages[0] = recorded
+	_, ok := next.unchanged(types.Package{Name: "database", Ref: "0.0.1@sha256:abc"}, "hash")
+	require.False(t, ok)
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/pterm/pterm"
+)
+
+func init() {
+	// chart overrides are merged by Helm into these types, gob needs them registered to send them to a child process
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// childDeploy is the request a parent deploy sends to a child process on stdin
+//
+// it's encoded with gob rather than JSON so chart override values keep their types (ie. ints don't become floats)
+type childDeploy struct {
+	Deploy packageDeploy
+	// ResultPath is the file the child writes its childResult to, stdout and stderr are the child's output
+	ResultPath string
+}
+
+// childResult is the outcome of a package deploy in a child process
+type childResult struct {
+	Result  packageDeployResult
+	Actions []childAction
+	// Error is the error the deploy failed with, if any
+	Error string
+}
+
+// childAction is an actionResult that can be sent from a child process
+type childAction struct {
+	Scope       string
+	Hook        string
+	Description string
+	Duration    time.Duration
+	Error       string
+}
+
+// deployInChildProcess deploys a package with `uds internal deploy-package` and adds the actions it ran to log
+//
+// Zarf keeps the state of a deploy (its common options, spinners and progress bars) in globals, so packages deployed
+// concurrently can't share a process. The child's output is written line by line, prefixed with the package's name
+func deployInChildProcess(d packageDeploy, log *actionLog) (packageDeployResult, error) {
+	executable, err := utils.GetFinalExecutablePath()
+	if err != nil {
+		return packageDeployResult{}, err
+	}
+	tmp, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return packageDeployResult{}, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// the package's overrides are already resolved into d.ValuesOverrides and d.NamespaceOverrides
+	d.Package.Overrides = nil
+
+	var request bytes.Buffer
+	resultPath := filepath.Join(tmp, "result")
+	if err := gob.NewEncoder(&request).Encode(childDeploy{Deploy: d, ResultPath: resultPath}); err != nil {
+		return packageDeployResult{}, err
+	}
+
+	stdout, stderr := newPrefixWriter(d.Package.Name), newPrefixWriter(d.Package.Name)
+	cmd := exec.Command(executable, childArgs()...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = &request, stdout, stderr
+	runErr := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+
+	f, err := os.Open(resultPath)
+	if err != nil {
+		if runErr != nil {
+			return packageDeployResult{}, fmt.Errorf("unable to deploy package %s: %w", d.Package.Name, runErr)
+		}
+		return packageDeployResult{}, err
+	}
+	defer f.Close()
+	var result childResult
+	if err := gob.NewDecoder(f).Decode(&result); err != nil {
+		return packageDeployResult{}, fmt.Errorf("unable to read the result of deploying package %s: %w", d.Package.Name, err)
+	}
+
+	for _, a := range result.Actions {
+		var actionErr error
+		if a.Error != "" {
+			actionErr = errors.New(a.Error)
+		}
+		log.add(actionResult{scope: a.Scope, hook: a.Hook, description: a.Description, duration: a.Duration, err: actionErr})
+	}
+	if result.Error != "" {
+		return result.Result, errors.New(result.Error)
+	}
+	if runErr != nil {
+		return result.Result, fmt.Errorf("unable to deploy package %s: %w", d.Package.Name, runErr)
+	}
+	return result.Result, nil
+}
+
+// childArgs returns the arguments for `uds internal deploy-package`, passing on the global flags that affect a deploy
+//
+// the child always runs without progress bars and spinners since its output isn't a terminal, and writes to the
+// parent's log file through the parent's output rather than to a log file of its own
+func childArgs() []string {
+	logLevels := map[message.LogLevel]string{
+		message.WarnLevel:  "warn",
+		message.InfoLevel:  "info",
+		message.DebugLevel: "debug",
+		message.TraceLevel: "trace",
+	}
+	args := []string{
+		"internal", "deploy-package",
+		"--no-progress",
+		"--no-log-file",
+		"--log-level", logLevels[message.GetLogLevel()],
+		"--architecture", config.GetArch(),
+		"--uds-cache", config.CommonOptions.CachePath,
+		"--tmpdir", config.CommonOptions.TempDirectory,
+		"--oci-concurrency", strconv.Itoa(config.CommonOptions.OCIConcurrency),
+	}
+	if config.CommonOptions.Insecure {
+		args = append(args, "--insecure")
+	}
+	return args
+}
+
+// DeployChildPackage deploys the package read from in, for a parent `uds deploy` that is deploying packages concurrently
+//
+// the result, including the error the deploy failed with, is written to the file the parent named in the request
+func DeployChildPackage(in io.Reader) error {
+	var request childDeploy
+	if err := gob.NewDecoder(in).Decode(&request); err != nil {
+		return fmt.Errorf("unable to read the package to deploy: %w", err)
+	}
+
+	var log actionLog
+	result, deployErr := deployZarfPackage(request.Deploy, &log, func() {})
+
+	child := childResult{Result: result}
+	for _, a := range log.results {
+		action := childAction{Scope: a.scope, Hook: a.hook, Description: a.description, Duration: a.duration}
+		if a.err != nil {
+			action.Error = a.err.Error()
+		}
+		child.Actions = append(child.Actions, action)
+	}
+	if deployErr != nil {
+		child.Error = deployErr.Error()
+	}
+
+	var out bytes.Buffer
+	if err := gob.NewEncoder(&out).Encode(child); err != nil {
+		return err
+	}
+	if err := os.WriteFile(request.ResultPath, out.Bytes(), helpers.ReadWriteUser); err != nil {
+		return err
+	}
+	return deployErr
+}
+
+// outputMu keeps lines from packages deploying concurrently from interleaving
+var outputMu sync.Mutex
+
+// prefixWriter writes each line written to it to UDS's output, prefixed with the name of the package it came from
+type prefixWriter struct {
+	prefix string
+	buf    []byte
+	// print writes a line, it's pterm.Print unless overridden in tests
+	print func(a ...interface{})
+}
+
+func newPrefixWriter(pkgName string) *prefixWriter {
+	return &prefixWriter{prefix: fmt.Sprintf("[%s] ", pkgName), print: pterm.Print}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any output that isn't followed by a newline
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	w.print(w.prefix + string(bytes.TrimSuffix(line, []byte("\r"))) + "\n")
+}
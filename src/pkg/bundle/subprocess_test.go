@@ -0,0 +1,59 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChildDeployEncoding(t *testing.T) {
+	d := packageDeploy{
+		Source:    "uds-bundle-test-amd64-0.0.1.tar.zst",
+		Package:   types.Package{Name: "podinfo", Ref: "0.0.1@sha256:abc", DependsOn: []string{"init"}},
+		Variables: map[string]string{"COLOR": "orange"},
+		ValuesOverrides: PkgOverrideMap{
+			"podinfo-component": {
+				"podinfo": {
+					"replicaCount": int64(1000000),
+					"ratio":        0.5,
+					"ui":           map[string]interface{}{"color": "orange", "logo": nil},
+					"tolerations":  []interface{}{map[string]interface{}{"key": "unicorn"}},
+				},
+			},
+		},
+		NamespaceOverrides: map[string]map[string]string{"podinfo-component": {"podinfo": "podinfo-ns"}},
+		Timeout:            40 * time.Minute,
+		Retries:            3,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(childDeploy{Deploy: d, ResultPath: "result"}))
+	var decoded childDeploy
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+
+	// chart values keep their types, so Helm renders them the same way as an in-process deploy
+	require.Equal(t, d, decoded.Deploy)
+	require.IsType(t, int64(0), decoded.Deploy.ValuesOverrides["podinfo-component"]["podinfo"]["replicaCount"])
+	require.Equal(t, "result", decoded.ResultPath)
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out strings.Builder
+	w := newPrefixWriter("podinfo")
+	w.print = func(a ...interface{}) { out.WriteString(fmt.Sprint(a...)) }
+
+	_, err := w.Write([]byte("Deploying component\r\nWaiting for"))
+	require.NoError(t, err)
+	require.Equal(t, "[podinfo] Deploying component\n", out.String())
+
+	_, err = w.Write([]byte(" the chart\nDone"))
+	require.NoError(t, err)
+	w.Flush()
+	require.Equal(t, "[podinfo] Deploying component\n[podinfo] Waiting for the chart\n[podinfo] Done\n", out.String())
+}
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package bundle contains functions for interacting with, managing and deploying UDS packages
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	goyaml "github.com/goccy/go-yaml"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	ocistore "oras.land/oras-go/v2/content/oci"
+)
+
+// splitPackageNames splits the values of a --packages flag, which can be repeated or comma separated, into package names
+func splitPackageNames(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(strings.ReplaceAll(value, " ", ""), ",") {
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// selectBundlePackages removes every package that isn't in names from the bundle, keeping the bundle's package order
+//
+// dependsOn entries that point at removed packages are dropped so the bundle stays valid, packages that import variables
+// from a removed package are kept as is since their imports can still be satisfied by variables set at deploy time
+func selectBundlePackages(bundle *types.UDSBundle, names []string) error {
+	var selected []types.Package
+	for _, pkg := range bundle.Packages {
+		if slices.Contains(names, pkg.Name) {
+			selected = append(selected, pkg)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(selected, func(pkg types.Package) bool { return pkg.Name == name }) {
+			return fmt.Errorf("package %s is not in bundle %s", name, bundle.Metadata.Name)
+		}
+	}
+
+	for i, pkg := range selected {
+		var dependsOn []string
+		for _, dep := range pkg.DependsOn {
+			if slices.Contains(names, dep) {
+				dependsOn = append(dependsOn, dep)
+			}
+		}
+		selected[i].DependsOn = dependsOn
+		for _, imp := range pkg.Imports {
+			if !slices.Contains(names, imp.Package) {
+				message.Warnf("Package %s imports %s from %s, which isn't being pulled, so it must be set at deploy time", pkg.Name, imp.Name, imp.Package)
+			}
+		}
+	}
+	bundle.Packages = selected
+	return nil
+}
+
+// validateSignature validates the signature of a bundle's uds-bundle.yaml, requiring it to be valid for at least threshold of the keys
+//
+// bundles pulled with --packages and --keep-signature have an unsigned uds-bundle.yaml alongside the original signed one, so the
+// original is verified instead and the bundle's uds-bundle.yaml must match the original with only the pulled packages selected
+func validateSignature(loaded types.PathMap, keyPaths []string, threshold int) error {
+	bundleYAMLPath, signaturePath := loaded[config.BundleYAML], loaded[config.BundleYAMLSignature]
+	if loaded[config.BundleYAMLOriginal] != "" {
+		bundleYAMLPath, signaturePath = loaded[config.BundleYAMLOriginal], loaded[config.BundleYAMLOriginalSignature]
+	}
+	verified := 0
+	var err error
+	for _, keyPath := range keyPaths {
+		if keyErr := ValidateBundleSignature(bundleYAMLPath, signaturePath, keyPath); keyErr != nil {
+			err = keyErr
+			continue
+		}
+		verified++
+	}
+	if verified < threshold {
+		return fmt.Errorf("the bundle's signature is valid for %d of the %d keys, %d are required: %w", verified, len(keyPaths), threshold, err)
+	}
+	if loaded[config.BundleYAMLOriginal] == "" {
+		return nil
+	}
+	return validateSubset(loaded[config.BundleYAML], loaded[config.BundleYAMLOriginal])
+}
+
+// validateSubset ensures the bundle at bundlePath is the bundle at originalPath with only some of its packages
+func validateSubset(bundlePath, originalPath string) error {
+	var bundle, expected types.UDSBundle
+	if err := utils.ReadYAMLStrict(bundlePath, &bundle); err != nil {
+		return err
+	}
+	if err := utils.ReadYAMLStrict(originalPath, &expected); err != nil {
+		return err
+	}
+	var names []string
+	for _, pkg := range bundle.Packages {
+		names = append(names, pkg.Name)
+	}
+	if err := selectBundlePackages(&expected, names); err != nil {
+		return fmt.Errorf("%s does not match the signed %s: %w", config.BundleYAML, config.BundleYAMLOriginal, err)
+	}
+	if !reflect.DeepEqual(bundle, expected) {
+		return fmt.Errorf("%s does not match the signed %s", config.BundleYAML, config.BundleYAMLOriginal)
+	}
+	return nil
+}
+
+// checkSubsetSignature ensures the signature of a signed bundle pulled with --packages is handled explicitly, since the
+// original signature doesn't cover the rewritten uds-bundle.yaml and root manifest
+func checkSubsetSignature(rootManifest *oci.Manifest, manifestSigned bool, opts types.BundlePullOptions) error {
+	yamlSigned := !oci.IsEmptyDescriptor(rootManifest.Locate(config.BundleYAMLSignature)) ||
+		!oci.IsEmptyDescriptor(rootManifest.Locate(config.BundleYAMLOriginalSignature))
+	signing := len(opts.SigningKeyPaths) > 0
+	if (yamlSigned || manifestSigned) && !signing && !opts.KeepSignature {
+		return fmt.Errorf("the bundle is signed, use --signing-key to sign the pulled packages or --keep-signature to keep the original signature")
+	}
+	if signing && opts.KeepSignature {
+		return fmt.Errorf("--signing-key and --keep-signature cannot be used together")
+	}
+	if opts.KeepSignature && !yamlSigned && manifestSigned {
+		return fmt.Errorf("--keep-signature can only keep signatures of the bundle's %s, the signatures of this bundle cover its root manifest so use --signing-key to sign the pulled packages", config.BundleYAML)
+	}
+	return nil
+}
+
+// writeSubsetRoot writes a new uds-bundle.yaml and root manifest for a bundle pulled with --packages and updates loaded to
+// reference them instead of the original bundle's, returning the new root manifest's descriptor and the descriptors of its signatures
+func (b *Bundle) writeSubsetRoot(ctx context.Context, rootManifest ocispec.Manifest, rootDesc ocispec.Descriptor, loaded types.PathMap) (ocispec.Descriptor, []ocispec.Descriptor, error) {
+	blobsDir := filepath.Join(b.tmp, config.BlobsDir)
+	if err := helpers.CreateDirectory(blobsDir, 0o700); err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	writeBlob := func(mediaType string, data []byte, title string) (ocispec.Descriptor, error) {
+		desc := content.NewDescriptorFromBytes(mediaType, data)
+		if title != "" {
+			desc.Annotations = map[string]string{ocispec.AnnotationTitle: title}
+		}
+		path := filepath.Join(blobsDir, desc.Digest.Encoded())
+		if err := os.WriteFile(path, data, helpers.ReadWriteUser); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		loaded[desc.Digest.Encoded()] = path
+		return desc, nil
+	}
+	copyBlob := func(path, title string) (ocispec.Descriptor, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		return writeBlob(zoci.ZarfLayerMediaTypeBlob, data, title)
+	}
+
+	// keep the manifests of the pulled pkgs, dropping the metadata of the original bundle
+	var pkgShas []string
+	for _, pkg := range b.bundle.Packages {
+		pkgShas = append(pkgShas, strings.Split(pkg.Ref, "@sha256:")[1])
+	}
+	var layers []ocispec.Descriptor
+	for _, layer := range rootManifest.Layers {
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; !ok && slices.Contains(pkgShas, layer.Digest.Encoded()) {
+			layers = append(layers, layer)
+		}
+	}
+
+	bundleYAML, err := goyaml.Marshal(b.bundle)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	bundleYAMLDesc, err := writeBlob(zoci.ZarfLayerMediaTypeBlob, bundleYAML, config.BundleYAML)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	layers = append(layers, bundleYAMLDesc)
+
+	// the original uds-bundle.yaml (or the one kept when the bundle was first pulled with --packages) and its signature
+	originalPath, originalSigPath := loaded[config.BundleYAML], loaded[config.BundleYAMLSignature]
+	if loaded[config.BundleYAMLOriginal] != "" {
+		originalPath, originalSigPath = loaded[config.BundleYAMLOriginal], loaded[config.BundleYAMLOriginalSignature]
+	}
+	for _, path := range config.BundleAlwaysPull {
+		delete(loaded, path)
+	}
+	delete(loaded, rootDesc.Digest.Encoded())
+
+	// the original signature of the uds-bundle.yaml is kept with --keep-signature, otherwise the new root manifest is signed below
+	opts := b.cfg.PullOpts
+	if len(opts.SigningKeyPaths) == 0 && originalSigPath != "" {
+		message.Note(fmt.Sprintf("The pulled bundle's %s is unsigned, the original signed %s is kept as %s and is verified instead when using --key",
+			config.BundleYAML, config.BundleYAML, config.BundleYAMLOriginal))
+		originalDesc, err := copyBlob(originalPath, config.BundleYAMLOriginal)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		originalSigDesc, err := copyBlob(originalSigPath, config.BundleYAMLOriginalSignature)
+		if err != nil {
+			return ocispec.Descriptor{}, nil, err
+		}
+		layers = append(layers, originalDesc, originalSigDesc)
+	}
+
+	rootManifest.Layers = layers
+	rootManifestBytes, err := json.Marshal(rootManifest)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	newRootDesc, err := writeBlob(ocispec.MediaTypeImageManifest, rootManifestBytes, "")
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	newRootDesc.Platform = rootDesc.Platform
+	if len(opts.SigningKeyPaths) == 0 {
+		return newRootDesc, nil, nil
+	}
+
+	// sign the new root manifest, which covers the pulled packages
+	store, err := ocistore.NewWithContext(ctx, filepath.Join(b.tmp, config.BundleReferrers))
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	signatures, err := boci.SignBundle(ctx, store, newRootDesc, opts.SigningKeyPaths, signingKeyPassword(opts.SigningKeyPassword))
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return newRootDesc, signatures, nil
+}
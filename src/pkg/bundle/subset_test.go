@@ -0,0 +1,97 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	goyaml "github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPackageNames(t *testing.T) {
+	require.Equal(t, []string{"a", "b", "c"}, splitPackageNames([]string{"a, b", "c", "a"}))
+	require.Empty(t, splitPackageNames(nil))
+}
+
+func subsetTestBundle() types.UDSBundle {
+	return types.UDSBundle{
+		Metadata: types.UDSMetadata{Name: "example", Version: "0.0.1"},
+		Packages: []types.Package{
+			{Name: "database", Repository: "localhost:888/database", Ref: "0.0.1@sha256:aaa"},
+			{Name: "cache", Repository: "localhost:888/cache", Ref: "0.0.1@sha256:bbb"},
+			{Name: "app", Repository: "localhost:888/app", Ref: "0.0.1@sha256:ccc", DependsOn: []string{"database", "cache"}},
+		},
+	}
+}
+
+func TestSelectBundlePackages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		names    []string
+		expected []types.Package
+		wantErr  string
+	}{
+		{
+			name:  "keeps bundle order",
+			names: []string{"app", "database"},
+			expected: []types.Package{
+				{Name: "database", Repository: "localhost:888/database", Ref: "0.0.1@sha256:aaa"},
+				{Name: "app", Repository: "localhost:888/app", Ref: "0.0.1@sha256:ccc", DependsOn: []string{"database"}},
+			},
+		},
+		{
+			name:  "drops dependencies on packages that aren't selected",
+			names: []string{"app"},
+			expected: []types.Package{
+				{Name: "app", Repository: "localhost:888/app", Ref: "0.0.1@sha256:ccc"},
+			},
+		},
+		{
+			name:    "unknown package",
+			names:   []string{"app", "missing"},
+			wantErr: "package missing is not in bundle example",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := subsetTestBundle()
+			err := selectBundlePackages(&bundle, tc.names)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, bundle.Packages)
+		})
+	}
+}
+
+func TestValidateSubset(t *testing.T) {
+	dir := t.TempDir()
+	writeBundle := func(name string, bundle types.UDSBundle) string {
+		b, err := goyaml.Marshal(bundle)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, b, 0o600))
+		return path
+	}
+	originalPath := writeBundle("original.yaml", subsetTestBundle())
+
+	subset := subsetTestBundle()
+	require.NoError(t, selectBundlePackages(&subset, []string{"database", "app"}))
+	require.NoError(t, validateSubset(writeBundle("subset.yaml", subset), originalPath))
+
+	// a package whose ref was changed after the original was signed
+	tampered := subset
+	tampered.Packages = []types.Package{subset.Packages[0], subset.Packages[1]}
+	tampered.Packages[1].Ref = "0.0.2@sha256:ddd"
+	require.ErrorContains(t, validateSubset(writeBundle("tampered.yaml", tampered), originalPath), "does not match the signed")
+
+	// a package that isn't in the original
+	added := subset
+	added.Packages = append([]types.Package{}, subset.Packages...)
+	added.Packages = append(added.Packages, types.Package{Name: "extra", Repository: "localhost:888/extra", Ref: "0.0.1@sha256:eee"})
+	require.ErrorContains(t, validateSubset(writeBundle("added.yaml", added), originalPath), "package extra is not in bundle example")
+}
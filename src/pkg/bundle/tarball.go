@@ -5,6 +5,7 @@
 package bundle
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -15,6 +16,7 @@ import (
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -24,6 +26,7 @@ import (
 	av4 "github.com/mholt/archiver/v4"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	ocistore "oras.land/oras-go/v2/content/oci"
 )
 
@@ -35,6 +38,7 @@ type tarballBundleProvider struct {
 	// these fields are populated by loadBundleManifest as part of the provider constructor
 	bundleRootDesc ocispec.Descriptor
 	rootManifest   *oci.Manifest
+	referrerDescs  []ocispec.Descriptor
 }
 
 // CreateBundleSBOM creates a bundle-level SBOM from the underlying Zarf packages, if the Zarf package contains an SBOM
@@ -52,8 +56,8 @@ func (tp *tarballBundleProvider) CreateBundleSBOM(extractSBOM bool) error {
 	containsSBOMs := false
 
 	for _, layer := range rootManifest.Layers {
-		// get Zarf image manifests from bundle manifest
-		if layer.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
+		// get Zarf image manifests from bundle manifest, skipping the bundle's metadata which has title annotations
+		if _, ok := layer.Annotations[ocispec.AnnotationTitle]; ok {
 			continue
 		}
 		layerFilePath := filepath.Join(config.BlobsDir, layer.Digest.Encoded())
@@ -124,6 +128,13 @@ func (tp *tarballBundleProvider) getBundleManifest() (*oci.Manifest, error) {
 	return nil, fmt.Errorf("bundle root manifest not loaded")
 }
 
+func (tp *tarballBundleProvider) getBundleRootDesc() (ocispec.Descriptor, error) {
+	if tp.rootManifest != nil {
+		return tp.bundleRootDesc, nil
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("bundle root manifest not loaded")
+}
+
 // loadBundleManifest loads the bundle's root manifest and desc into the tarballBundleProvider so we don't have to load it multiple times
 func (tp *tarballBundleProvider) loadBundleManifest() error {
 	// Create a secure temporary directory for handling files
@@ -149,13 +160,14 @@ func (tp *tarballBundleProvider) loadBundleManifest() error {
 	if err := json.Unmarshal(b, &index); err != nil {
 		return fmt.Errorf("failed to unmarshal index.json: %w", err)
 	}
-	// local bundles only have one manifest entry in their index.json
-	bundleManifestDesc := index.Manifests[0]
-	tp.bundleRootDesc = bundleManifestDesc
-
-	if len(index.Manifests) > 1 {
-		return fmt.Errorf("expected only one manifest in index.json, found %d", len(index.Manifests))
+	// local bundles only have one manifest entry in their index.json, besides any artifacts attached to the bundle
+	bundleManifests, referrers := splitReferrers(index.Manifests)
+	if len(bundleManifests) != 1 {
+		return fmt.Errorf("expected only one manifest in index.json, found %d", len(bundleManifests))
 	}
+	bundleManifestDesc := bundleManifests[0]
+	tp.bundleRootDesc = bundleManifestDesc
+	tp.referrerDescs = referrers
 
 	manifestRelativePath := filepath.Join(config.BlobsDir, bundleManifestDesc.Digest.Encoded())
 
@@ -186,6 +198,39 @@ func (tp *tarballBundleProvider) loadBundleManifest() error {
 	return nil
 }
 
+// blobPath returns the path of a blob extracted from the tarball, ensuring the blob matches its digest
+func (tp *tarballBundleProvider) blobPath(desc ocispec.Descriptor) (string, error) {
+	pathInTarball := filepath.Join(config.BlobsDir, desc.Digest.Encoded())
+	abs := filepath.Join(tp.dst, pathInTarball)
+	if helpers.InvalidPath(abs) || helpers.SHAsMatch(abs, desc.Digest.Encoded()) != nil {
+		if err := av3.Extract(tp.src, pathInTarball, tp.dst); err != nil {
+			return "", fmt.Errorf("failed to extract %s from %s: %w", desc.Digest.Encoded(), tp.src, err)
+		}
+		if err := helpers.SHAsMatch(abs, desc.Digest.Encoded()); err != nil {
+			return "", err
+		}
+	}
+	return abs, nil
+}
+
+// getReferrers gets the artifacts attached to the bundle that were pulled into the tarball with it
+func (tp *tarballBundleProvider) getReferrers() ([]ocispec.Descriptor, content.ReadOnlyStorage, error) {
+	storage := blobStorage(tp.blobPath)
+	referrers, err := localReferrers(tp.ctx, storage, tp.referrerDescs, tp.bundleRootDesc)
+	return referrers, storage, err
+}
+
+// verifyBlobs checks that every blob in the tarball matches its digest, reading the tarball once
+func (tp *tarballBundleProvider) verifyBlobs() error {
+	return av3.Walk(tp.src, func(f av3.File) error {
+		header, ok := f.Header.(*tar.Header)
+		if !ok || f.IsDir() || filepath.Dir(filepath.Clean(header.Name)) != config.BlobsDir {
+			return nil
+		}
+		return verifyBlob(filepath.Base(header.Name), f)
+	})
+}
+
 // LoadBundle loads a bundle from a tarball
 func (tp *tarballBundleProvider) LoadBundle(_ types.BundlePullOptions, _ int) (*types.UDSBundle, types.PathMap, error) {
 	return nil, nil, fmt.Errorf("uds pull does not support pulling local bundles")
@@ -219,11 +264,11 @@ func (tp *tarballBundleProvider) LoadBundleMetadata() (types.PathMap, error) {
 }
 
 // getZarfLayers returns the layers of the Zarf package that are in the bundle
-func (tp *tarballBundleProvider) getZarfLayers(store *ocistore.Store, pkgManifestDesc ocispec.Descriptor) ([]ocispec.Descriptor, int64, error) {
+func getZarfLayers(ctx context.Context, store *ocistore.Store, layoutDir string, pkgManifestDesc ocispec.Descriptor) ([]ocispec.Descriptor, int64, error) {
 	var layersToPull []ocispec.Descriptor
 	estimatedPkgSize := int64(0)
 
-	layerBytes, err := os.ReadFile(filepath.Join(tp.dst, config.BlobsDir, pkgManifestDesc.Digest.Encoded()))
+	layerBytes, err := os.ReadFile(filepath.Join(layoutDir, config.BlobsDir, pkgManifestDesc.Digest.Encoded()))
 	if err != nil {
 		return nil, int64(0), err
 	}
@@ -235,7 +280,7 @@ func (tp *tarballBundleProvider) getZarfLayers(store *ocistore.Store, pkgManifes
 
 	// only grab image layers that we want
 	for _, layer := range zarfImageManifest.Manifest.Layers {
-		ok, err := store.Exists(tp.ctx, layer)
+		ok, err := store.Exists(ctx, layer)
 		if err != nil {
 			return nil, int64(0), err
 		}
@@ -249,26 +294,32 @@ func (tp *tarballBundleProvider) getZarfLayers(store *ocistore.Store, pkgManifes
 }
 
 // PublishBundle publishes a local bundle to a remote OCI registry
-func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote) error {
-	var layersToPush []ocispec.Descriptor
+func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *oci.OrasRemote, tags []string) error {
 	bundleRootManifest, err := tp.getBundleManifest()
 	if err != nil {
 		return err
 	}
+	// the bundle is untarred into tp.dst before publishing
+	return publishFromLayout(tp.ctx, tp.dst, bundleRootManifest, tp.bundleRootDesc, bundle, remote, tags)
+}
+
+// publishFromLayout publishes the bundle in the OCI image layout at layoutDir to a remote OCI registry
+func publishFromLayout(ctx context.Context, layoutDir string, bundleRootManifest *oci.Manifest, bundleRootDesc ocispec.Descriptor, bundle types.UDSBundle, remote *oci.OrasRemote, tags []string) error {
+	var layersToPush []ocispec.Descriptor
 	estimatedBytes := int64(0)
 
-	// reference local store holding untarred bundle
-	store, err := ocistore.NewWithContext(tp.ctx, tp.dst)
+	// reference local store holding the bundle
+	store, err := ocistore.NewWithContext(ctx, layoutDir)
 	if err != nil {
 		return err
 	}
 	// push bundle layers to remote
 	for _, manifestDesc := range bundleRootManifest.Layers {
 		layersToPush = append(layersToPush, manifestDesc)
-		if manifestDesc.Annotations[ocispec.AnnotationTitle] == config.BundleYAML {
-			continue // uds-bundle.yaml doesn't have layers
+		if _, ok := manifestDesc.Annotations[ocispec.AnnotationTitle]; ok {
+			continue // uds-bundle.yaml and signatures don't have layers
 		}
-		layers, estimatedPkgSize, err := tp.getZarfLayers(store, manifestDesc)
+		layers, estimatedPkgSize, err := getZarfLayers(ctx, store, layoutDir, manifestDesc)
 		estimatedBytes += estimatedPkgSize
 		if err != nil {
 			return err
@@ -286,10 +337,10 @@ func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *o
 	remote.SetProgressWriter(progressBar)
 	defer remote.ClearProgressWriter()
 
-	ref := bundle.Metadata.Version
+	ref := bundleRootDesc.Digest.String()
 
-	// check for existing index
-	index, err := boci.GetIndex(remote, ref)
+	// check for existing indexes
+	indexes, err := boci.GetIndexes(remote, tags)
 	if err != nil {
 		return err
 	}
@@ -299,13 +350,14 @@ func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *o
 	retries := 0
 
 	// reset retries if a desc was successful
-	copyOpts.PostCopy = func(_ context.Context, _ ocispec.Descriptor) error {
+	copyOpts.PostCopy = func(_ context.Context, desc ocispec.Descriptor) error {
 		retries = 0
+		events.LayerPushed(desc)
 		return nil
 	}
 
 	for {
-		_, err = oras.Copy(tp.ctx, store, ref, remote.Repo(), ref, copyOpts)
+		_, err = oras.Copy(ctx, store, ref, remote.Repo(), tags[0], copyOpts)
 		if err != nil && retries < maxRetries {
 			retries++
 			message.Debugf("Encountered err during publish: %s\nRetrying %d/%d", err, retries, maxRetries)
@@ -316,8 +368,8 @@ func (tp *tarballBundleProvider) PublishBundle(bundle types.UDSBundle, remote *o
 		break
 	}
 
-	// create or update, then push index.json
-	err = boci.UpdateIndex(index, remote, &bundle, tp.bundleRootDesc)
+	// create or update, then push index.json for each tag
+	err = boci.UpdateIndexes(indexes, remote, &bundle, bundleRootDesc)
 	if err != nil {
 		return err
 	}
@@ -5,16 +5,44 @@
 package bundler
 
 import (
+	"context"
+
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 )
 
 // Bundler is used for bundling packages
 type Bundler struct {
-	bundle    *types.UDSBundle
-	output    string
-	tmpDstDir string
-	sourceDir string
+	bundle     *types.UDSBundle
+	output     string
+	tmpDstDir  string
+	sourceDir  string
+	tags       []string
+	repository string
+	signer     signer
+}
+
+// signer signs the root manifest of a bundle with each of its keys
+type signer struct {
+	keyPaths    []string
+	getPassword func(bool) ([]byte, error)
+}
+
+// sign signs the bundle's root manifest in dst with each key, returning the descriptors of the attached signatures
+func (s signer) sign(ctx context.Context, dst content.Storage, rootDesc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	if len(s.keyPaths) == 0 {
+		return nil, nil
+	}
+	signatures, err := boci.SignBundle(ctx, dst, rootDesc, s.keyPaths, s.getPassword)
+	if err != nil {
+		return nil, err
+	}
+	message.Debugf("Signed bundle %s with %d keys", rootDesc.Digest, len(signatures))
+	return signatures, nil
 }
 
 // Pusher is the interface for pushing bundles
@@ -23,19 +51,28 @@ type Pusher interface {
 
 // Options are the options for creating a bundler
 type Options struct {
-	Bundle    *types.UDSBundle
-	Output    string
-	TmpDstDir string
-	SourceDir string
+	Bundle     *types.UDSBundle
+	Output     string
+	TmpDstDir  string
+	SourceDir  string
+	Tags       []string
+	Repository string
+	// SigningKeyPaths are the private keys the bundle's root manifest is signed with
+	SigningKeyPaths []string
+	// SigningKeyPassword returns the password of the signing keys
+	SigningKeyPassword func(bool) ([]byte, error)
 }
 
 // NewBundler creates a new bundler
 func NewBundler(opts *Options) *Bundler {
 	b := Bundler{
-		bundle:    opts.Bundle,
-		output:    opts.Output,
-		tmpDstDir: opts.TmpDstDir,
-		sourceDir: opts.SourceDir,
+		bundle:     opts.Bundle,
+		output:     opts.Output,
+		tmpDstDir:  opts.TmpDstDir,
+		sourceDir:  opts.SourceDir,
+		tags:       opts.Tags,
+		repository: opts.Repository,
+		signer:     signer{keyPaths: opts.SigningKeyPaths, getPassword: opts.SigningKeyPassword},
 	}
 	return &b
 }
@@ -43,13 +80,13 @@ func NewBundler(opts *Options) *Bundler {
 // Create creates a bundle
 func (b *Bundler) Create() error {
 	if utils.IsRegistryURL(b.output) {
-		remoteBundle := NewRemoteBundle(&RemoteBundleOpts{Bundle: b.bundle, Output: b.output})
+		remoteBundle := NewRemoteBundle(&RemoteBundleOpts{Bundle: b.bundle, Output: b.output, Tags: b.tags, Repository: b.repository, signer: b.signer})
 		err := remoteBundle.create(nil)
 		if err != nil {
 			return err
 		}
 	} else {
-		localBundle := NewLocalBundle(&LocalBundleOpts{Bundle: b.bundle, TmpDstDir: b.tmpDstDir, SourceDir: b.sourceDir, OutputDir: b.output})
+		localBundle := NewLocalBundle(&LocalBundleOpts{Bundle: b.bundle, TmpDstDir: b.tmpDstDir, SourceDir: b.sourceDir, OutputDir: b.output, signer: b.signer})
 		err := localBundle.create(nil)
 		if err != nil {
 			return err
@@ -63,9 +63,15 @@ func pushManifestConfigFromMetadata(r *oci.OrasRemote, metadata *types.UDSMetada
 }
 
 // copied from: https://github.com/defenseunicorns/zarf/blob/main/src/pkg/oci/utils.go
-func referenceFromMetadata(registryLocation string, metadata *types.UDSMetadata) (string, error) {
-	ver := metadata.Version
-	if len(ver) == 0 {
+// repository and tag default to the bundle's name and version
+func referenceFromMetadata(registryLocation string, metadata *types.UDSMetadata, repository, tag string) (string, error) {
+	if repository == "" {
+		repository = metadata.Name
+	}
+	if tag == "" {
+		tag = metadata.Version
+	}
+	if len(tag) == 0 {
 		return "", errors.New("version is required for publishing")
 	}
 
@@ -73,7 +79,7 @@ func referenceFromMetadata(registryLocation string, metadata *types.UDSMetadata)
 		registryLocation = registryLocation + "/"
 	}
 	registryLocation = strings.TrimPrefix(registryLocation, helpers.OCIURLPrefix)
-	raw := fmt.Sprintf("%s%s:%s", registryLocation, metadata.Name, ver)
+	raw := fmt.Sprintf("%s%s:%s", registryLocation, repository, tag)
 
 	message.Debug("Raw OCI reference from metadata:", raw)
 	ref, err := registry.ParseReference(raw)
@@ -5,6 +5,7 @@
 package fetcher
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/defenseunicorns/zarf/src/pkg/layout"
 	"github.com/defenseunicorns/zarf/src/pkg/packager/filters"
 	zarfSources "github.com/defenseunicorns/zarf/src/pkg/packager/sources"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	goyaml "github.com/goccy/go-yaml"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -38,6 +40,18 @@ func loadPkg(pkgTmp string, pkgSrc zarfSources.PackageSource, optionalComponents
 	return pkg, pkgPaths, nil
 }
 
+// verifyPkgSignature verifies the zarf.yaml in dir against the signature next to it, failing if the package isn't signed
+func verifyPkgSignature(dir string, publicKeyPath string) error {
+	signaturePath := filepath.Join(dir, config.ZarfYAMLSignature)
+	if helpers.InvalidPath(signaturePath) {
+		return zarfSources.ErrPkgKeyButNoSig
+	}
+	if err := zarfUtils.CosignVerifyBlob(filepath.Join(dir, config.ZarfYAML), signaturePath, publicKeyPath); err != nil {
+		return fmt.Errorf("package signature did not match the provided key: %w", err)
+	}
+	return nil
+}
+
 // getImgLayerDigests grabs the digests of the layers from the images in the image index
 func getImgLayerDigests(manifestsToInclude []ocispec.Descriptor, pkgPaths *layout.PackagePaths) ([]string, error) {
 	var includeLayers []string
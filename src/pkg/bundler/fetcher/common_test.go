@@ -0,0 +1,48 @@
+package fetcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/test/keypair"
+	zarfSources "github.com/defenseunicorns/zarf/src/pkg/packager/sources"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPkgSignature(t *testing.T) {
+	keys := t.TempDir()
+	privateKey, publicKey := keypair.Write(t, keys, "signer")
+	_, otherKey := keypair.Write(t, keys, "other")
+
+	tests := []struct {
+		name   string
+		signed bool
+		key    string
+		err    string
+	}{
+		{name: "signed with the key", signed: true, key: publicKey},
+		{name: "unsigned", key: publicKey, err: zarfSources.ErrPkgKeyButNoSig.Error()},
+		{name: "signed with another key", signed: true, key: otherKey, err: "package signature did not match the provided key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			zarfYAML := filepath.Join(dir, config.ZarfYAML)
+			require.NoError(t, os.WriteFile(zarfYAML, []byte("kind: ZarfPackageConfig\nmetadata:\n  name: foo\n"), 0o600))
+			if tt.signed {
+				_, err := zarfUtils.CosignSignBlob(zarfYAML, filepath.Join(dir, config.ZarfYAMLSignature), privateKey, keypair.Password)
+				require.NoError(t, err)
+			}
+
+			err := verifyPkgSignature(dir, tt.key)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -10,9 +10,10 @@ import (
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	ocistore "oras.land/oras-go/v2/content/oci"
@@ -22,6 +23,7 @@ import (
 type Fetcher interface {
 	Fetch() ([]ocispec.Descriptor, error)
 	GetPkgMetadata() (zarfTypes.ZarfPackage, error)
+	VerifyPkgSignature(publicKeyPath string) error
 }
 
 // Config is the configuration for the fetcher
@@ -32,6 +34,7 @@ type Config struct {
 	NumPkgs            int
 	BundleRootManifest *ocispec.Manifest
 	Bundle             *types.UDSBundle
+	CacheStats         *cache.Stats
 }
 
 // NewPkgFetcher creates a fetcher object to pull Zarf pkgs into a local bundle
@@ -43,11 +46,11 @@ func NewPkgFetcher(pkg types.Package, fetcherConfig Config) (Fetcher, error) {
 			OS:           oci.MultiOS,
 		}
 		url := fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
-		remote, err := zoci.NewRemote(url, platform)
+		ctx := context.TODO()
+		remote, err := boci.NewMirroredRemote(ctx, url, platform)
 		if err != nil {
 			return nil, err
 		}
-		ctx := context.TODO()
 		pkgRootManifest, err := remote.FetchRoot(ctx)
 		if err != nil {
 			return nil, err
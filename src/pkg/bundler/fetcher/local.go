@@ -62,18 +62,46 @@ func (f *localFetcher) GetPkgMetadata() (zarfTypes.ZarfPackage, error) {
 	}
 	defer os.RemoveAll(tmpDir) //nolint:errcheck
 
-	zarfTarball, err := os.Open(f.cfg.Bundle.Packages[f.cfg.PkgIter].Path)
+	// write zarf.yaml to tmp for checking optional components later on
+	if err := f.extractPkgFiles(tmpDir, config.ZarfYAML); err != nil {
+		return zarfTypes.ZarfPackage{}, err
+	}
+	zarfYAML := zarfTypes.ZarfPackage{}
+	zarfYAMLPath := filepath.Join(tmpDir, config.ZarfYAML)
+	err = utils.ReadYAMLStrict(zarfYAMLPath, &zarfYAML)
 	if err != nil {
 		return zarfTypes.ZarfPackage{}, err
 	}
+	return zarfYAML, err
+}
+
+// VerifyPkgSignature verifies a local Zarf package's signature with the public key
+func (f *localFetcher) VerifyPkgSignature(publicKeyPath string) error {
+	tmpDir, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	if err := f.extractPkgFiles(tmpDir, config.ZarfYAML, config.ZarfYAMLSignature); err != nil {
+		return err
+	}
+	return verifyPkgSignature(tmpDir, publicKeyPath)
+}
+
+// extractPkgFiles extracts files from a local Zarf package's tarball to dst, skipping files that aren't in the package
+func (f *localFetcher) extractPkgFiles(dst string, files ...string) error {
+	zarfTarball, err := os.Open(f.cfg.Bundle.Packages[f.cfg.PkgIter].Path)
+	if err != nil {
+		return err
+	}
+	defer zarfTarball.Close()
 	format := av4.CompressedArchive{
 		Compression: av4.Zstd{},
 		Archival:    av4.Tar{},
 	}
-	if err := format.Extract(context.TODO(), zarfTarball, []string{config.ZarfYAML}, func(_ context.Context, fileInArchive av4.File) error {
-		// write zarf.yaml to tmp for checking optional components later on
-		dst := filepath.Join(tmpDir, fileInArchive.NameInArchive)
-		outFile, err := os.Create(dst)
+	return format.Extract(context.TODO(), zarfTarball, files, func(_ context.Context, fileInArchive av4.File) error {
+		outFile, err := os.Create(filepath.Join(dst, fileInArchive.NameInArchive))
 		if err != nil {
 			return err
 		}
@@ -84,21 +112,8 @@ func (f *localFetcher) GetPkgMetadata() (zarfTypes.ZarfPackage, error) {
 		}
 		defer stream.Close()
 		_, err = io.Copy(outFile, io.Reader(stream))
-		if err != nil {
-			return err
-		}
-		return nil
-	}); err != nil {
-		zarfTarball.Close()
-		return zarfTypes.ZarfPackage{}, err
-	}
-	zarfYAML := zarfTypes.ZarfPackage{}
-	zarfYAMLPath := filepath.Join(tmpDir, config.ZarfYAML)
-	err = utils.ReadYAMLStrict(zarfYAMLPath, &zarfYAML)
-	if err != nil {
-		return zarfTypes.ZarfPackage{}, err
-	}
-	return zarfYAML, err
+		return err
+	})
 }
 
 // toBundle transfers a Zarf package to a given Bundle
@@ -6,9 +6,10 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
@@ -23,7 +24,6 @@ import (
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
-	ocistore "oras.land/oras-go/v2/content/oci"
 )
 
 // remoteFetcher fetches remote Zarf pkgs for local bundles
@@ -72,7 +72,7 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 			continue
 		}
 
-		exists, err := checkLayerExists(ctx, layer, f.cfg.Store, f.cfg.TmpDstDir)
+		exists, err := checkLayerExists(ctx, layer, f.cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -96,9 +96,12 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 		rootPkgDesc.MediaType = zoci.ZarfLayerMediaTypeBlob // force media type to Zarf blob
 		f.cfg.BundleRootManifest.Layers = append(f.cfg.BundleRootManifest.Layers, rootPkgDesc)
 
-		// cache only the image layers that were just pulled
-		err = cachePulledImgLayers(layersToPull, f.cfg.TmpDstDir)
-		if err != nil {
+		// the layers that were just pulled were added to the cache by copyLayers
+		var digests []string
+		for _, layer := range layersToPull {
+			digests = append(digests, layer.Digest.Encoded())
+		}
+		if err := cache.Own(f.cfg.Bundle.Metadata.Name, digests...); err != nil {
 			return nil, err
 		}
 	} else {
@@ -121,7 +124,7 @@ func (f *remoteFetcher) copyRemotePkgLayers(layersToCopy []ocispec.Descriptor) (
 	return descsToBundle, nil
 }
 
-// copyLayers uses ORAS to copy layers from a remote repo to a local OCI store
+// copyLayers uses ORAS to copy layers from a remote repo to a local OCI store, reading from and adding to the UDS cache
 func (f *remoteFetcher) copyLayers(layersToPull []ocispec.Descriptor, estimatedBytes int64) (ocispec.Descriptor, error) {
 	// copy Zarf pkg
 	copyOpts := boci.CreateCopyOpts(layersToPull, config.CommonOptions.OCIConcurrency)
@@ -137,7 +140,8 @@ func (f *remoteFetcher) copyLayers(layersToPull []ocispec.Descriptor, estimatedB
 	}
 
 	go zarfUtils.RenderProgressBarForLocalDirWrite(f.cfg.TmpDstDir, estimatedBytes+tmpDirSize, doneSaving, fmt.Sprintf("Pulling bundle: %s", f.pkg.Name), fmt.Sprintf("Successfully pulled package: %s", f.pkg.Name))
-	rootPkgDesc, err := oras.Copy(context.TODO(), f.remote.Repo(), f.remote.Repo().Reference.String(), f.cfg.Store, "", copyOpts)
+	src := cache.NewTarget(f.remote.Repo(), f.cfg.CacheStats)
+	rootPkgDesc, err := oras.Copy(context.TODO(), src, f.remote.Repo().Reference.String(), f.cfg.Store, "", copyOpts)
 	doneSaving <- err
 	<-doneSaving
 	if err != nil {
@@ -155,7 +159,7 @@ func (f *remoteFetcher) GetPkgMetadata() (zarfTypes.ZarfPackage, error) {
 
 	// create OCI remote
 	url := fmt.Sprintf("%s:%s", f.pkg.Repository, f.pkg.Ref)
-	remote, err := zoci.NewRemote(url, platform)
+	remote, err := boci.NewMirroredRemote(ctx, url, platform)
 	if err != nil {
 		return zarfTypes.ZarfPackage{}, err
 	}
@@ -179,29 +183,35 @@ func (f *remoteFetcher) GetPkgMetadata() (zarfTypes.ZarfPackage, error) {
 	return zarfYAML, err
 }
 
-// cachePulledImgLayers caches the image layers that were just pulled
-func cachePulledImgLayers(pulledLayers []ocispec.Descriptor, dstDir string) (err error) {
-	for _, layer := range pulledLayers {
-		if strings.Contains(layer.Annotations[ocispec.AnnotationTitle], config.BlobsDir) {
-			err = cache.Add(filepath.Join(dstDir, config.BlobsDir, layer.Digest.Encoded()))
-			if err != nil {
-				return err
-			}
-		}
+// VerifyPkgSignature verifies a remote Zarf package's signature with the public key
+func (f *remoteFetcher) VerifyPkgSignature(publicKeyPath string) error {
+	tmpDir, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer os.RemoveAll(tmpDir) //nolint:errcheck
+
+	// pulls the signature along with the zarf.yaml if the package is signed
+	if _, err := f.remote.PullPackageMetadata(context.TODO(), tmpDir); err != nil {
+		return err
+	}
+	return verifyPkgSignature(tmpDir, publicKeyPath)
 }
 
 // checkLayerExists checks if a layer already exists in the bundle store or the cache
-func checkLayerExists(ctx context.Context, layer ocispec.Descriptor, store *ocistore.Store, dstDir string) (bool, error) {
-	if exists, _ := store.Exists(ctx, layer); exists {
+func checkLayerExists(ctx context.Context, layer ocispec.Descriptor, cfg Config) (bool, error) {
+	if exists, _ := cfg.Store.Exists(ctx, layer); exists {
 		return true, nil
 	} else if cache.Exists(layer.Digest.Encoded()) {
-		err := cache.Use(layer.Digest.Encoded(), filepath.Join(dstDir, config.BlobsDir))
-		if err != nil {
+		err := cache.Use(layer.Digest.Encoded(), filepath.Join(cfg.TmpDstDir, config.BlobsDir))
+		if errors.Is(err, cache.ErrCorrupt) {
+			// the corrupt layer was dropped from the cache, so pull it again
+			return false, nil
+		} else if err != nil {
 			return false, err
 		}
-		return true, nil
+		cfg.CacheStats.Hit(layer.Size)
+		return true, cache.Own(cfg.Bundle.Metadata.Name, layer.Digest.Encoded())
 	}
 	return false, nil
 }
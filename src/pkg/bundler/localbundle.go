@@ -11,11 +11,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/fetcher"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
@@ -35,6 +38,7 @@ type LocalBundleOpts struct {
 	TmpDstDir string
 	SourceDir string
 	OutputDir string
+	signer    signer
 }
 
 // LocalBundle enables create ops with local bundles
@@ -43,6 +47,7 @@ type LocalBundle struct {
 	tmpDstDir string
 	sourceDir string
 	outputDir string
+	signer    signer
 }
 
 // NewLocalBundle creates a new local bundle
@@ -52,6 +57,7 @@ func NewLocalBundle(opts *LocalBundleOpts) *LocalBundle {
 		tmpDstDir: opts.TmpDstDir,
 		sourceDir: opts.SourceDir,
 		outputDir: opts.OutputDir,
+		signer:    opts.signer,
 	}
 }
 
@@ -71,12 +77,14 @@ func (lo *LocalBundle) create(signature []byte) error {
 		MediaType: ocispec.MediaTypeImageManifest,
 	}
 
+	cacheStats := &cache.Stats{}
 	fetcherConfig := fetcher.Config{
 		Bundle:             bundle,
 		Store:              store,
 		TmpDstDir:          lo.tmpDstDir,
 		NumPkgs:            len(lo.bundle.Packages),
 		BundleRootManifest: &rootManifest,
+		CacheStats:         cacheStats,
 	}
 
 	message.Debug("Bundling", bundle.Metadata.Name, "to", lo.tmpDstDir)
@@ -93,10 +101,14 @@ func (lo *LocalBundle) create(signature []byte) error {
 		if err != nil {
 			return err
 		}
+		events.PackageStarted(pkg.Name)
+		start := time.Now()
 		pkgDescs, err := pkgFetcher.Fetch()
 		if err != nil {
+			events.PackageFinished(pkg.Name, types.StatusFailed, time.Since(start), err)
 			return err
 		}
+		events.PackageFinished(pkg.Name, types.StatusSucceeded, time.Since(start), nil)
 
 		// add to artifactPathMap for local bundle tarball
 		for _, layer := range pkgDescs {
@@ -105,6 +117,8 @@ func (lo *LocalBundle) create(signature []byte) error {
 		}
 	}
 
+	message.Infof("UDS cache: %s", cacheStats)
+
 	message.HeaderInfof("🚧 Building Bundle")
 
 	// push uds-bundle.yaml to OCI store
@@ -158,8 +172,24 @@ func (lo *LocalBundle) create(signature []byte) error {
 	if err != nil {
 		return err
 	}
-	// ensure the bundle root manifest is the only manifest in the index.json
-	err = cleanIndexJSON(lo.tmpDstDir, rootManifestDesc)
+	// sign the root manifest, which covers every package in the bundle, and add the signatures to the tarball
+	signatures, err := lo.signer.sign(ctx, store, rootManifestDesc)
+	if err != nil {
+		return err
+	}
+	for _, desc := range signatures {
+		manifest, err := boci.FetchReferrer(ctx, store, desc)
+		if err != nil {
+			return err
+		}
+		for _, blob := range append(manifest.Layers, desc) {
+			digest := blob.Digest.Encoded()
+			artifactPathMap[filepath.Join(lo.tmpDstDir, config.BlobsDir, digest)] = filepath.Join(config.BlobsDir, digest)
+		}
+	}
+
+	// ensure the bundle root manifest and its signatures are the only manifests in the index.json
+	err = cleanIndexJSON(lo.tmpDstDir, rootManifestDesc, signatures)
 	if err != nil {
 		return err
 	}
@@ -303,7 +333,8 @@ func pushBundleSignature(store *ocistore.Store, signature []byte) (ocispec.Descr
 
 // rebuild index.json because copying remote Zarf pkgs adds unnecessary entries
 // this is due to root manifest in Zarf packages having an image manifest media type
-func cleanIndexJSON(tmpDir string, bundleRootDesc ocispec.Descriptor) error {
+// the bundle's signatures are kept after its root manifest, as they're attached to it
+func cleanIndexJSON(tmpDir string, bundleRootDesc ocispec.Descriptor, signatures []ocispec.Descriptor) error {
 	indexBytes, err := os.ReadFile(filepath.Join(tmpDir, "index.json"))
 	if err != nil {
 		return err
@@ -319,6 +350,7 @@ func cleanIndexJSON(tmpDir string, bundleRootDesc ocispec.Descriptor) error {
 			break
 		}
 	}
+	index.Manifests = append(index.Manifests, signatures...)
 
 	err = utils.ToLocalFile(index, filepath.Join(tmpDir, "index.json"))
 	if err != nil {
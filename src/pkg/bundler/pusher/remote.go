@@ -11,11 +11,13 @@ import (
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/errgroup"
 )
 
 // RemotePusher contains methods for pulling remote Zarf packages into a bundle
@@ -32,6 +34,7 @@ type Config struct {
 	PkgIter         int
 	NumPkgs         int
 	Bundle          *types.UDSBundle
+	CacheStats      *cache.Stats
 }
 
 // NewPkgPusher creates a pusher object to push Zarf pkgs to a remote bundle
@@ -96,20 +99,10 @@ func (p *RemotePusher) remoteToRemote(layersToCopy []ocispec.Descriptor) error {
 	ctx := context.TODO()
 	srcRef := p.cfg.RemoteSrc.Repo().Reference
 	dstRef := p.cfg.RemoteDst.Repo().Reference
-	// stream copy if different registry
+	// stream copy through the UDS cache if different registry
 	if srcRef.Registry != dstRef.Registry {
 		message.Debugf("Streaming layers from %s --> %s", srcRef, dstRef)
-		// filterLayers returns true if the layer is in the list of layers to copy, this allows for
-		// copying only the layers that are required by the required + specified optional components
-		filterLayers := func(d ocispec.Descriptor) bool {
-			for _, layer := range layersToCopy {
-				if layer.Digest == d.Digest {
-					return true
-				}
-			}
-			return false
-		}
-		if err := oci.Copy(ctx, p.cfg.RemoteSrc.OrasRemote, p.cfg.RemoteDst.OrasRemote, filterLayers, config.CommonOptions.OCIConcurrency, nil); err != nil {
+		if err := p.copyLayers(ctx, layersToCopy); err != nil {
 			return err
 		}
 	} else {
@@ -123,7 +116,7 @@ func (p *RemotePusher) remoteToRemote(layersToCopy []ocispec.Descriptor) error {
 			}
 			spinner.Updatef("Mounting %s", layer.Digest.Encoded())
 			if err := p.cfg.RemoteDst.Repo().Mount(ctx, layer, srcRef.Repository, func() (io.ReadCloser, error) {
-				return p.cfg.RemoteSrc.Repo().Fetch(ctx, layer)
+				return cache.Fetch(ctx, p.cfg.RemoteSrc.Repo(), layer, p.cfg.CacheStats)
 			}); err != nil {
 				return err
 			}
@@ -132,3 +125,55 @@ func (p *RemotePusher) remoteToRemote(layersToCopy []ocispec.Descriptor) error {
 	}
 	return nil
 }
+
+// copyLayers copies the Zarf pkg's layers and config that aren't already in the remote bundle, reading from and adding to the UDS cache
+//
+// up to --oci-concurrency layers are copied at once
+func (p *RemotePusher) copyLayers(ctx context.Context, layersToCopy []ocispec.Descriptor) error {
+	layersToCopy = append(layersToCopy, p.cfg.PkgRootManifest.Config)
+	// pushed holds the digests of the layers that were pushed, in the order of layersToCopy
+	pushed := make([]string, len(layersToCopy))
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(max(config.CommonOptions.OCIConcurrency, 1))
+	for i, layer := range layersToCopy {
+		if layer.Digest == "" {
+			continue
+		}
+		i, layer := i, layer
+		eg.Go(func() error {
+			exists, err := p.cfg.RemoteDst.Repo().Exists(ctx, layer)
+			if err != nil {
+				return err
+			}
+			if exists {
+				message.Debugf("Layer %s already exists in %s, skipping", layer.Digest.Encoded(), p.cfg.RemoteDst.Repo().Reference)
+				return nil
+			}
+			rc, err := cache.Fetch(ctx, p.cfg.RemoteSrc.Repo(), layer, p.cfg.CacheStats)
+			if err != nil {
+				return err
+			}
+			err = p.cfg.RemoteDst.Repo().Push(ctx, layer, rc)
+			// closing the layer adds it to the cache once it has been read in full
+			if closeErr := rc.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to push layer %s to %s: %w", layer.Digest, p.cfg.RemoteDst.Repo().Reference, err)
+			}
+			pushed[i] = layer.Digest.Encoded()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	var digests []string
+	for _, digest := range pushed {
+		if digest != "" {
+			digests = append(digests, digest)
+		}
+	}
+	return cache.Own(p.cfg.Bundle.Metadata.Name, digests...)
+}
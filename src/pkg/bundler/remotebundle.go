@@ -7,10 +7,13 @@ package bundler
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
 	"github.com/defenseunicorns/uds-cli/src/pkg/bundler/pusher"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
@@ -21,24 +24,33 @@ import (
 
 // RemoteBundleOpts are the options for creating a remote bundle
 type RemoteBundleOpts struct {
-	Bundle    *types.UDSBundle
-	TmpDstDir string
-	Output    string
+	Bundle     *types.UDSBundle
+	TmpDstDir  string
+	Output     string
+	Tags       []string
+	Repository string
+	signer     signer
 }
 
 // RemoteBundle enables create ops with remote bundles
 type RemoteBundle struct {
-	bundle    *types.UDSBundle
-	tmpDstDir string
-	output    string
+	bundle     *types.UDSBundle
+	tmpDstDir  string
+	output     string
+	tags       []string
+	repository string
+	signer     signer
 }
 
 // NewRemoteBundle creates a new remote bundle
 func NewRemoteBundle(opts *RemoteBundleOpts) *RemoteBundle {
 	return &RemoteBundle{
-		bundle:    opts.Bundle,
-		tmpDstDir: opts.TmpDstDir,
-		output:    opts.Output,
+		bundle:     opts.Bundle,
+		tmpDstDir:  opts.TmpDstDir,
+		output:     opts.Output,
+		tags:       opts.Tags,
+		repository: opts.Repository,
+		signer:     opts.signer,
 	}
 }
 
@@ -48,7 +60,11 @@ func (r *RemoteBundle) create(signature []byte) error {
 
 	// set the bundle remote's reference from metadata
 	r.output = boci.EnsureOCIPrefix(r.output)
-	ref, err := referenceFromMetadata(r.output, &r.bundle.Metadata)
+	tags, err := boci.ResolveTags(r.tags, r.bundle.Metadata)
+	if err != nil {
+		return err
+	}
+	ref, err := referenceFromMetadata(r.output, &r.bundle.Metadata, r.repository, tags[0])
 	if err != nil {
 		return err
 	}
@@ -58,7 +74,7 @@ func (r *RemoteBundle) create(signature []byte) error {
 	}
 
 	// create the bundle remote
-	bundleRemote, err := zoci.NewRemote(ref, platform)
+	bundleRemote, err := boci.NewRemote(ref, platform)
 	if err != nil {
 		return err
 	}
@@ -70,16 +86,18 @@ func (r *RemoteBundle) create(signature []byte) error {
 	message.Debug("Bundling", bundle.Metadata.Name, "to", dstRef)
 
 	rootManifest := ocispec.Manifest{}
+	cacheStats := &cache.Stats{}
 	pusherConfig := pusher.Config{
-		Bundle:    bundle,
-		RemoteDst: *bundleRemote,
-		NumPkgs:   len(bundle.Packages),
+		Bundle:     bundle,
+		RemoteDst:  *bundleRemote,
+		NumPkgs:    len(bundle.Packages),
+		CacheStats: cacheStats,
 	}
 
 	for i, pkg := range bundle.Packages {
 		// todo: can leave this block here or move to pusher.NewPkgPusher (would be closer to NewPkgFetcher pattern)
 		pkgURL := fmt.Sprintf("%s:%s", pkg.Repository, pkg.Ref)
-		src, err := zoci.NewRemote(pkgURL, platform)
+		src, err := boci.NewMirroredRemote(ctx, pkgURL, platform)
 		if err != nil {
 			return err
 		}
@@ -92,12 +110,17 @@ func (r *RemoteBundle) create(signature []byte) error {
 		pusherConfig.PkgIter = i
 
 		remotePusher := pusher.NewPkgPusher(pkg, pusherConfig)
+		events.PackageStarted(pkg.Name)
+		start := time.Now()
 		zarfManifestDesc, err := remotePusher.Push()
 		if err != nil {
+			events.PackageFinished(pkg.Name, types.StatusFailed, time.Since(start), err)
 			return err
 		}
+		events.PackageFinished(pkg.Name, types.StatusSucceeded, time.Since(start), nil)
 		rootManifest.Layers = append(rootManifest.Layers, zarfManifestDesc)
 	}
+	message.Infof("UDS cache: %s", cacheStats)
 
 	// push the bundle's metadata
 	bundleYamlBytes, err := goyaml.Marshal(bundle)
@@ -116,8 +139,9 @@ func (r *RemoteBundle) create(signature []byte) error {
 	rootManifest.Layers = append(rootManifest.Layers, *bundleYamlDesc)
 
 	// push the bundle's signature
+	var bundleYamlSigDesc *ocispec.Descriptor
 	if len(signature) > 0 {
-		bundleYamlSigDesc, err := bundleRemote.PushLayer(ctx, signature, zoci.ZarfLayerMediaTypeBlob)
+		bundleYamlSigDesc, err = bundleRemote.PushLayer(ctx, signature, zoci.ZarfLayerMediaTypeBlob)
 		if err != nil {
 			return err
 		}
@@ -136,8 +160,8 @@ func (r *RemoteBundle) create(signature []byte) error {
 
 	message.Debug("Pushed config:", message.JSONValue(configDesc))
 
-	// check for existing index
-	index, err := boci.GetIndex(bundleRemote.OrasRemote, dstRef.String())
+	// check for existing indexes
+	indexes, err := boci.GetIndexes(bundleRemote.OrasRemote, tags)
 	if err != nil {
 		return err
 	}
@@ -151,12 +175,24 @@ func (r *RemoteBundle) create(signature []byte) error {
 		return err
 	}
 
-	// create or update, then push index.json
-	err = boci.UpdateIndex(index, bundleRemote.OrasRemote, bundle, *rootManifestDesc)
+	// create or update, then push index.json for each tag
+	err = boci.UpdateIndexes(indexes, bundleRemote.OrasRemote, bundle, *rootManifestDesc)
 	if err != nil {
 		return err
 	}
 
+	// also attach the signature as a referrer so it can be discovered without pulling the bundle
+	if bundleYamlSigDesc != nil {
+		if err := boci.AttachSignature(ctx, bundleRemote.Repo(), *rootManifestDesc, *bundleYamlSigDesc); err != nil {
+			return err
+		}
+	}
+
+	// sign the root manifest, which covers every package in the bundle
+	if _, err := r.signer.sign(ctx, bundleRemote.Repo(), *rootManifestDesc); err != nil {
+		return err
+	}
+
 	message.HorizontalRule()
 	flags := ""
 	if config.CommonOptions.Insecure {
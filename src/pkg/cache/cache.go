@@ -5,17 +5,28 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
 )
 
+// ErrCorrupt is returned when a cached layer doesn't match its digest, the layer is removed from the cache so it can be fetched again
+var ErrCorrupt = errors.New("cached layer is corrupt")
+
+// verifiedSuffix is the suffix of the stamp written next to a layer once it has been verified
+const verifiedSuffix = ".verified"
+
 func expandTilde(cachePath string) string {
-	if cachePath[:2] == "~/" {
+	if strings.HasPrefix(cachePath, "~/") {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			fmt.Printf("Error in cache dir: %v\n", err)
@@ -26,63 +37,161 @@ func expandTilde(cachePath string) string {
 	return cachePath
 }
 
-// Add adds a file to the cache
-func Add(filePathToAdd string) error {
-	// ensure cache dir exists
-	cacheDir := config.CommonOptions.CachePath
-	if err := os.MkdirAll(filepath.Join(cacheDir, config.UDSCacheLayers), 0o755); err != nil {
-		return err
-	}
+// Dir returns the path of the UDS cache
+func Dir() string {
+	return expandTilde(config.CommonOptions.CachePath)
+}
 
-	// if file already in cache, return
-	filename := strings.Split(filePathToAdd, config.BlobsDir)[1]
-	if Exists(filename) {
-		return nil
+// createTemp creates a temp file in the cache to write a layer to, so a partial write is never seen under the layer's digest
+//
+// the cache is locked until unlock is called so the temp file isn't removed by pruning while it is written
+func createTemp(layerDigest string) (tmp *os.File, unlock func(), err error) {
+	layersDir := filepath.Join(Dir(), config.UDSCacheLayers)
+	if err := os.MkdirAll(layersDir, 0o755); err != nil {
+		return nil, nil, err
 	}
+	unlock, err = Lock()
+	if err != nil {
+		return nil, nil, err
+	}
+	tmp, err = os.CreateTemp(layersDir, layerDigest+".tmp-*")
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return tmp, unlock, nil
+}
 
-	srcFile, err := os.Open(filePathToAdd)
+// commit closes a layer's temp file and, if the sha256 sum written to h matches the layer's digest, atomically moves it into place
+func commit(tmp *os.File, h hash.Hash, layerDigest string) error {
+	err := tmp.Sync()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	if err := checkDigest(h, layerDigest); err != nil {
+		return err
+	}
+	// cached layers are hardlinked, so make sure they can't be changed through the links
+	if err := os.Chmod(tmp.Name(), readOnly); err != nil {
+		return err
+	}
 
-	dstFile, err := os.Create(filepath.Join(cacheDir, config.UDSCacheLayers, filename))
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, layerDigest)
+	if err := os.Rename(tmp.Name(), layerCachePath); err != nil {
+		return err
+	}
+	info, err := os.Stat(layerCachePath)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	return stamp(layerCachePath, info)
 }
 
 // Exists checks if a layer exists in the cache
 func Exists(layerDigest string) bool {
-	cacheDir := config.CommonOptions.CachePath
-	layerCachePath := filepath.Join(expandTilde(cacheDir), config.UDSCacheLayers, layerDigest)
-	_, err := os.Stat(layerCachePath)
-	return !os.IsNotExist(err)
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, layerDigest)
+	info, err := os.Stat(layerCachePath)
+	return err == nil && info.Mode().IsRegular()
 }
 
-// Use copies a layer from the cache to the dst dir
+// Use places a layer from the cache in the dst dir, verifying the layer unless it has already been verified
+//
+// the layer is reflinked or hardlinked where the filesystem allows and only copied as a fallback, see place
+// a layer that doesn't match its digest is removed from the cache and ErrCorrupt is returned so the caller can fetch it again
 func Use(layerDigest, dstDir string) error {
-	cacheDir := config.CommonOptions.CachePath
-	layerCachePath := filepath.Join(expandTilde(cacheDir), config.UDSCacheLayers, layerDigest)
-	srcFile, err := os.Open(layerCachePath)
+	unlock, err := Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, layerDigest)
+	info, err := os.Stat(layerCachePath)
 	if err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	if err := verify(layerCachePath, layerDigest, info); err != nil {
+		return err
+	}
 
 	// ensure blobs/sha256 dir has been created
 	if err := os.MkdirAll(dstDir, 0o755); err != nil {
 		return err
 	}
+	if err := place(layerCachePath, filepath.Join(dstDir, layerDigest)); err != nil {
+		return err
+	}
+	// the modification time of cached files is their last use for pruning
+	return Touch(layerCachePath)
+}
 
-	dstFile, err := os.Create(filepath.Join(dstDir, layerDigest))
+// verify checks a cached layer matches its digest unless it has already been verified
+//
+// a layer that doesn't match its digest is removed from the cache and ErrCorrupt is returned
+func verify(layerCachePath, layerDigest string, info os.FileInfo) error {
+	if isStamped(layerCachePath, info) {
+		return nil
+	}
+	f, err := os.Open(layerCachePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err == nil {
+		err = checkDigest(h, layerDigest)
+	}
+	if err != nil {
+		message.Warnf("Removing corrupt layer %s from the UDS cache: %s", layerDigest, err.Error())
+		_ = os.Remove(layerCachePath)
+		_ = os.Remove(layerCachePath + verifiedSuffix)
+		return fmt.Errorf("%w: %s", ErrCorrupt, layerDigest)
+	}
+	return stamp(layerCachePath, info)
+}
+
+// checkDigest checks the sha256 sum written to h matches the encoded digest
+func checkDigest(h hash.Hash, digest string) error {
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != digest {
+		return fmt.Errorf("expected sha256 %s, found %s", digest, actual)
+	}
+	return nil
+}
+
+// stamp records that a cached layer has been verified
+//
+// the stamp holds the layer's size, modification time and inode when it was verified, so a layer that is changed or
+// replaced afterwards is verified again. info must be from before the layer was verified
+func stamp(layerCachePath string, info os.FileInfo) error {
+	return writeFileAtomic(layerCachePath+verifiedSuffix, []byte(stampOf(info)))
+}
+
+// isStamped returns true if a cached layer has been verified and hasn't changed since
+func isStamped(layerCachePath string, info os.FileInfo) bool {
+	b, err := os.ReadFile(layerCachePath + verifiedSuffix)
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(b)) == stampOf(info)
+}
+
+// stampOf returns the stamp of a cached layer
+func stampOf(info os.FileInfo) string {
+	return fmt.Sprintf("%d %d %d", info.Size(), info.ModTime().UnixNano(), inode(info))
+}
+
+// restamp updates the stamp of a verified layer after its modification time is changed to record its last use
+func restamp(layerCachePath string, before os.FileInfo) error {
+	if !isStamped(layerCachePath, before) {
+		return nil
+	}
+	info, err := os.Stat(layerCachePath)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
-	_, err = io.Copy(dstFile, srcFile)
-	return err
+	return stamp(layerCachePath, info)
 }
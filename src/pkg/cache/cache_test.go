@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// cacheLayer caches a layer the way it's cached when it's fetched, returning its encoded digest
+func cacheLayer(t *testing.T, content []byte) string {
+	t.Helper()
+	desc := ocispec.Descriptor{Digest: digest.FromBytes(content), Size: int64(len(content))}
+	rc, err := Fetch(context.Background(), fetcherFunc(func(_ ocispec.Descriptor) []byte { return content }), desc, nil)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	return desc.Digest.Encoded()
+}
+
+func TestUse(t *testing.T) {
+	setupCache(t)
+	encoded := cacheLayer(t, []byte("image layer"))
+	require.True(t, Exists(encoded))
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, encoded)
+	info, err := os.Stat(layerCachePath)
+	require.NoError(t, err)
+	require.True(t, isStamped(layerCachePath, info))
+
+	dst := t.TempDir()
+	require.NoError(t, Use(encoded, dst))
+	// using a layer marks it as used without it having to be verified again
+	info, err = os.Stat(layerCachePath)
+	require.NoError(t, err)
+	require.True(t, isStamped(layerCachePath, info))
+	b, err := os.ReadFile(filepath.Join(dst, encoded))
+	require.NoError(t, err)
+	require.Equal(t, "image layer", string(b))
+
+	// no temp files are left in the cache
+	files, err := os.ReadDir(filepath.Join(Dir(), config.UDSCacheLayers))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestUseDropsCorruptLayers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		corrupt func(t *testing.T, layerCachePath string)
+	}{
+		{
+			name: "unverified layer",
+			corrupt: func(t *testing.T, layerCachePath string) {
+				require.NoError(t, os.Remove(layerCachePath+verifiedSuffix))
+				require.NoError(t, os.WriteFile(layerCachePath, []byte("image lay3r"), 0o600))
+			},
+		},
+		{
+			name: "layer changed in place without changing its size",
+			corrupt: func(t *testing.T, layerCachePath string) {
+				require.NoError(t, os.Chmod(layerCachePath, 0o600))
+				require.NoError(t, os.WriteFile(layerCachePath, []byte("image lay3r"), 0o600))
+			},
+		},
+		{
+			name: "layer replaced with the same size and modification time",
+			corrupt: func(t *testing.T, layerCachePath string) {
+				info, err := os.Stat(layerCachePath)
+				require.NoError(t, err)
+				replacement := layerCachePath + ".replacement"
+				require.NoError(t, os.WriteFile(replacement, []byte("image lay3r"), 0o600))
+				require.NoError(t, os.Chtimes(replacement, info.ModTime(), info.ModTime()))
+				require.NoError(t, os.Rename(replacement, layerCachePath))
+			},
+		},
+		{
+			name: "truncated layer",
+			corrupt: func(t *testing.T, layerCachePath string) {
+				require.NoError(t, os.WriteFile(layerCachePath, []byte("image"), 0o600))
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupCache(t)
+			encoded := cacheLayer(t, []byte("image layer"))
+			layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, encoded)
+			tc.corrupt(t, layerCachePath)
+
+			dst := t.TempDir()
+			require.ErrorIs(t, Use(encoded, dst), ErrCorrupt)
+			require.False(t, Exists(encoded))
+			require.NoFileExists(t, layerCachePath+verifiedSuffix)
+			require.NoFileExists(t, filepath.Join(dst, encoded))
+
+			// the layer can be cached again once it's fetched
+			cacheLayer(t, []byte("image layer"))
+			require.NoError(t, Use(encoded, dst))
+		})
+	}
+}
+
+func TestConcurrentFetch(t *testing.T) {
+	setupCache(t)
+	content := []byte("image layer")
+	desc := ocispec.Descriptor{Digest: digest.FromBytes(content), Size: int64(len(content))}
+	src := fetcherFunc(func(_ ocispec.Descriptor) []byte { return content })
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc, err := Fetch(context.Background(), src, desc, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if _, err := io.ReadAll(rc); err != nil {
+				errs <- err
+			}
+			errs <- rc.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, Use(desc.Digest.Encoded(), t.TempDir()))
+	entries, err := List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestPruneRemovesTempFiles(t *testing.T) {
+	setupCache(t)
+	encoded := cacheLayer(t, []byte("image layer"))
+	layersDir := filepath.Join(Dir(), config.UDSCacheLayers)
+	partial := filepath.Join(layersDir, encoded+".tmp-123")
+	require.NoError(t, os.WriteFile(partial, []byte("image"), 0o600))
+
+	removed, err := Prune(PruneOptions{MaxSize: 1 << 20})
+	require.NoError(t, err)
+	require.Empty(t, removed)
+	require.NoFileExists(t, partial)
+	require.True(t, Exists(encoded))
+}
+
+func TestUseDoesNotExposeCachedLayer(t *testing.T) {
+	setupCache(t)
+	encoded := cacheLayer(t, []byte("image layer"))
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, encoded)
+
+	// using a layer twice replaces the first copy
+	dst := t.TempDir()
+	require.NoError(t, Use(encoded, dst))
+	require.NoError(t, Use(encoded, dst))
+	dstPath := filepath.Join(dst, encoded)
+	b, err := os.ReadFile(dstPath)
+	require.NoError(t, err)
+	require.Equal(t, "image layer", string(b))
+
+	cached, err := os.Stat(layerCachePath)
+	require.NoError(t, err)
+	placed, err := os.Stat(dstPath)
+	require.NoError(t, err)
+	// a hardlink shares the cached file, which is read-only, otherwise the layer is an independent clone or copy
+	if os.SameFile(cached, placed) {
+		require.Equal(t, os.FileMode(readOnly), cached.Mode().Perm())
+	} else {
+		require.NoError(t, os.WriteFile(dstPath, []byte("changed"), 0o600))
+		require.NoError(t, Use(encoded, t.TempDir()))
+	}
+
+	// the layer can be removed downstream without affecting the cache
+	require.NoError(t, os.Remove(dstPath))
+	require.True(t, Exists(encoded))
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.WriteFile(src, []byte("image layer"), readOnly))
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, copyFile(src, dst))
+	b, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "image layer", string(b))
+
+	info, err := os.Stat(dst)
+	require.NoError(t, err)
+	require.NotEqual(t, os.FileMode(readOnly), info.Mode().Perm())
+}
+
+func TestPlace(t *testing.T) {
+	dir := t.TempDir()
+	writable := filepath.Join(dir, "writable")
+	require.NoError(t, os.WriteFile(writable, []byte("image layer"), 0o644))
+	readOnlySrc := filepath.Join(dir, "read-only")
+	require.NoError(t, os.WriteFile(readOnlySrc, []byte("image layer"), readOnly))
+
+	// files that can be written to are never hardlinked, since writing through dst would change them
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, Place(writable, dst))
+	srcInfo, err := os.Stat(writable)
+	require.NoError(t, err)
+	dstInfo, err := os.Stat(dst)
+	require.NoError(t, err)
+	require.False(t, os.SameFile(srcInfo, dstInfo))
+	require.NoError(t, os.WriteFile(dst, []byte("changed"), 0o644))
+	b, err := os.ReadFile(writable)
+	require.NoError(t, err)
+	require.Equal(t, "image layer", string(b))
+
+	// read-only files can be
+	require.NoError(t, Place(readOnlySrc, dst))
+	b, err = os.ReadFile(dst)
+	require.NoError(t, err)
+	require.Equal(t, "image layer", string(b))
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+)
+
+// Stats counts the blobs served from the cache and fetched from registries
+type Stats struct {
+	mu        sync.Mutex
+	Hits      int
+	HitBytes  int64
+	Misses    int
+	MissBytes int64
+}
+
+// Hit records a blob served from the cache
+func (s *Stats) Hit(size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Hits++
+	s.HitBytes += size
+}
+
+// Miss records a blob fetched from a registry
+func (s *Stats) Miss(size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Misses++
+	s.MissBytes += size
+}
+
+func (s *Stats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("%d hits (%s), %d misses (%s)",
+		s.Hits, zarfUtils.ByteFormat(float64(s.HitBytes), 2), s.Misses, zarfUtils.ByteFormat(float64(s.MissBytes), 2))
+}
+
+// Fetch reads a blob from the cache, falling back to fetching it from src and adding it to the cache as it is read
+func Fetch(ctx context.Context, src content.Fetcher, desc ocispec.Descriptor, stats *Stats) (io.ReadCloser, error) {
+	if desc.Digest.Validate() != nil || desc.Digest.Algorithm() != digest.SHA256 {
+		return src.Fetch(ctx, desc)
+	}
+	encoded := desc.Digest.Encoded()
+	if Exists(encoded) {
+		rc, err := open(encoded, desc.Size)
+		if err == nil {
+			stats.Hit(desc.Size)
+			return rc, nil
+		}
+		message.Debugf("Fetching %s instead of using the UDS cache: %s", encoded, err.Error())
+	}
+
+	rc, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	stats.Miss(desc.Size)
+	tmp, unlock, err := createTemp(encoded)
+	if err != nil {
+		message.Debugf("Unable to add %s to the UDS cache: %s", encoded, err.Error())
+		return rc, nil
+	}
+	return &cachingReader{rc: rc, desc: desc, tmp: tmp, unlock: unlock, h: sha256.New()}, nil
+}
+
+// NewTarget wraps src so that blobs copied from it with ORAS are read from and added to the cache
+func NewTarget(src oras.ReadOnlyTarget, stats *Stats) oras.ReadOnlyTarget {
+	return &target{ReadOnlyTarget: src, stats: stats}
+}
+
+type target struct {
+	oras.ReadOnlyTarget
+	stats *Stats
+}
+
+// Fetch fetches a blob through the cache
+func (t *target) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	return Fetch(ctx, t.ReadOnlyTarget, desc, t.stats)
+}
+
+// open opens a cached blob, verifying it unless it has already been verified
+//
+// a blob that doesn't match its digest is removed from the cache and ErrCorrupt is returned
+func open(encoded string, size int64) (io.ReadCloser, error) {
+	unlock, err := Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	layerCachePath := filepath.Join(Dir(), config.UDSCacheLayers, encoded)
+	f, err := os.Open(layerCachePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() != size {
+		f.Close()
+		return nil, fmt.Errorf("expected %s to be %d bytes, found %d", encoded, size, info.Size())
+	}
+
+	if err := verify(layerCachePath, encoded, info); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// the modification time of cached files is their last use for pruning
+	if err := Touch(layerCachePath); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// cachingReader writes a blob to a temp file in the cache as it is read, adding it to the cache once it has been read in full
+type cachingReader struct {
+	rc     io.ReadCloser
+	desc   ocispec.Descriptor
+	tmp    *os.File
+	unlock func()
+	h      hash.Hash
+	n      int64
+	failed bool
+}
+
+func (c *cachingReader) Read(p []byte) (int, error) {
+	n, err := c.rc.Read(p)
+	if n > 0 && !c.failed {
+		if _, werr := c.tmp.Write(p[:n]); werr != nil {
+			message.Debugf("Unable to add %s to the UDS cache: %s", c.desc.Digest.Encoded(), werr.Error())
+			c.failed = true
+		}
+		c.h.Write(p[:n])
+		c.n += int64(n)
+	}
+	return n, err
+}
+
+// Close closes the fetched blob, adding it to the cache if it was read in full and matches its digest
+func (c *cachingReader) Close() error {
+	defer c.unlock()
+	defer os.Remove(c.tmp.Name())
+	err := c.rc.Close()
+	if c.failed || c.n != c.desc.Size {
+		c.tmp.Close()
+		return err
+	}
+	if commitErr := commit(c.tmp, c.h, c.desc.Digest.Encoded()); commitErr != nil {
+		message.Debugf("Unable to add %s to the UDS cache: %s", c.desc.Digest.Encoded(), commitErr.Error())
+	}
+	return err
+}
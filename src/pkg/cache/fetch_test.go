@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func pushBlob(t *testing.T, store *memory.Store, mediaType string, content []byte) ocispec.Descriptor {
+	t.Helper()
+	desc := ocispec.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(content), Size: int64(len(content))}
+	require.NoError(t, store.Push(context.Background(), desc, bytes.NewReader(content)))
+	return desc
+}
+
+// fetcherFunc serves blobs from a func, regardless of whether they match their descriptor
+type fetcherFunc func(desc ocispec.Descriptor) []byte
+
+func (f fetcherFunc) Fetch(_ context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f(desc))), nil
+}
+
+func TestFetch(t *testing.T) {
+	setupCache(t)
+	ctx := context.Background()
+	src := memory.New()
+	desc := pushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("image layer"))
+	stats := &Stats{}
+
+	// a partial read isn't cached
+	rc, err := Fetch(ctx, src, desc, stats)
+	require.NoError(t, err)
+	_, err = rc.Read(make([]byte, 5))
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.False(t, Exists(desc.Digest.Encoded()))
+
+	// a miss is cached once read in full
+	rc, err = Fetch(ctx, src, desc, stats)
+	require.NoError(t, err)
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, "image layer", string(b))
+	require.True(t, Exists(desc.Digest.Encoded()))
+
+	// a hit is read from the cache without fetching from src
+	rc, err = Fetch(ctx, memory.New(), desc, stats)
+	require.NoError(t, err)
+	b, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, "image layer", string(b))
+
+	require.Equal(t, 1, stats.Hits)
+	require.Equal(t, int64(11), stats.HitBytes)
+	require.Equal(t, 2, stats.Misses)
+	require.Equal(t, int64(22), stats.MissBytes)
+	require.Equal(t, "1 hits (11.00 Bytes), 2 misses (22.00 Bytes)", stats.String())
+}
+
+func TestFetchDoesNotCacheMismatchedContent(t *testing.T) {
+	setupCache(t)
+	// a registry serving content that doesn't match the descriptor's digest
+	src := fetcherFunc(func(_ ocispec.Descriptor) []byte { return []byte("image layer") })
+	wrong := ocispec.Descriptor{Digest: digest.FromBytes([]byte("image lay3r")), Size: 11}
+
+	rc, err := Fetch(context.Background(), src, wrong, nil)
+	require.NoError(t, err)
+	_, err = io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.False(t, Exists(wrong.Digest.Encoded()))
+}
+
+func TestNewTarget(t *testing.T) {
+	setupCache(t)
+	ctx := context.Background()
+	src := memory.New()
+	layer := pushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("image layer"))
+	cfg := pushBlob(t, src, ocispec.MediaTypeImageConfig, []byte("{}"))
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    cfg,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	require.NoError(t, err)
+	root := pushBlob(t, src, ocispec.MediaTypeImageManifest, manifest)
+	require.NoError(t, src.Tag(ctx, root, "0.0.1"))
+
+	stats := &Stats{}
+	_, err = oras.Copy(ctx, NewTarget(src, stats), "0.0.1", memory.New(), "", oras.DefaultCopyOptions)
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Misses)
+
+	_, err = oras.Copy(ctx, NewTarget(src, stats), "0.0.1", memory.New(), "", oras.DefaultCopyOptions)
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Hits)
+	for _, desc := range []ocispec.Descriptor{root, cfg, layer} {
+		require.True(t, Exists(desc.Digest.Encoded()))
+	}
+}
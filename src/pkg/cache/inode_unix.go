@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+//go:build unix
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode returns the inode number of a file, so a cached layer that is replaced is verified again
+func inode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino) //nolint:unconvert // Ino isn't a uint64 on every platform
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"io"
+	"os"
+
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+)
+
+// readOnly is the mode of cached layers, which are hardlinked into bundles and deploys and must not be changed through those links
+const readOnly = 0o444
+
+// place puts a cached layer at dst without copying it where the filesystem allows
+func place(layerCachePath, dst string) error {
+	// older cached layers may not be read-only yet
+	_ = os.Chmod(layerCachePath, readOnly)
+	return Place(layerCachePath, dst)
+}
+
+// Place puts the file at src at dst without copying it where the filesystem allows and src can't be changed through dst
+//
+// a reflink (copy-on-write clone) gives dst its own copy of src that shares its storage, a hardlink shares src itself so
+// it's only used when src is read-only, and src is copied otherwise
+func Place(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := reflink(src, dst); err == nil {
+		message.Debugf("Reflinked %s from %s", dst, src)
+		return nil
+	}
+	if info, err := os.Stat(src); err == nil && info.Mode().Perm()&0o222 == 0 {
+		if err := os.Link(src, dst); err == nil {
+			message.Debugf("Hardlinked %s from %s", dst, src)
+			return nil
+		}
+	}
+	return copyFile(src, dst)
+}
+
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		dstFile.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return dstFile.Close()
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/gofrs/flock"
+)
+
+// Lock takes a shared lock on the cache so it isn't pruned or cleared by another process until unlock is called
+//
+// layers are written to a temp file and renamed into place, so any number of processes can add and use layers while holding shared locks
+func Lock() (unlock func(), err error) {
+	return lock(filepath.Join(Dir(), config.UDSCacheLock), false)
+}
+
+// lockExclusive takes an exclusive lock on the cache, waiting for every other process using the cache to finish
+func lockExclusive() (unlock func(), err error) {
+	return lock(filepath.Join(Dir(), config.UDSCacheLock), true)
+}
+
+// lockOwners takes an exclusive lock on the record of which bundles use each layer
+func lockOwners() (unlock func(), err error) {
+	return lock(ownersPath()+".lock", true)
+}
+
+func lock(path string, exclusive bool) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	l := flock.New(path)
+	var err error
+	if exclusive {
+		err = l.Lock()
+	} else {
+		err = l.RLock()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return func() { _ = l.Unlock() }, nil
+}
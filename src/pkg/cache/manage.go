@@ -0,0 +1,535 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// KindLayer is a cached image layer shared between bundle creates and deploys
+	KindLayer = "layer"
+	// KindPackage is a blob of a bundle pulled into the cache
+	KindPackage = "package"
+)
+
+// Entry is a file in the UDS cache
+type Entry struct {
+	Digest   string
+	Kind     string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+	Bundles  []string
+}
+
+// PruneOptions are the limits the cache is pruned to, zero values are ignored
+type PruneOptions struct {
+	// OlderThan removes files that haven't been used for longer than this
+	OlderThan time.Duration
+	// MaxSize removes the least recently used files until the cache is no larger than this many bytes
+	MaxSize int64
+}
+
+var digestPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// Touch marks files in the cache as used now, keeping verified layers verified
+func Touch(paths ...string) error {
+	now := time.Now()
+	for _, path := range paths {
+		before, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := os.Chtimes(path, now, now); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := restamp(path, before); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Own records that a bundle uses the cached layers with the given digests
+func Own(bundle string, digests ...string) error {
+	if bundle == "" || len(digests) == 0 {
+		return nil
+	}
+	unlock, err := lockOwners()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	owners, err := readOwners()
+	if err != nil {
+		return err
+	}
+	for _, digest := range digests {
+		if !slices.Contains(owners[digest], bundle) {
+			owners[digest] = append(owners[digest], bundle)
+			slices.Sort(owners[digest])
+		}
+	}
+	return writeOwners(owners)
+}
+
+// List returns the layers and pulled bundle blobs in the cache, least recently used first
+func List() ([]Entry, error) {
+	layers, err := listLayers()
+	if err != nil {
+		return nil, err
+	}
+	pkgs, _, err := listPackages()
+	if err != nil {
+		return nil, err
+	}
+	entries := append(layers, pkgs...)
+	sortByLastUsed(entries)
+	return entries, nil
+}
+
+// Prune removes files from the cache, least recently used first, until it is within the limits of opts and returns the removed files
+//
+// bundles pulled into the cache are removed as a whole since the OCI store won't re-pull the rest of a bundle whose root manifest exists
+func Prune(opts PruneOptions) ([]Entry, error) {
+	unlock, err := lockExclusive()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// no other process is writing to the cache, so any temp files were left behind by killed processes
+	if err := removeTempFiles(filepath.Join(Dir(), config.UDSCacheLayers)); err != nil {
+		return nil, err
+	}
+
+	layers, err := listLayers()
+	if err != nil {
+		return nil, err
+	}
+	pkgs, index, err := listPackages()
+	if err != nil {
+		return nil, err
+	}
+	entries := append(layers, pkgs...)
+	sortByLastUsed(entries)
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	evicted := make(map[string]bool)
+	droppedBundles := make(map[string]bool)
+	var evict func(e Entry)
+	evict = func(e Entry) {
+		if evicted[e.Kind+e.Digest] {
+			return
+		}
+		evicted[e.Kind+e.Digest] = true
+		total -= e.Size
+		if e.Kind != KindPackage {
+			return
+		}
+		// drop the bundles using this blob along with every blob only they use
+		for _, bundle := range e.Bundles {
+			if droppedBundles[bundle] {
+				continue
+			}
+			droppedBundles[bundle] = true
+			for _, other := range pkgs {
+				if slices.Contains(other.Bundles, bundle) && allDropped(other.Bundles, droppedBundles) {
+					evict(other)
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		if opts.OlderThan > 0 && now.Sub(e.LastUsed) > opts.OlderThan {
+			evict(e)
+		}
+	}
+	for _, e := range entries {
+		if opts.MaxSize <= 0 || total <= opts.MaxSize {
+			break
+		}
+		evict(e)
+	}
+
+	// untag dropped bundles before removing their blobs so the index never references missing blobs
+	if len(droppedBundles) > 0 {
+		var kept []ocispec.Descriptor
+		for _, desc := range index.Manifests {
+			if !droppedBundles[refName(desc)] {
+				kept = append(kept, desc)
+			}
+		}
+		index.Manifests = kept
+		b, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(filepath.Join(Dir(), config.UDSCachePackages, ocispec.ImageIndexFile), b); err != nil {
+			return nil, err
+		}
+	}
+
+	var removed []Entry
+	for _, e := range entries {
+		if !evicted[e.Kind+e.Digest] {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		if e.Kind == KindLayer {
+			_ = os.Remove(e.Path + verifiedSuffix)
+		}
+		removed = append(removed, e)
+	}
+
+	unlockOwners, err := lockOwners()
+	if err != nil {
+		return removed, err
+	}
+	defer unlockOwners()
+	owners, err := readOwners()
+	if err != nil {
+		return removed, err
+	}
+	for _, e := range removed {
+		if e.Kind == KindLayer {
+			delete(owners, e.Digest)
+		}
+	}
+	return removed, writeOwners(owners)
+}
+
+// Clear removes every layer and pulled bundle from the cache
+func Clear() error {
+	unlock, err := lockExclusive()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, path := range []string{
+		filepath.Join(Dir(), config.UDSCacheLayers),
+		filepath.Join(Dir(), config.UDSCachePackages),
+		filepath.Join(Dir(), config.UDSCacheLayersOwners),
+	} {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enforce prunes the cache down to maxSize (ie. 10GB), it does nothing if maxSize is empty
+func Enforce(maxSize string) error {
+	if maxSize == "" {
+		return nil
+	}
+	size, err := ParseSize(maxSize)
+	if err != nil {
+		return err
+	}
+	removed, err := Prune(PruneOptions{MaxSize: size})
+	if len(removed) > 0 {
+		message.Debugf("Pruned %d files from the UDS cache to keep it under %s", len(removed), maxSize)
+	}
+	return err
+}
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-zA-Z]*)$`)
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a size in bytes with an optional unit (ie. 500MB, 10GiB)
+func ParseSize(size string) (int64, error) {
+	matches := sizePattern.FindStringSubmatch(strings.TrimSpace(size))
+	if matches == nil {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	unit, ok := sizeUnits[strings.ToLower(matches[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q, unknown unit %s", size, matches[2])
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	return int64(value * unit), nil
+}
+
+// ParseAge parses a duration, additionally accepting a number of days (ie. 30d)
+func ParseAge(age string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		value, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q: %w", age, err)
+		}
+		return time.Duration(value * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", age, err)
+	}
+	return d, nil
+}
+
+func sortByLastUsed(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+}
+
+// removeTempFiles removes the temp files of incomplete writes from a cache dir
+func removeTempFiles(dir string) error {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if strings.Contains(f.Name(), ".tmp-") {
+			if err := os.RemoveAll(filepath.Join(dir, f.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func allDropped(bundles []string, dropped map[string]bool) bool {
+	for _, bundle := range bundles {
+		if !dropped[bundle] {
+			return false
+		}
+	}
+	return true
+}
+
+// listDir lists the blobs in a cache dir, skipping anything that isn't named by its digest
+func listDir(dir, kind string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !digestPattern.MatchString(f.Name()) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry{
+			Digest:   f.Name(),
+			Kind:     kind,
+			Path:     filepath.Join(dir, f.Name()),
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// listLayers lists the cached image layers along with the bundles that use them
+func listLayers() ([]Entry, error) {
+	entries, err := listDir(filepath.Join(Dir(), config.UDSCacheLayers), KindLayer)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := readOwners()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Bundles = owners[entries[i].Digest]
+	}
+	return entries, nil
+}
+
+// listPackages lists the blobs of bundles pulled into the cache, along with the index of the cache's OCI store
+func listPackages() ([]Entry, ocispec.Index, error) {
+	dir := filepath.Join(Dir(), config.UDSCachePackages)
+	blobsDir := filepath.Join(dir, config.BlobsDir)
+	entries, err := listDir(blobsDir, KindPackage)
+	if err != nil {
+		return nil, ocispec.Index{}, err
+	}
+
+	var index ocispec.Index
+	b, err := os.ReadFile(filepath.Join(dir, ocispec.ImageIndexFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, ocispec.Index{}, err
+	} else if err == nil {
+		if err := json.Unmarshal(b, &index); err != nil {
+			return nil, ocispec.Index{}, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, ocispec.ImageIndexFile), err)
+		}
+	}
+
+	owners := make(map[string][]string)
+	for _, desc := range index.Manifests {
+		ref := refName(desc)
+		for _, digest := range reachable(blobsDir, desc) {
+			if !slices.Contains(owners[digest], ref) {
+				owners[digest] = append(owners[digest], ref)
+			}
+		}
+	}
+	for i := range entries {
+		entries[i].Bundles = owners[entries[i].Digest]
+	}
+	return entries, index, nil
+}
+
+// refName returns the ref a bundle was pulled with, falling back to its digest
+func refName(desc ocispec.Descriptor) string {
+	if ref := desc.Annotations[ocispec.AnnotationRefName]; ref != "" {
+		return ref
+	}
+	return desc.Digest.String()
+}
+
+// reachable returns the digests of the blobs reachable from root in an OCI store's blobs dir
+func reachable(blobsDir string, root ocispec.Descriptor) []string {
+	seen := make(map[string]bool)
+	var walk func(desc ocispec.Descriptor)
+	walk = func(desc ocispec.Descriptor) {
+		if desc.Digest.Validate() != nil {
+			return
+		}
+		digest := desc.Digest.Encoded()
+		if seen[digest] {
+			return
+		}
+		seen[digest] = true
+		if !hasSuccessors(desc) {
+			return
+		}
+		// blobs of components or images that weren't pulled are skipped
+		b, err := os.ReadFile(filepath.Join(blobsDir, digest))
+		if err != nil {
+			return
+		}
+		var node struct {
+			Config    *ocispec.Descriptor  `json:"config"`
+			Layers    []ocispec.Descriptor `json:"layers"`
+			Manifests []ocispec.Descriptor `json:"manifests"`
+		}
+		if err := json.Unmarshal(b, &node); err != nil {
+			return
+		}
+		if node.Config != nil {
+			walk(*node.Config)
+		}
+		for _, child := range append(node.Layers, node.Manifests...) {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	digests := make([]string, 0, len(seen))
+	for digest := range seen {
+		digests = append(digests, digest)
+	}
+	slices.Sort(digests)
+	return digests
+}
+
+// hasSuccessors returns true if a blob references other blobs
+func hasSuccessors(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex,
+		"application/vnd.docker.distribution.manifest.v2+json", "application/vnd.docker.distribution.manifest.list.v2+json":
+		return true
+	}
+	// a bundle's Zarf package manifests are untitled layers of its root manifest, and package images are indexed by images/index.json
+	title := desc.Annotations[ocispec.AnnotationTitle]
+	return (desc.MediaType == zoci.ZarfLayerMediaTypeBlob && title == "") || title == "images/index.json"
+}
+
+func ownersPath() string {
+	return filepath.Join(Dir(), config.UDSCacheLayersOwners)
+}
+
+func readOwners() (map[string][]string, error) {
+	owners := make(map[string][]string)
+	b, err := os.ReadFile(ownersPath())
+	if os.IsNotExist(err) {
+		return owners, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &owners); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ownersPath(), err)
+	}
+	return owners, nil
+}
+
+func writeOwners(owners map[string][]string) error {
+	b, err := json.Marshal(owners)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ownersPath(), b)
+}
+
+// writeFileAtomic writes a file through a temp file in the same dir so readers never see a partial write
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBlob writes content to dir named by its digest, last used the given time ago
+func writeBlob(t *testing.T, dir string, content []byte, age time.Duration) ocispec.Descriptor {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	desc := ocispec.Descriptor{Digest: digest.FromBytes(content), Size: int64(len(content))}
+	path := filepath.Join(dir, desc.Digest.Encoded())
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	used := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, used, used))
+	return desc
+}
+
+// writeBundle writes a pulled bundle with one Zarf package to the cache's OCI store, returning its blobs' digests
+func writeBundle(t *testing.T, ref string, layer []byte, age time.Duration) []string {
+	t.Helper()
+	dir := filepath.Join(Dir(), config.UDSCachePackages)
+	blobsDir := filepath.Join(dir, config.BlobsDir)
+
+	layerDesc := writeBlob(t, blobsDir, layer, age)
+	layerDesc.MediaType = zoci.ZarfLayerMediaTypeBlob
+	layerDesc.Annotations = map[string]string{ocispec.AnnotationTitle: "components/example.tar"}
+	pkgManifest, err := json.Marshal(ocispec.Manifest{Layers: []ocispec.Descriptor{layerDesc}})
+	require.NoError(t, err)
+	pkgDesc := writeBlob(t, blobsDir, pkgManifest, age)
+	pkgDesc.MediaType = zoci.ZarfLayerMediaTypeBlob
+
+	rootManifest, err := json.Marshal(ocispec.Manifest{Layers: []ocispec.Descriptor{pkgDesc}})
+	require.NoError(t, err)
+	rootDesc := writeBlob(t, blobsDir, rootManifest, age)
+	rootDesc.MediaType = ocispec.MediaTypeImageManifest
+	rootDesc.Annotations = map[string]string{ocispec.AnnotationRefName: ref}
+
+	var index ocispec.Index
+	if b, err := os.ReadFile(filepath.Join(dir, ocispec.ImageIndexFile)); err == nil {
+		require.NoError(t, json.Unmarshal(b, &index))
+	}
+	index.Manifests = append(index.Manifests, rootDesc)
+	b, err := json.Marshal(index)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ocispec.ImageIndexFile), b, 0o600))
+	return []string{layerDesc.Digest.Encoded(), pkgDesc.Digest.Encoded(), rootDesc.Digest.Encoded()}
+}
+
+func setupCache(t *testing.T) {
+	t.Helper()
+	cachePath := config.CommonOptions.CachePath
+	config.CommonOptions.CachePath = t.TempDir()
+	t.Cleanup(func() { config.CommonOptions.CachePath = cachePath })
+}
+
+func digests(entries []Entry) []string {
+	var ds []string
+	for _, e := range entries {
+		ds = append(ds, e.Digest)
+	}
+	return ds
+}
+
+func TestList(t *testing.T) {
+	setupCache(t)
+	layersDir := filepath.Join(Dir(), config.UDSCacheLayers)
+	newLayer := writeBlob(t, layersDir, []byte("new layer"), time.Hour)
+	oldLayer := writeBlob(t, layersDir, []byte("old layer"), 48*time.Hour)
+	require.NoError(t, Own("example", oldLayer.Digest.Encoded()))
+	require.NoError(t, Own("other", oldLayer.Digest.Encoded()))
+	// partial writes aren't listed
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir, "layer.tmp-123"), []byte("partial"), 0o600))
+	pulled := writeBundle(t, "localhost:888/example:0.0.1", []byte("pkg layer"), 24*time.Hour)
+
+	entries, err := List()
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	require.Equal(t, oldLayer.Digest.Encoded(), entries[0].Digest)
+	require.Equal(t, KindLayer, entries[0].Kind)
+	require.Equal(t, []string{"example", "other"}, entries[0].Bundles)
+	require.Equal(t, newLayer.Digest.Encoded(), entries[4].Digest)
+	require.Empty(t, entries[4].Bundles)
+
+	require.ElementsMatch(t, pulled, digests(entries[1:4]))
+	for _, e := range entries[1:4] {
+		require.Equal(t, KindPackage, e.Kind)
+		require.Equal(t, []string{"localhost:888/example:0.0.1"}, e.Bundles)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        PruneOptions
+		removed     int
+		keptBundles []string
+	}{
+		{
+			name:        "older than",
+			opts:        PruneOptions{OlderThan: 36 * time.Hour},
+			removed:     4,
+			keptBundles: []string{"localhost:888/new:0.0.1"},
+		},
+		{
+			name:        "within max size",
+			opts:        PruneOptions{MaxSize: 1 << 20},
+			removed:     0,
+			keptBundles: []string{"localhost:888/old:0.0.1", "localhost:888/new:0.0.1"},
+		},
+		{
+			name:        "max size",
+			opts:        PruneOptions{MaxSize: 1},
+			removed:     8,
+			keptBundles: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupCache(t)
+			layersDir := filepath.Join(Dir(), config.UDSCacheLayers)
+			oldLayer := writeBlob(t, layersDir, []byte("old layer"), 72*time.Hour)
+			writeBlob(t, layersDir, []byte("new layer"), time.Hour)
+			require.NoError(t, Own("example", oldLayer.Digest.Encoded()))
+			writeBundle(t, "localhost:888/old:0.0.1", []byte("old pkg layer"), 48*time.Hour)
+			newBundle := writeBundle(t, "localhost:888/new:0.0.1", []byte("new pkg layer"), 2*time.Hour)
+
+			removed, err := Prune(tc.opts)
+			require.NoError(t, err)
+			require.Len(t, removed, tc.removed)
+
+			b, err := os.ReadFile(filepath.Join(Dir(), config.UDSCachePackages, ocispec.ImageIndexFile))
+			require.NoError(t, err)
+			var index ocispec.Index
+			require.NoError(t, json.Unmarshal(b, &index))
+			var kept []string
+			for _, desc := range index.Manifests {
+				kept = append(kept, desc.Annotations[ocispec.AnnotationRefName])
+			}
+			require.Equal(t, tc.keptBundles, kept)
+
+			// every blob of the bundles that are kept is still in the cache
+			entries, err := List()
+			require.NoError(t, err)
+			if len(kept) == 1 {
+				require.Subset(t, digests(entries), newBundle)
+			}
+			for _, e := range removed {
+				require.NoFileExists(t, e.Path)
+			}
+
+			owners, err := readOwners()
+			require.NoError(t, err)
+			if tc.removed > 0 {
+				require.NotContains(t, owners, oldLayer.Digest.Encoded())
+			}
+		})
+	}
+}
+
+func TestClear(t *testing.T) {
+	setupCache(t)
+	layer := writeBlob(t, filepath.Join(Dir(), config.UDSCacheLayers), []byte("layer"), time.Hour)
+	require.NoError(t, Own("example", layer.Digest.Encoded()))
+	writeBundle(t, "localhost:888/example:0.0.1", []byte("pkg layer"), time.Hour)
+	require.NoError(t, os.WriteFile(filepath.Join(Dir(), config.CachedLogs), []byte("logs"), 0o600))
+
+	require.NoError(t, Clear())
+	entries, err := List()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	require.NoFileExists(t, ownersPath())
+	// anything else in the cache is left alone
+	require.FileExists(t, filepath.Join(Dir(), config.CachedLogs))
+}
+
+func TestParseSize(t *testing.T) {
+	testCases := []struct {
+		size     string
+		expected int64
+		wantErr  bool
+	}{
+		{size: "1024", expected: 1024},
+		{size: "500MB", expected: 500_000_000},
+		{size: "1.5 GB", expected: 1_500_000_000},
+		{size: "10GiB", expected: 10 << 30},
+		{size: "2kib", expected: 2048},
+		{size: "10XB", wantErr: true},
+		{size: "-1GB", wantErr: true},
+		{size: "", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.size, func(t *testing.T) {
+			size, err := ParseSize(tc.size)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, size)
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("30d")
+	require.NoError(t, err)
+	require.Equal(t, 30*24*time.Hour, age)
+
+	age, err = ParseAge("90m")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, age)
+
+	_, err = ParseAge("soon")
+	require.Error(t, err)
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+//go:build darwin
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflink clones src to dst with clonefile, supported by APFS
+func reflink(src, dst string) error {
+	if err := unix.Clonefile(src, dst, unix.CLONE_NOFOLLOW); err != nil {
+		return err
+	}
+	// clones keep the read-only mode of the cached layer, but are independent copies that can be changed
+	return os.Chmod(dst, 0o644)
+}
@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+//go:build !linux && !darwin
+
+// Package cache provides a primitive cache mechanism for bundle layers
+package cache
+
+import "errors"
+
+// reflink isn't supported on this platform
+func reflink(_, _ string) error {
+	return errors.New("reflinks are not supported on this platform")
+}
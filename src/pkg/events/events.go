@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package events writes a JSON-lines stream of events for long-running UDS operations
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MaskedValue replaces the value of sensitive variables
+const MaskedValue = "**sensitive**"
+
+var (
+	mu        sync.Mutex
+	out       io.Writer
+	closer    io.Closer
+	operation string
+	bundle    string
+	startedAt time.Time
+)
+
+// Start opens the event stream at path, or stdout if path is "-", and emits an operation.started event
+func Start(path, op, source string) error {
+	mu.Lock()
+	if path == "-" {
+		// everything else UDS prints goes to stderr, so stdout only has events
+		out, closer = os.Stdout, nil
+	} else {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, helpers.ReadWriteUser)
+		if err != nil {
+			mu.Unlock()
+			return err
+		}
+		out, closer = f, f
+	}
+	operation, bundle, startedAt = op, "", time.Now()
+	mu.Unlock()
+
+	Emit(types.Event{Type: types.EventOperationStarted, Source: source})
+	return nil
+}
+
+// Finish emits an error event if err isn't nil and an operation.finished event, then closes the event stream
+func Finish(err error) {
+	if !Enabled() {
+		return
+	}
+	mu.Lock()
+	duration := time.Since(startedAt)
+	mu.Unlock()
+
+	finished := types.Event{Type: types.EventOperationFinished, Result: types.StatusSucceeded, DurationSeconds: duration.Seconds()}
+	if err != nil {
+		Emit(types.Event{Type: types.EventError, Error: err.Error()})
+		finished.Result, finished.Error = types.StatusFailed, err.Error()
+	}
+	Emit(finished)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if closer != nil {
+		_ = closer.Close()
+	}
+	out, closer = nil, nil
+}
+
+// Enabled returns whether an event stream is open
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// SetBundle sets the name of the bundle on all following events
+func SetBundle(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	bundle = name
+}
+
+// Emit writes an event to the stream, filling in its time, operation and bundle; it is a no-op if no stream is open
+func Emit(e types.Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Operation = operation
+	if e.Bundle == "" {
+		e.Bundle = bundle
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		message.Debugf("Unable to marshal %s event: %s", e.Type, err.Error())
+		return
+	}
+	if _, err := out.Write(append(b, '\n')); err != nil {
+		message.Debugf("Unable to write %s event: %s", e.Type, err.Error())
+	}
+}
+
+// PackageStarted emits a package.started event
+func PackageStarted(pkg string) {
+	Emit(types.Event{Type: types.EventPackageStarted, Package: pkg})
+}
+
+// PackageFinished emits a package.finished event with the package's result
+func PackageFinished(pkg string, result types.BundleStatus, duration time.Duration, err error) {
+	e := types.Event{Type: types.EventPackageFinished, Package: pkg, Result: result, DurationSeconds: duration.Seconds()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	Emit(e)
+}
+
+// LayerPulled emits a layer.pulled event
+func LayerPulled(desc ocispec.Descriptor) {
+	Emit(types.Event{Type: types.EventLayerPulled, Digest: desc.Digest.String(), MediaType: desc.MediaType, Bytes: desc.Size})
+}
+
+// LayerPushed emits a layer.pushed event
+func LayerPushed(desc ocispec.Descriptor) {
+	Emit(types.Event{Type: types.EventLayerPushed, Digest: desc.Digest.String(), MediaType: desc.MediaType, Bytes: desc.Size})
+}
+
+// OverrideApplied emits an override.applied event, override values aren't included since they can come from sensitive variables
+func OverrideApplied(pkg, component, chart, path string) {
+	Emit(types.Event{Type: types.EventOverrideApplied, Package: pkg, Component: component, Chart: chart, Path: path})
+}
+
+// VariableResolved emits a variable.resolved event, masking the value of sensitive variables
+func VariableResolved(pkg, name, value string, sensitive bool) {
+	if sensitive {
+		value = MaskedValue
+	}
+	Emit(types.Event{Type: types.EventVariableResolved, Package: pkg, Variable: name, Value: value, Sensitive: sensitive})
+}
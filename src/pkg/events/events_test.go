@@ -0,0 +1,79 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func readEvents(t *testing.T, path string) []types.Event {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []types.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e types.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestEventStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, Start(path, "deploy", "uds-bundle-example.tar.zst"))
+	require.True(t, Enabled())
+
+	SetBundle("example")
+	PackageStarted("nginx")
+	VariableResolved("nginx", "DOMAIN", "uds.dev", false)
+	VariableResolved("nginx", "PASSWORD", "hunter2", true)
+	OverrideApplied("nginx", "component", "chart", "replicas")
+	Finish(errors.New("failed to deploy package nginx"))
+	require.False(t, Enabled())
+
+	// events after the stream is closed are dropped
+	PackageStarted("dropped")
+
+	events := readEvents(t, path)
+	var got []types.EventType
+	for _, e := range events {
+		got = append(got, e.Type)
+		require.Equal(t, "deploy", e.Operation)
+		require.False(t, e.Time.IsZero())
+	}
+	require.Equal(t, []types.EventType{
+		types.EventOperationStarted,
+		types.EventPackageStarted,
+		types.EventVariableResolved,
+		types.EventVariableResolved,
+		types.EventOverrideApplied,
+		types.EventError,
+		types.EventOperationFinished,
+	}, got)
+
+	require.Equal(t, "uds-bundle-example.tar.zst", events[0].Source)
+	require.Empty(t, events[0].Bundle)
+	require.Equal(t, "example", events[1].Bundle)
+	require.Equal(t, "uds.dev", events[2].Value)
+	require.Equal(t, MaskedValue, events[3].Value)
+	require.True(t, events[3].Sensitive)
+	require.Equal(t, types.StatusFailed, events[6].Result)
+	require.Equal(t, "failed to deploy package nginx", events[6].Error)
+}
+
+func TestEmitWithoutStream(t *testing.T) {
+	require.False(t, Enabled())
+	// none of these should panic without an open stream
+	Emit(types.Event{Type: types.EventError})
+	Finish(nil)
+}
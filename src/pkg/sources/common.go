@@ -5,7 +5,11 @@
 package sources
 
 import (
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/layout"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
 	"github.com/defenseunicorns/zarf/src/pkg/packager/filters"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/sources"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 )
 
@@ -25,6 +29,21 @@ func addNamespaceOverrides(pkg *zarfTypes.ZarfPackage, nsOverrides NamespaceOver
 	}
 }
 
+// validatePkgSignature verifies a bundled package's signature when the bundle sets a publicKey for the package
+//
+// signed packages without a publicKey are deployed without verifying their signature, as they were before, and --insecure
+// skips the verification like it does when the bundle is created
+func validatePkgSignature(dst *layout.PackagePaths, publicKeyPath string, pkgName string) error {
+	if publicKeyPath == "" {
+		return nil
+	}
+	if config.CommonOptions.Insecure {
+		message.Warnf("Skipping signature verification of package %s because --insecure is set", pkgName)
+		return nil
+	}
+	return sources.ValidatePackageSignature(dst, publicKeyPath)
+}
+
 // setAsYOLO sets the YOLO flag on a package and strips out all images and repos
 func setAsYOLO(pkg *zarfTypes.ZarfPackage) {
 	pkg.Metadata.YOLO = true
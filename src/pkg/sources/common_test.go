@@ -0,0 +1,58 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/test/keypair"
+	"github.com/defenseunicorns/zarf/src/pkg/layout"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/sources"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePkgSignature(t *testing.T) {
+	keys := t.TempDir()
+	privateKey, publicKey := keypair.Write(t, keys, "signer")
+	_, otherKey := keypair.Write(t, keys, "other")
+
+	tests := []struct {
+		name     string
+		signed   bool
+		key      string
+		insecure bool
+		err      string
+	}{
+		{name: "signed with the key", signed: true, key: publicKey},
+		{name: "unsigned", key: publicKey, err: sources.ErrPkgKeyButNoSig.Error()},
+		{name: "signed with another key", signed: true, key: otherKey, err: "package signature did not match the provided key"},
+		{name: "no publicKey in the bundle", signed: true},
+		{name: "--insecure skips the verification", key: otherKey, insecure: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insecure := config.CommonOptions.Insecure
+			config.CommonOptions.Insecure = tt.insecure
+			t.Cleanup(func() { config.CommonOptions.Insecure = insecure })
+
+			dst := layout.New(t.TempDir())
+			require.NoError(t, os.WriteFile(dst.ZarfYAML, []byte("kind: ZarfPackageConfig\nmetadata:\n  name: foo\n"), 0o600))
+			files := []string{config.ZarfYAML}
+			if tt.signed {
+				_, err := zarfUtils.CosignSignBlob(dst.ZarfYAML, filepath.Join(dst.Base, config.ZarfYAMLSignature), privateKey, keypair.Password)
+				require.NoError(t, err)
+				files = append(files, config.ZarfYAMLSignature)
+			}
+			dst.SetFromPaths(files)
+
+			err := validatePkgSignature(dst, tt.key, "foo")
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package sources contains Zarf packager sources
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/cache"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/layout"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/filters"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/sources"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// LayoutBundle is a package source for bundles in an OCI image layout directory that implements Zarf's packager.PackageSource
+//
+// the package's layers are hard linked into TmpDir rather than copied where possible, and the layout is only ever read
+type LayoutBundle struct {
+	PkgOpts        *zarfTypes.ZarfPackageOptions
+	PkgManifestSHA string
+	TmpDir         string
+	BundleLocation string
+	Pkg            types.Package
+	nsOverrides    NamespaceOverrideMap
+}
+
+// LoadPackage loads a Zarf package from a bundle in an OCI image layout
+func (l *LayoutBundle) LoadPackage(dst *layout.PackagePaths, filter filters.ComponentFilterStrategy, unarchiveAll bool) (zarfTypes.ZarfPackage, []string, error) {
+	packageSpinner := message.NewProgressSpinner("Loading bundled Zarf package: %s", l.Pkg.Name)
+	defer packageSpinner.Stop()
+
+	files, err := l.linkPkgFromBundle()
+	if err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	var pkg zarfTypes.ZarfPackage
+	if err = utils.ReadYAMLStrict(dst.ZarfYAML, &pkg); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	// if in dev mode and package is a zarf init config, return an empty package
+	if config.Dev && pkg.Kind == zarfTypes.ZarfInitConfig {
+		return zarfTypes.ZarfPackage{}, nil, nil
+	}
+
+	// filter pkg components and determine if its a partial pkg
+	filteredComps, isPartialPkg, err := handleFilter(pkg, filter)
+	if err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+	pkg.Components = filteredComps
+
+	dst.SetFromPaths(files)
+
+	if err := sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, isPartialPkg); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	if err := validatePkgSignature(dst, l.PkgOpts.PublicKeyPath, l.Pkg.Name); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	if unarchiveAll {
+		for _, component := range pkg.Components {
+			if err := dst.Components.Unarchive(component); err != nil {
+				if layout.IsNotLoaded(err) {
+					_, err := dst.Components.Create(component)
+					if err != nil {
+						return zarfTypes.ZarfPackage{}, nil, err
+					}
+				} else {
+					return zarfTypes.ZarfPackage{}, nil, err
+				}
+			}
+		}
+
+		if dst.SBOMs.Path != "" {
+			if err := dst.SBOMs.Unarchive(); err != nil {
+				return zarfTypes.ZarfPackage{}, nil, err
+			}
+		}
+	}
+	addNamespaceOverrides(&pkg, l.nsOverrides)
+
+	if config.Dev {
+		setAsYOLO(&pkg)
+	}
+
+	packageSpinner.Successf("Loaded bundled Zarf package: %s", l.Pkg.Name)
+	// ensure we're using the correct package name as specified by the bundle
+	pkg.Metadata.Name = l.Pkg.Name
+	return pkg, nil, err
+}
+
+// LoadPackageMetadata loads a Zarf package's metadata from a bundle in an OCI image layout
+func (l *LayoutBundle) LoadPackageMetadata(dst *layout.PackagePaths, _ bool, _ bool) (zarfTypes.ZarfPackage, []string, error) {
+	manifest, err := l.pkgManifest()
+	if err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	// copy zarf.yaml and checksums.txt out of the layout
+	var filePaths []string
+	for _, name := range []string{config.ZarfYAML, config.ChecksumsTxt} {
+		desc := manifest.Locate(name)
+		if oci.IsEmptyDescriptor(desc) {
+			if name == config.ZarfYAML {
+				return zarfTypes.ZarfPackage{}, nil, fmt.Errorf("%s not found in Zarf pkg %s", config.ZarfYAML, l.Pkg.Name)
+			}
+			continue
+		}
+		if err := helpers.CreatePathAndCopy(l.blobPath(desc), filepath.Join(dst.Base, name)); err != nil {
+			return zarfTypes.ZarfPackage{}, nil, err
+		}
+		filePaths = append(filePaths, name)
+	}
+
+	// deserialize zarf.yaml to grab checksum for validating pkg integrity
+	var pkg zarfTypes.ZarfPackage
+	if err := utils.ReadYAMLStrict(dst.ZarfYAML, &pkg); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	dst.SetFromPaths(filePaths)
+	if err := sources.ValidatePackageIntegrity(dst, pkg.Metadata.AggregateChecksum, true); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
+	// ensure we're using the correct package name as specified by the bundle
+	pkg.Metadata.Name = l.Pkg.Name
+	return pkg, nil, nil
+}
+
+// Collect doesn't need to be implemented
+func (l *LayoutBundle) Collect(_ string) (string, error) {
+	return "", fmt.Errorf("not implemented in %T", l)
+}
+
+// blobPath returns the path of a blob in the layout
+func (l *LayoutBundle) blobPath(desc ocispec.Descriptor) string {
+	return filepath.Join(l.BundleLocation, config.BlobsDir, desc.Digest.Encoded())
+}
+
+// pkgManifest reads the Zarf package's image manifest from the layout
+func (l *LayoutBundle) pkgManifest() (*oci.Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(l.BundleLocation, config.BlobsDir, l.PkgManifestSHA))
+	if err != nil {
+		return nil, fmt.Errorf("zarf package %s with manifest sha %s not found: %w", l.Pkg.Name, l.PkgManifestSHA, err)
+	}
+	var manifest oci.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// linkPkgFromBundle places the Zarf package's layers from the layout into TmpDir, returning their paths relative to TmpDir
+//
+// layers are reflinked where the filesystem supports it and otherwise copied, so nothing Zarf does in TmpDir can change
+// the layout (see cache.Place). The package's checksums are validated once the package is loaded
+func (l *LayoutBundle) linkPkgFromBundle() ([]string, error) {
+	manifest, err := l.pkgManifest()
+	if err != nil {
+		return nil, err
+	}
+	absLocation, err := filepath.Abs(l.BundleLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, layer := range manifest.Layers {
+		path := layer.Annotations[ocispec.AnnotationTitle]
+		cleanPath := filepath.Clean(path)
+		if strings.Contains(cleanPath, "..") {
+			// throw an error for dangerous looking paths
+			return nil, fmt.Errorf("invalid path detected: %s", path)
+		}
+
+		// layers of components that weren't bundled aren't in the layout
+		blob := filepath.Join(absLocation, config.BlobsDir, layer.Digest.Encoded())
+		info, err := os.Stat(blob)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if info.Size() != layer.Size {
+			return nil, fmt.Errorf("expected %s to be %d bytes, found %d", path, layer.Size, info.Size())
+		}
+
+		layerDst := filepath.Join(l.TmpDir, cleanPath)
+		if err := helpers.CreateDirectory(filepath.Dir(layerDst), 0700); err != nil {
+			return nil, err
+		}
+		if err := cache.Place(blob, layerDst); err != nil {
+			return nil, err
+		}
+		files = append(files, cleanPath)
+	}
+	return files, nil
+}
@@ -0,0 +1,151 @@
+package sources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/layout"
+	"github.com/defenseunicorns/zarf/src/pkg/packager/filters"
+	zarfTypes "github.com/defenseunicorns/zarf/src/types"
+	goyaml "github.com/goccy/go-yaml"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBlob writes b to the layout's blobs, returning a descriptor of it titled with name
+func writeBlob(t *testing.T, location string, name string, b []byte) ocispec.Descriptor {
+	t.Helper()
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    digest.FromBytes(b),
+		Size:      int64(len(b)),
+	}
+	if name != "" {
+		desc.Annotations = map[string]string{ocispec.AnnotationTitle: name}
+	}
+	blobs := filepath.Join(location, config.BlobsDir)
+	require.NoError(t, os.MkdirAll(blobs, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(blobs, desc.Digest.Encoded()), b, 0o600))
+	return desc
+}
+
+// writeLayoutPkg writes a Zarf package with a single component to an OCI image layout, letting edit change its
+// manifest's layers before the manifest is written, and returns a source of the package
+func writeLayoutPkg(t *testing.T, edit func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor) *LayoutBundle {
+	t.Helper()
+	location := t.TempDir()
+
+	component := []byte("component tarball")
+	componentSHA := sha256.Sum256(component)
+	checksums := []byte(fmt.Sprintf("%s components/foo.tar\n", hex.EncodeToString(componentSHA[:])))
+	checksumsSHA := sha256.Sum256(checksums)
+	zarfYAML, err := goyaml.Marshal(zarfTypes.ZarfPackage{
+		Kind: zarfTypes.ZarfPackageConfig,
+		Metadata: zarfTypes.ZarfMetadata{
+			Name:              "foo",
+			AggregateChecksum: hex.EncodeToString(checksumsSHA[:]),
+		},
+		Components: []zarfTypes.ZarfComponent{{Name: "foo", Required: &[]bool{true}[0]}},
+	})
+	require.NoError(t, err)
+
+	layers := []ocispec.Descriptor{
+		writeBlob(t, location, config.ZarfYAML, zarfYAML),
+		writeBlob(t, location, config.ChecksumsTxt, checksums),
+		writeBlob(t, location, "components/foo.tar", component),
+	}
+	if edit != nil {
+		layers = edit(location, layers)
+	}
+	manifest, err := json.Marshal(ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Layers: layers})
+	require.NoError(t, err)
+	manifestDesc := writeBlob(t, location, "", manifest)
+
+	return &LayoutBundle{
+		PkgOpts:        &zarfTypes.ZarfPackageOptions{},
+		PkgManifestSHA: manifestDesc.Digest.Encoded(),
+		TmpDir:         t.TempDir(),
+		BundleLocation: location,
+		Pkg:            types.Package{Name: "foo"},
+	}
+}
+
+func TestLayoutBundleLoadPackage(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor
+		err  string
+	}{
+		{name: "bundled package"},
+		{
+			name: "blob that doesn't match its size",
+			edit: func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor {
+				require.NoError(t, os.WriteFile(filepath.Join(location, config.BlobsDir, layers[2].Digest.Encoded()), []byte("truncated"), 0o600))
+				return layers
+			},
+			err: "expected components/foo.tar to be 17 bytes, found 9",
+		},
+		{
+			name: "title outside of the package",
+			edit: func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor {
+				return append(layers, writeBlob(t, location, "../../escape", []byte("escape")))
+			},
+			err: "invalid path detected: ../../escape",
+		},
+		{
+			name: "missing blob of a bundled component",
+			edit: func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor {
+				require.NoError(t, os.Remove(filepath.Join(location, config.BlobsDir, layers[2].Digest.Encoded())))
+				return layers
+			},
+			err: "unable to validate checksums",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := writeLayoutPkg(t, tt.edit)
+			dst := layout.New(t.TempDir())
+			l.TmpDir = dst.Base
+
+			pkg, _, err := l.LoadPackage(dst, filters.Empty(), false)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "foo", pkg.Metadata.Name)
+
+			// the layers are copies rather than links, so nothing Zarf writes to them can change the layout
+			manifest, err := l.pkgManifest()
+			require.NoError(t, err)
+			for _, layer := range manifest.Layers {
+				name := layer.Annotations[ocispec.AnnotationTitle]
+				info, err := os.Lstat(filepath.Join(dst.Base, name))
+				require.NoError(t, err)
+				require.True(t, info.Mode().IsRegular(), name)
+				blobInfo, err := os.Stat(l.blobPath(layer))
+				require.NoError(t, err)
+				require.False(t, os.SameFile(info, blobInfo), name)
+			}
+		})
+	}
+}
+
+func TestLinkPkgFromBundleSkipsUnbundledLayers(t *testing.T) {
+	// layers of components that weren't bundled are left out of the layout
+	l := writeLayoutPkg(t, func(location string, layers []ocispec.Descriptor) []ocispec.Descriptor {
+		require.NoError(t, os.Remove(filepath.Join(location, config.BlobsDir, layers[2].Digest.Encoded())))
+		return layers
+	})
+	files, err := l.linkPkgFromBundle()
+	require.NoError(t, err)
+	require.Equal(t, []string{config.ZarfYAML, config.ChecksumsTxt}, files)
+}
@@ -9,9 +9,10 @@ import (
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
+	"github.com/defenseunicorns/uds-cli/src/pkg/utils/boci"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	zarfSources "github.com/defenseunicorns/zarf/src/pkg/packager/sources"
-	"github.com/defenseunicorns/zarf/src/pkg/zoci"
 	zarfTypes "github.com/defenseunicorns/zarf/src/types"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -19,7 +20,16 @@ import (
 // New creates a new package source based on pkgLocation
 func New(pkgLocation string, pkg types.Package, opts zarfTypes.ZarfPackageOptions, sha string, nsOverrides NamespaceOverrideMap) (zarfSources.PackageSource, error) {
 	var source zarfSources.PackageSource
-	if strings.Contains(pkgLocation, "tar.zst") {
+	if utils.IsValidLayoutPath(pkgLocation) {
+		source = &LayoutBundle{
+			Pkg:            pkg,
+			PkgOpts:        &opts,
+			PkgManifestSHA: sha,
+			TmpDir:         opts.PackageSource,
+			BundleLocation: pkgLocation,
+			nsOverrides:    nsOverrides,
+		}
+	} else if strings.Contains(pkgLocation, "tar.zst") {
 		source = &TarballBundle{
 			Pkg:            pkg,
 			PkgOpts:        &opts,
@@ -33,7 +43,7 @@ func New(pkgLocation string, pkg types.Package, opts zarfTypes.ZarfPackageOption
 			Architecture: config.GetArch(),
 			OS:           oci.MultiOS,
 		}
-		remote, err := zoci.NewRemote(pkgLocation, platform)
+		remote, err := boci.NewRemote(pkgLocation, platform)
 		if err != nil {
 			return nil, err
 		}
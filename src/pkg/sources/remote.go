@@ -6,6 +6,7 @@ package sources
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -71,6 +72,10 @@ func (r *RemoteBundle) LoadPackage(dst *layout.PackagePaths, filter filters.Comp
 		return zarfTypes.ZarfPackage{}, nil, err
 	}
 
+	if err := validatePkgSignature(dst, r.PkgOpts.PublicKeyPath, r.Pkg.Name); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
 	if unarchiveAll {
 		for _, component := range pkg.Components {
 			if err := dst.Components.Unarchive(component); err != nil {
@@ -212,13 +217,17 @@ func (r *RemoteBundle) downloadPkgFromRemoteBundle() ([]ocispec.Descriptor, erro
 				digest := manifestLayer.Digest.Encoded()
 
 				// if it's an image layer and is in the cache, use it
+				inCache := false
 				if strings.Contains(manifestLayer.Annotations[ocispec.AnnotationTitle], config.BlobsDir) && cache.Exists(digest) {
 					dst := filepath.Join(r.TmpDir, "images", config.BlobsDir)
 					err = cache.Use(digest, dst)
-					if err != nil {
+					// corrupt layers are dropped from the cache and pulled again
+					if err != nil && !errors.Is(err, cache.ErrCorrupt) {
 						return nil, err
 					}
-				} else {
+					inCache = err == nil
+				}
+				if !inCache {
 					// not in cache, so pull
 					layersToPull = append(layersToPull, manifestLayer)
 					estimatedBytes += manifestLayer.Size
@@ -74,6 +74,10 @@ func (t *TarballBundle) LoadPackage(dst *layout.PackagePaths, filter filters.Com
 		return zarfTypes.ZarfPackage{}, nil, err
 	}
 
+	if err := validatePkgSignature(dst, t.PkgOpts.PublicKeyPath, t.Pkg.Name); err != nil {
+		return zarfTypes.ZarfPackage{}, nil, err
+	}
+
 	if unarchiveAll {
 		for _, component := range pkg.Components {
 			if err := dst.Components.Unarchive(component); err != nil {
@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package state manages the records of deployed bundles that are stored in the cluster
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/cluster"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// SecretPrefix is the prefix of the secrets that hold bundle state
+	SecretPrefix = "uds-bundle-"
+
+	// BundleInfoLabel is the label applied to the secrets that hold bundle state
+	BundleInfoLabel = "uds-bundle-deploy-info"
+
+	// stateKey is the key of the bundle's state in its secret
+	stateKey = "data"
+
+	// configKeyKey is the key of the bundle's config hash key in its secret
+	configKeyKey = "configKey"
+)
+
+// ErrNotFound is returned when a bundle has no state recorded in the cluster
+var ErrNotFound = errors.New("bundle state not found")
+
+// Client reads and writes bundle state in the cluster
+type Client struct {
+	cluster *cluster.Cluster
+}
+
+// NewClient creates a new state client connected to the current cluster
+func NewClient() (*Client, error) {
+	c, err := cluster.NewCluster()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cluster: c}, nil
+}
+
+// Get returns the state of a deployed bundle
+func (c *Client) Get(ctx context.Context, bundleName string) (*types.BundleState, error) {
+	secret, err := c.cluster.GetSecret(ctx, cluster.ZarfNamespaceName, SecretPrefix+bundleName)
+	if kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, bundleName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state types.BundleState
+	if err := json.Unmarshal(secret.Data[stateKey], &state); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the state of bundle %s: %w", bundleName, err)
+	}
+	return &state, nil
+}
+
+// List returns the state of all bundles deployed to the cluster sorted by name
+func (c *Client) List(ctx context.Context) ([]types.BundleState, error) {
+	secrets, err := c.cluster.GetSecretsWithLabel(ctx, cluster.ZarfNamespaceName, BundleInfoLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	states := []types.BundleState{}
+	for _, secret := range secrets.Items {
+		if _, ok := secret.Data[stateKey]; !ok || !strings.HasPrefix(secret.Name, SecretPrefix) {
+			continue
+		}
+		var state types.BundleState
+		if err := json.Unmarshal(secret.Data[stateKey], &state); err != nil {
+			errs = append(errs, fmt.Errorf("unable to unmarshal the secret %s/%s", secret.Namespace, secret.Name))
+			continue
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states, errors.Join(errs...)
+}
+
+// Save creates or updates the state of a bundle in the cluster, keeping its config key
+func (c *Client) Save(ctx context.Context, state *types.BundleState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	secret := c.cluster.GenerateSecret(cluster.ZarfNamespaceName, SecretPrefix+state.Name, corev1.SecretTypeOpaque)
+	secret.Labels[BundleInfoLabel] = state.Name
+	secret.Data = map[string][]byte{stateKey: data}
+	if existing, err := c.cluster.GetSecret(ctx, cluster.ZarfNamespaceName, SecretPrefix+state.Name); err == nil {
+		if key, ok := existing.Data[configKeyKey]; ok {
+			secret.Data[configKeyKey] = key
+		}
+	}
+	if _, err := c.cluster.CreateOrUpdateSecret(ctx, secret); err != nil {
+		return fmt.Errorf("failed to record the state of bundle %s: %w", state.Name, err)
+	}
+	return nil
+}
+
+// FindConfigKey returns the random key that a bundle's package configs are hashed with, or nil if the bundle doesn't
+// have one yet. Unlike ConfigKey it never writes to the cluster
+func (c *Client) FindConfigKey(ctx context.Context, bundleName string) ([]byte, error) {
+	secret, err := c.cluster.GetSecret(ctx, cluster.ZarfNamespaceName, SecretPrefix+bundleName)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return secret.Data[configKeyKey], nil
+}
+
+// ConfigKey returns the random key that a bundle's package configs are hashed with, saving key as the bundle's key if
+// it doesn't have one yet
+//
+// the key is kept in the bundle's state secret rather than its state, so the hashes recorded in the state and in deploy
+// plans can't be brute-forced for the variable values they were made from without reading the secret itself
+func (c *Client) ConfigKey(ctx context.Context, bundleName string, key []byte) ([]byte, error) {
+	secret, err := c.cluster.GetSecret(ctx, cluster.ZarfNamespaceName, SecretPrefix+bundleName)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		if existing, ok := secret.Data[configKeyKey]; ok {
+			return existing, nil
+		}
+	} else {
+		secret = c.cluster.GenerateSecret(cluster.ZarfNamespaceName, SecretPrefix+bundleName, corev1.SecretTypeOpaque)
+		secret.Labels[BundleInfoLabel] = bundleName
+	}
+
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	secret.Data[configKeyKey] = key
+	if _, err := c.cluster.CreateOrUpdateSecret(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to record the config key of bundle %s: %w", bundleName, err)
+	}
+	return key, nil
+}
+
+// NewConfigKey returns a new random config key
+func NewConfigKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Delete removes the state of a bundle from the cluster
+func (c *Client) Delete(ctx context.Context, bundleName string) error {
+	secret := c.cluster.GenerateSecret(cluster.ZarfNamespaceName, SecretPrefix+bundleName, corev1.SecretTypeOpaque)
+	return c.cluster.DeleteSecret(ctx, secret)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const ociScheme = "oci://"
+
+// ValidateMirrors checks that every mirror rule has a prefix and at least one mirror
+func ValidateMirrors(mirrors []types.RegistryMirror) error {
+	for i, m := range mirrors {
+		if strings.TrimPrefix(m.Prefix, ociScheme) == "" {
+			return fmt.Errorf("registry_mirrors[%d].prefix is required", i)
+		}
+		if len(m.Mirrors) == 0 {
+			return fmt.Errorf("registry_mirrors[%d] (%s) must have at least one mirror", i, m.Prefix)
+		}
+	}
+	return nil
+}
+
+// MirrorRefs returns the refs to try for ref in order, rewriting the longest matching prefix with each of its mirrors
+//
+// prefixes only match whole path segments, so ghcr.io/org doesn't match ghcr.io/org-evil or ghcr.io.attacker.com
+// the original ref is tried last unless the matching rule skips the upstream, a ref with no matching rule is returned as is
+func MirrorRefs(ref string, mirrors []types.RegistryMirror) []string {
+	scheme := ""
+	if strings.HasPrefix(ref, ociScheme) {
+		scheme = ociScheme
+	}
+	trimmed := strings.TrimPrefix(ref, ociScheme)
+
+	var rule *types.RegistryMirror
+	var prefix string
+	for i, m := range mirrors {
+		p := strings.TrimSuffix(strings.TrimPrefix(m.Prefix, ociScheme), "/")
+		if p == "" || len(p) <= len(prefix) {
+			continue
+		}
+		if trimmed == p || strings.HasPrefix(trimmed, p+"/") {
+			rule, prefix = &mirrors[i], p
+		}
+	}
+	if rule == nil {
+		return []string{ref}
+	}
+
+	var refs []string
+	for _, mirror := range rule.Mirrors {
+		mirror = strings.TrimSuffix(strings.TrimPrefix(mirror, ociScheme), "/")
+		refs = append(refs, scheme+mirror+strings.TrimPrefix(trimmed, prefix))
+	}
+	if !rule.SkipUpstream {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// NewMirroredRemote creates a remote for ref, or for the first of its mirrors that resolves when the uds-config has a matching mirror rule
+func NewMirroredRemote(ctx context.Context, ref string, platform ocispec.Platform) (*zoci.Remote, error) {
+	refs := MirrorRefs(ref, config.CommonOptions.RegistryMirrors)
+	if len(refs) == 1 && refs[0] == ref {
+		return NewRemote(ref, platform)
+	}
+
+	var err error
+	for _, r := range refs {
+		var remote *zoci.Remote
+		remote, err = NewRemote(r, platform)
+		if err == nil {
+			_, err = remote.ResolveRoot(ctx)
+		}
+		if err == nil {
+			message.Debugf("Using %s for %s", r, ref)
+			return remote, nil
+		}
+		message.Debugf("%s: not found: %s", r, err.Error())
+	}
+	return nil, fmt.Errorf("unable to resolve %s from any of %s: %w", ref, strings.Join(refs, ", "), err)
+}
@@ -0,0 +1,77 @@
+package boci
+
+import (
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorRefs(t *testing.T) {
+	mirrors := []types.RegistryMirror{
+		{Prefix: "ghcr.io/", Mirrors: []string{"registry.enclave.mil/mirror/", "backup.enclave.mil/mirror/"}},
+		{Prefix: "oci://ghcr.io/defenseunicorns/", Mirrors: []string{"registry.enclave.mil/du/"}, SkipUpstream: true},
+		{Prefix: "ghcr.io/org", Mirrors: []string{"registry.enclave.mil/org"}, SkipUpstream: true},
+		{Prefix: "registry.io", Mirrors: []string{"registry.enclave.mil/registry"}, SkipUpstream: true},
+	}
+	tests := []struct {
+		name     string
+		ref      string
+		expected []string
+	}{
+		{
+			name:     "no matching rule",
+			ref:      "oci://docker.io/library/example:0.0.1",
+			expected: []string{"oci://docker.io/library/example:0.0.1"},
+		},
+		{
+			name: "mirrors in order before the upstream",
+			ref:  "ghcr.io/example/bundle:0.0.1",
+			expected: []string{
+				"registry.enclave.mil/mirror/example/bundle:0.0.1",
+				"backup.enclave.mil/mirror/example/bundle:0.0.1",
+				"ghcr.io/example/bundle:0.0.1",
+			},
+		},
+		{
+			name:     "longest prefix wins and keeps the scheme",
+			ref:      "oci://ghcr.io/defenseunicorns/packages/uds/bundles/example:0.0.1",
+			expected: []string{"oci://registry.enclave.mil/du/packages/uds/bundles/example:0.0.1"},
+		},
+		{
+			name:     "prefix without a trailing slash",
+			ref:      "ghcr.io/org/bundle:0.0.1",
+			expected: []string{"registry.enclave.mil/org/bundle:0.0.1"},
+		},
+		{
+			name: "prefix only matches whole path segments",
+			ref:  "ghcr.io/org-evil/bundle:0.0.1",
+			expected: []string{
+				"registry.enclave.mil/mirror/org-evil/bundle:0.0.1",
+				"backup.enclave.mil/mirror/org-evil/bundle:0.0.1",
+				"ghcr.io/org-evil/bundle:0.0.1",
+			},
+		},
+		{
+			name:     "registry prefix doesn't match another registry",
+			ref:      "oci://registry.io.attacker.com/bundle:0.0.1",
+			expected: []string{"oci://registry.io.attacker.com/bundle:0.0.1"},
+		},
+		{
+			name:     "registry prefix",
+			ref:      "oci://registry.io/bundle:0.0.1",
+			expected: []string{"oci://registry.enclave.mil/registry/bundle:0.0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, MirrorRefs(tt.ref, mirrors))
+		})
+	}
+}
+
+func TestValidateMirrors(t *testing.T) {
+	require.NoError(t, ValidateMirrors(nil))
+	require.ErrorContains(t, ValidateMirrors([]types.RegistryMirror{{Mirrors: []string{"registry.enclave.mil/"}}}), "registry_mirrors[0].prefix is required")
+	require.ErrorContains(t, ValidateMirrors([]types.RegistryMirror{{Prefix: "ghcr.io/"}}), "must have at least one mirror")
+}
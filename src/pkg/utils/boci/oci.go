@@ -16,6 +16,7 @@ import (
 
 	"github.com/defenseunicorns/pkg/oci"
 	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/pkg/events"
 	"github.com/defenseunicorns/uds-cli/src/pkg/utils"
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
@@ -28,6 +29,7 @@ import (
 	"oras.land/oras-go/v2/content"
 	ocistore "oras.land/oras-go/v2/content/oci"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
 )
 
 // ToOCIStore takes an arbitrary type, typically a struct, marshals it into JSON and store it in a local OCI store
@@ -77,6 +79,10 @@ func ToOCIRemote(t any, mediaType string, remote *oci.OrasRemote) (*ocispec.Desc
 func CreateCopyOpts(layersToPull []ocispec.Descriptor, concurrency int) oras.CopyOptions {
 	var copyOpts oras.CopyOptions
 	copyOpts.Concurrency = concurrency
+	copyOpts.PostCopy = func(_ context.Context, desc ocispec.Descriptor) error {
+		events.LayerPulled(desc)
+		return nil
+	}
 	var shas []string
 	for _, layer := range layersToPull {
 		if len(layer.Digest.String()) > 0 {
@@ -195,22 +201,75 @@ func pushIndex(index *ocispec.Index, remote *oci.OrasRemote, ref string) error {
 	return nil
 }
 
-// UpdateIndex updates or creates a new OCI index based on the index arg, then pushes to the remote OCI repo
-func UpdateIndex(index *ocispec.Index, remote *oci.OrasRemote, bundle *types.UDSBundle, newManifestDesc ocispec.Descriptor) error {
-	var newIndex *ocispec.Index
-	ref := bundle.Metadata.Version
-	if index == nil {
-		newIndex = createIndex(bundle, newManifestDesc)
-	} else {
-		newIndex = addToIndex(index, bundle, newManifestDesc)
+// ResolveTags returns the tags to publish a bundle with, defaulting to the bundle's version, and ensures they are valid tags
+func ResolveTags(tags []string, metadata types.UDSMetadata) ([]string, error) {
+	if len(tags) == 0 {
+		if metadata.Version == "" {
+			return nil, errors.New("version is required for publishing")
+		}
+		tags = []string{metadata.Version}
 	}
-	err := pushIndex(newIndex, remote, ref)
-	if err != nil {
-		return err
+	var resolved []string
+	for _, tag := range tags {
+		ref := registry.Reference{Reference: tag}
+		if err := ref.ValidateReferenceAsTag(); err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+		if !slices.Contains(resolved, tag) {
+			resolved = append(resolved, tag)
+		}
+	}
+	return resolved, nil
+}
+
+// GetIndexes gets the existing OCI index for each tag, see GetIndex
+func GetIndexes(remote *oci.OrasRemote, tags []string) (map[string]*ocispec.Index, error) {
+	indexes := make(map[string]*ocispec.Index, len(tags))
+	for _, tag := range tags {
+		index, err := GetIndex(remote, tag)
+		if err != nil {
+			return nil, err
+		}
+		indexes[tag] = index
+	}
+	return indexes, nil
+}
+
+// UpdateIndexes updates the OCI index of every tag with the bundle's root manifest, so each tag resolves to the new root manifest for
+// the bundle's arch while keeping the root manifests of other archs that were already published with that tag
+func UpdateIndexes(indexes map[string]*ocispec.Index, remote *oci.OrasRemote, bundle *types.UDSBundle, newManifestDesc ocispec.Descriptor) error {
+	return UpdateIndexesWithRoots(indexes, remote, []PlatformRoot{{Bundle: bundle, Desc: newManifestDesc}})
+}
+
+// PlatformRoot is the root manifest of a bundle for one arch along with the bundle it was created from
+type PlatformRoot struct {
+	Bundle *types.UDSBundle
+	Desc   ocispec.Descriptor
+}
+
+// UpdateIndexesWithRoots updates the OCI index of every tag with the root manifest of each arch in roots, keeping the root manifests
+// of other archs that were already published with that tag
+func UpdateIndexesWithRoots(indexes map[string]*ocispec.Index, remote *oci.OrasRemote, roots []PlatformRoot) error {
+	for tag, index := range indexes {
+		if err := pushIndex(indexWithRoots(index, roots), remote, tag); err != nil {
+			return fmt.Errorf("unable to tag the bundle with %s: %w", tag, err)
+		}
 	}
 	return nil
 }
 
+// indexWithRoots adds or replaces the root manifest of each arch in roots in an OCI index, creating the index if it's nil
+func indexWithRoots(index *ocispec.Index, roots []PlatformRoot) *ocispec.Index {
+	for _, root := range roots {
+		if index == nil {
+			index = createIndex(root.Bundle, root.Desc)
+		} else {
+			index = addToIndex(index, root.Bundle, root.Desc)
+		}
+	}
+	return index
+}
+
 // GetIndex gets the OCI index from a remote repository if the index exists, otherwise returns a
 func GetIndex(remote *oci.OrasRemote, ref string) (*ocispec.Index, error) {
 	ctx := context.TODO()
@@ -0,0 +1,84 @@
+package boci
+
+import (
+	"testing"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTags(t *testing.T) {
+	metadata := types.UDSMetadata{Name: "example", Version: "0.0.1"}
+	tests := []struct {
+		name     string
+		tags     []string
+		metadata types.UDSMetadata
+		expected []string
+		err      string
+	}{
+		{name: "defaults to the version", metadata: metadata, expected: []string{"0.0.1"}},
+		{name: "custom tags", tags: []string{"latest", "0.0.1"}, metadata: metadata, expected: []string{"latest", "0.0.1"}},
+		{name: "duplicate tags", tags: []string{"latest", "latest"}, metadata: metadata, expected: []string{"latest"}},
+		{name: "invalid tag", tags: []string{"not/a:tag"}, metadata: metadata, err: `invalid tag "not/a:tag"`},
+		{name: "no version", metadata: types.UDSMetadata{Name: "example"}, err: "version is required for publishing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := ResolveTags(tt.tags, tt.metadata)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tags)
+		})
+	}
+}
+
+func TestIndexWithRoots(t *testing.T) {
+	root := func(arch string, content string) PlatformRoot {
+		return PlatformRoot{
+			Bundle: &types.UDSBundle{Metadata: types.UDSMetadata{Name: "example", Architecture: arch}},
+			Desc:   ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString(content), Size: int64(len(content))},
+		}
+	}
+	entry := func(r PlatformRoot) ocispec.Descriptor {
+		return ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    r.Desc.Digest,
+			Size:      r.Desc.Size,
+			Platform:  &ocispec.Platform{Architecture: r.Bundle.Metadata.Architecture, OS: oci.MultiOS},
+		}
+	}
+	amd64, arm64 := root("amd64", "amd64 root"), root("arm64", "arm64 root")
+	oldAMD64, ppc64le := root("amd64", "old amd64 root"), root("ppc64le", "ppc64le root")
+
+	tests := []struct {
+		name     string
+		index    *ocispec.Index
+		roots    []PlatformRoot
+		expected []ocispec.Descriptor
+	}{
+		{
+			name:     "every arch is added to a new index",
+			roots:    []PlatformRoot{amd64, arm64},
+			expected: []ocispec.Descriptor{entry(amd64), entry(arm64)},
+		},
+		{
+			name:     "archs are replaced and archs only in the destination are kept",
+			index:    indexWithRoots(nil, []PlatformRoot{oldAMD64, ppc64le}),
+			roots:    []PlatformRoot{amd64, arm64},
+			expected: []ocispec.Descriptor{entry(amd64), entry(ppc64le), entry(arm64)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := indexWithRoots(tt.index, tt.roots)
+			require.Equal(t, ocispec.MediaTypeImageIndex, index.MediaType)
+			require.Equal(t, tt.expected, index.Manifests)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// artifactTypes maps the short names of the artifact types that can be attached to bundles to their artifact types
+var artifactTypes = map[string]string{
+	"signature":     config.BundleSignatureArtifactType,
+	"sbom":          config.BundleSBOMArtifactType,
+	"attestation":   config.BundleAttestationArtifactType,
+	"release-notes": config.BundleReleaseNotesArtifactType,
+}
+
+// ArtifactType returns the artifact type for one of the short names (signature, sbom, attestation or release-notes), or name itself if it is already a media type
+func ArtifactType(name string) (string, error) {
+	if artifactType, ok := artifactTypes[name]; ok {
+		return artifactType, nil
+	}
+	if strings.Contains(name, "/") {
+		return name, nil
+	}
+	return "", fmt.Errorf("invalid artifact type %q, must be a media type or one of signature, sbom, attestation or release-notes", name)
+}
+
+// ShortArtifactType returns the short name of a bundle artifact type, or the artifact type itself if it has no short name
+func ShortArtifactType(artifactType string) string {
+	for name, t := range artifactTypes {
+		if t == artifactType {
+			return name
+		}
+	}
+	return artifactType
+}
+
+// subjectOf returns the descriptor of a manifest to use as the subject of its referrers, without the annotations and platform it may have in an index
+func subjectOf(desc ocispec.Descriptor) ocispec.Descriptor {
+	return ocispec.Descriptor{MediaType: desc.MediaType, Digest: desc.Digest, Size: desc.Size}
+}
+
+// PushFiles pushes files to dst as layers titled with their file names
+func PushFiles(ctx context.Context, dst content.Storage, files []string) ([]ocispec.Descriptor, error) {
+	var layers []ocispec.Descriptor
+	for _, path := range files {
+		desc, err := pushFile(ctx, dst, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to push %s: %w", path, err)
+		}
+		layers = append(layers, desc)
+	}
+	return layers, nil
+}
+
+func pushFile(ctx context.Context, dst content.Storage, path string) (ocispec.Descriptor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	dgst, err := digest.FromReader(f)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageLayer,
+		Digest:      dgst,
+		Size:        info.Size(),
+		Annotations: map[string]string{ocispec.AnnotationTitle: filepath.Base(path)},
+	}
+	if exists, err := dst.Exists(ctx, desc); err == nil && exists {
+		return desc, nil
+	}
+	if err := dst.Push(ctx, desc, f); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// Attach attaches an artifact made of layers that are already in dst to subject as an OCI referrer
+//
+// registries that don't support the referrers API are updated using the referrers tag schema instead
+func Attach(ctx context.Context, dst content.Pusher, subject ocispec.Descriptor, artifactType string, layers []ocispec.Descriptor) (ocispec.Descriptor, error) {
+	s := subjectOf(subject)
+	return oras.PackManifest(ctx, dst, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Subject:             &s,
+		Layers:              layers,
+		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339)},
+	})
+}
+
+// Referrers returns the artifacts attached to subject, oldest first
+func Referrers(ctx context.Context, src content.ReadOnlyGraphStorage, subject ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, src, subjectOf(subject), "")
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(referrers, func(a, b ocispec.Descriptor) int {
+		if c := strings.Compare(a.Annotations[ocispec.AnnotationCreated], b.Annotations[ocispec.AnnotationCreated]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Digest.String(), b.Digest.String())
+	})
+	return referrers, nil
+}
+
+// FetchReferrer fetches the manifest of an artifact attached to a bundle
+func FetchReferrer(ctx context.Context, src content.Fetcher, desc ocispec.Descriptor) (ocispec.Manifest, error) {
+	var manifest ocispec.Manifest
+	b, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+// CopyReferrers copies the referrers of subject and their contents from src to dst
+//
+// the subject itself isn't copied, it must already be in dst or be copied to it separately
+func CopyReferrers(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, subject ocispec.Descriptor, referrers []ocispec.Descriptor) error {
+	opts := oras.DefaultCopyGraphOptions
+	opts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := content.Successors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		return slices.DeleteFunc(successors, func(d ocispec.Descriptor) bool { return d.Digest == subject.Digest }), nil
+	}
+	for _, desc := range referrers {
+		if err := oras.CopyGraph(ctx, src, dst, desc, opts); err != nil {
+			return fmt.Errorf("failed to copy referrer %s: %w", desc.Digest, err)
+		}
+		message.Debugf("Copied %s referrer %s", ShortArtifactType(desc.ArtifactType), desc.Digest)
+	}
+	return nil
+}
+
+// AttachSignature attaches the bundle's signature layer, which must already be in dst, to its root manifest unless it is already attached
+func AttachSignature(ctx context.Context, dst oras.GraphTarget, rootDesc ocispec.Descriptor, sigDesc ocispec.Descriptor) error {
+	referrers, err := Referrers(ctx, dst, rootDesc)
+	if err != nil {
+		return err
+	}
+	for _, desc := range referrers {
+		if desc.ArtifactType != config.BundleSignatureArtifactType {
+			continue
+		}
+		manifest, err := FetchReferrer(ctx, dst, desc)
+		if err != nil {
+			return err
+		}
+		for _, layer := range manifest.Layers {
+			if layer.Digest == sigDesc.Digest {
+				return nil
+			}
+		}
+	}
+	desc, err := Attach(ctx, dst, rootDesc, config.BundleSignatureArtifactType, []ocispec.Descriptor{sigDesc})
+	if err != nil {
+		return fmt.Errorf("failed to attach the bundle's signature: %w", err)
+	}
+	message.Debug("Attached signature:", message.JSONValue(desc))
+	return nil
+}
+
+// SaveReferrer writes the layers of an artifact attached to a bundle to dir, named by their titles
+func SaveReferrer(ctx context.Context, src content.Fetcher, manifest ocispec.Manifest, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, layer := range manifest.Layers {
+		name := filepath.Base(layer.Annotations[ocispec.AnnotationTitle])
+		if name == "." || name == string(filepath.Separator) || name == "" {
+			name = layer.Digest.Encoded()
+		}
+		b, err := content.FetchAll(ctx, src, layer)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package boci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// pushTestBundle pushes a minimal bundle root manifest to store
+func pushTestBundle(t *testing.T, store content.Storage) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}"))
+	require.NoError(t, store.Push(ctx, configDesc, bytes.NewReader([]byte("{}"))))
+	manifest := ocispec.Manifest{MediaType: ocispec.MediaTypeImageManifest, Config: configDesc, Layers: []ocispec.Descriptor{}}
+	manifest.SchemaVersion = 2
+	b, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	rootDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, b)
+	require.NoError(t, store.Push(ctx, rootDesc, bytes.NewReader(b)))
+	rootDesc.Annotations = map[string]string{ocispec.AnnotationRefName: "0.0.1"}
+	return rootDesc
+}
+
+func TestAttachAndCopyReferrers(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	rootDesc := pushTestBundle(t, src)
+
+	dir := t.TempDir()
+	sbom := filepath.Join(dir, "sboms.tar")
+	require.NoError(t, os.WriteFile(sbom, []byte("sbom"), 0o600))
+	notes := filepath.Join(dir, "NOTES.md")
+	require.NoError(t, os.WriteFile(notes, []byte("# 0.0.1"), 0o600))
+
+	artifactType, err := ArtifactType("sbom")
+	require.NoError(t, err)
+	layers, err := PushFiles(ctx, src, []string{sbom})
+	require.NoError(t, err)
+	sbomDesc, err := Attach(ctx, src, rootDesc, artifactType, layers)
+	require.NoError(t, err)
+	layers, err = PushFiles(ctx, src, []string{notes})
+	require.NoError(t, err)
+	_, err = Attach(ctx, src, rootDesc, config.BundleReleaseNotesArtifactType, layers)
+	require.NoError(t, err)
+
+	referrers, err := Referrers(ctx, src, rootDesc)
+	require.NoError(t, err)
+	require.Len(t, referrers, 2)
+	manifest, err := FetchReferrer(ctx, src, sbomDesc)
+	require.NoError(t, err)
+	require.Equal(t, rootDesc.Digest, manifest.Subject.Digest)
+	require.Nil(t, manifest.Subject.Annotations)
+	require.Equal(t, "sboms.tar", manifest.Layers[0].Annotations[ocispec.AnnotationTitle])
+
+	// the referrers are copied without their subject
+	dst := memory.New()
+	require.NoError(t, CopyReferrers(ctx, src, dst, rootDesc, referrers))
+	exists, err := dst.Exists(ctx, rootDesc)
+	require.NoError(t, err)
+	require.False(t, exists)
+	for _, desc := range referrers {
+		exists, err := dst.Exists(ctx, desc)
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+
+	out := t.TempDir()
+	require.NoError(t, SaveReferrer(ctx, dst, manifest, out))
+	b, err := os.ReadFile(filepath.Join(out, "sboms.tar"))
+	require.NoError(t, err)
+	require.Equal(t, "sbom", string(b))
+}
+
+func TestAttachSignature(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	rootDesc := pushTestBundle(t, store)
+	sig := []byte("signature")
+	sigDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, sig)
+	sigDesc.Annotations = map[string]string{ocispec.AnnotationTitle: config.BundleYAMLSignature}
+	require.NoError(t, store.Push(ctx, sigDesc, bytes.NewReader(sig)))
+
+	// attaching the same signature again is a no-op
+	require.NoError(t, AttachSignature(ctx, store, rootDesc, sigDesc))
+	require.NoError(t, AttachSignature(ctx, store, rootDesc, sigDesc))
+	referrers, err := Referrers(ctx, store, rootDesc)
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	require.Equal(t, config.BundleSignatureArtifactType, referrers[0].ArtifactType)
+}
+
+func TestArtifactType(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      string
+	}{
+		{name: "signature", expected: config.BundleSignatureArtifactType},
+		{name: "release-notes", expected: config.BundleReleaseNotesArtifactType},
+		{name: "application/vnd.example.scan.v1+json", expected: "application/vnd.example.scan.v1+json"},
+		{name: "scan", err: `invalid artifact type "scan"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifactType, err := ArtifactType(tt.name)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, artifactType)
+			require.Equal(t, tt.name, ShortArtifactType(artifactType))
+		})
+	}
+}
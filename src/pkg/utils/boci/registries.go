@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/defenseunicorns/zarf/src/pkg/zoci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// newRemoteMu serializes creating remotes, which share and modify the ORAS default auth client until they're given their own
+var newRemoteMu sync.Mutex
+
+// NewRemote creates a remote for url with the credentials and TLS settings of its registry from the uds-config
+//
+// each remote gets its own HTTP client, as the ORAS default client is shared and would otherwise mix settings across registries
+func NewRemote(url string, platform ocispec.Platform) (*zoci.Remote, error) {
+	ref, err := registry.ParseReference(strings.TrimPrefix(url, ociScheme))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OCI reference %q: %w", url, err)
+	}
+	regCfg, ok := config.CommonOptions.Registries[ref.Registry]
+	var cred auth.Credential
+	var tlsCfg *tls.Config
+	if ok {
+		if cred, err = credential(regCfg); err != nil {
+			return nil, fmt.Errorf("unable to get credentials for %s: %w", ref.Registry, err)
+		}
+		if tlsCfg, err = tlsConfig(regCfg); err != nil {
+			return nil, fmt.Errorf("unable to configure TLS for %s: %w", ref.Registry, err)
+		}
+	}
+
+	var transport *http.Transport
+	mods := []oci.Modifier{func(o *oci.OrasRemote) {
+		transport, _ = o.Repo().Client.(*auth.Client).Client.Transport.(*http.Transport)
+	}}
+	if regCfg.PlainHTTP {
+		mods = append(mods, oci.WithPlainHTTP(true))
+	}
+	if regCfg.InsecureSkipVerify {
+		mods = append(mods, oci.WithInsecureSkipVerify(true))
+	}
+
+	newRemoteMu.Lock()
+	defer newRemoteMu.Unlock()
+	remote, err := zoci.NewRemote(url, platform, mods...)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		return nil, errors.New("unable to configure the HTTP transport for " + ref.Registry)
+	}
+	if tlsCfg != nil {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = tlsCfg.RootCAs
+		transport.TLSClientConfig.Certificates = tlsCfg.Certificates
+	}
+
+	// the transport is also the base of the remote's progress transport, so the settings apply while reporting progress
+	shared := remote.Repo().Client.(*auth.Client)
+	client := &auth.Client{
+		Client:     &http.Client{Transport: transport},
+		Header:     shared.Header.Clone(),
+		Cache:      auth.NewCache(),
+		Credential: shared.Credential,
+	}
+	if cred != auth.EmptyCredential {
+		client.Credential = auth.StaticCredential(remote.Repo().Reference.Registry, cred)
+	}
+	remote.Repo().Client = client
+	return remote, nil
+}
+
+// ValidateRegistries checks that each registry's password, token and client certificate are set in only one way
+func ValidateRegistries(registries map[string]types.RegistryConfig) error {
+	for host, r := range registries {
+		if countSet(r.Password, r.PasswordEnv, r.PasswordFile) > 1 {
+			return fmt.Errorf("registries.%s: only one of password, password_env and password_file can be set", host)
+		}
+		if countSet(r.Token, r.TokenEnv, r.TokenFile) > 1 {
+			return fmt.Errorf("registries.%s: only one of token, token_env and token_file can be set", host)
+		}
+		if countSet(r.CertFile, r.KeyFile) == 1 {
+			return fmt.Errorf("registries.%s: cert_file and key_file must be set together", host)
+		}
+	}
+	return nil
+}
+
+func countSet(values ...string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// credential returns a registry's credentials, reading the password or token from its env var or file
+func credential(r types.RegistryConfig) (auth.Credential, error) {
+	password, err := secret(r.Password, r.PasswordEnv, r.PasswordFile)
+	if err != nil {
+		return auth.EmptyCredential, err
+	}
+	token, err := secret(r.Token, r.TokenEnv, r.TokenFile)
+	if err != nil {
+		return auth.EmptyCredential, err
+	}
+	return auth.Credential{Username: r.Username, Password: password, AccessToken: token}, nil
+}
+
+// secret returns value, or reads it from the env var or file it is set in
+func secret(value, env, file string) (string, error) {
+	switch {
+	case env != "":
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("env var %s is not set", env)
+		}
+		return v, nil
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	default:
+		return value, nil
+	}
+}
+
+// tlsConfig returns the CAs and client certificate to use for a registry, or nil if it uses the defaults
+func tlsConfig(r types.RegistryConfig) (*tls.Config, error) {
+	if r.CAFile == "" && r.CertFile == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{}
+	if r.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		b, err := os.ReadFile(r.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", r.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if r.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,153 @@
+package boci
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/pkg/oci"
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/types"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to dir
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "uds-ci"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return cert, certFile, keyFile
+}
+
+func TestNewRemote(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCert(t, dir)
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+
+	// a registry that requires a client certificate and basic auth
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ci" || pass != "s3cret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="uds"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/v2/example/manifests/0.0.1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(manifest)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+	host := strings.TrimPrefix(srv.URL, "https://")
+	t.Setenv("UDS_TEST_REGISTRY_PASSWORD", "s3cret")
+
+	registries := config.CommonOptions.Registries
+	t.Cleanup(func() { config.CommonOptions.Registries = registries })
+	config.CommonOptions.Registries = map[string]types.RegistryConfig{
+		host: {Username: "ci", PasswordEnv: "UDS_TEST_REGISTRY_PASSWORD", CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+	}
+	ctx := context.Background()
+	platform := oci.PlatformForArch("amd64")
+
+	remote, err := NewRemote("oci://"+host+"/example:0.0.1", platform)
+	require.NoError(t, err)
+
+	// a remote for a registry without settings doesn't change the settings of other remotes
+	config.CommonOptions.Registries = nil
+	other, err := NewRemote("oci://"+host+"/example:0.0.1", platform)
+	require.NoError(t, err)
+	_, err = other.ResolveRoot(ctx)
+	require.Error(t, err)
+
+	desc, err := remote.ResolveRoot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, digest.FromBytes(manifest), desc.Digest)
+}
+
+func TestValidateRegistries(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry types.RegistryConfig
+		err      string
+	}{
+		{name: "password from env", registry: types.RegistryConfig{Username: "ci", PasswordEnv: "PASSWORD"}},
+		{name: "token from file", registry: types.RegistryConfig{TokenFile: "/run/secrets/token"}},
+		{name: "password set twice", registry: types.RegistryConfig{Password: "p", PasswordFile: "/p"}, err: "only one of password, password_env and password_file"},
+		{name: "token set twice", registry: types.RegistryConfig{Token: "t", TokenEnv: "TOKEN"}, err: "only one of token, token_env and token_file"},
+		{name: "cert without key", registry: types.RegistryConfig{CertFile: "client.pem"}, err: "cert_file and key_file must be set together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRegistries(map[string]types.RegistryConfig{"registry.example.com": tt.registry})
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSecret(t *testing.T) {
+	t.Setenv("UDS_TEST_SECRET", "from env")
+	file := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(file, []byte("from file\n"), 0o600))
+
+	v, err := secret("value", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+	v, err = secret("", "UDS_TEST_SECRET", "")
+	require.NoError(t, err)
+	require.Equal(t, "from env", v)
+	v, err = secret("", "", file)
+	require.NoError(t, err)
+	require.Equal(t, "from file", v)
+	_, err = secret("", "UDS_TEST_UNSET_SECRET", "")
+	require.ErrorContains(t, err, "env var UDS_TEST_UNSET_SECRET is not set")
+}
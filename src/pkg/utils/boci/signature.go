@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package boci (bundle OCI) provides OCI utility functions for bundles
+package boci
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/zarf/src/pkg/message"
+	zarfUtils "github.com/defenseunicorns/zarf/src/pkg/utils"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// SignaturePayload is the payload signed by a bundle signature
+//
+// it pins the digest of the bundle's root manifest, which in turn pins every package manifest and layer in the bundle
+type SignaturePayload struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Size      int64         `json:"size"`
+}
+
+// SignBundle signs the digest of a bundle's root manifest with each key, attaching each signature to the root manifest in dst
+func SignBundle(ctx context.Context, dst content.Storage, rootDesc ocispec.Descriptor, keyPaths []string, getPassword func(bool) ([]byte, error)) ([]ocispec.Descriptor, error) {
+	tmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	payload, err := json.Marshal(SignaturePayload{MediaType: rootDesc.MediaType, Digest: rootDesc.Digest, Size: rootDesc.Size})
+	if err != nil {
+		return nil, err
+	}
+	payloadPath := filepath.Join(tmp, config.BundleManifestPayload)
+	if err := os.WriteFile(payloadPath, payload, 0o600); err != nil {
+		return nil, err
+	}
+
+	var signatures []ocispec.Descriptor
+	for i, keyPath := range keyPaths {
+		// each signature is written to its own dir so its layer keeps the signature's file name as its title
+		sigPath := filepath.Join(tmp, strconv.Itoa(i), config.BundleManifestSignature)
+		if err := os.MkdirAll(filepath.Dir(sigPath), 0o700); err != nil {
+			return nil, err
+		}
+		if _, err := zarfUtils.CosignSignBlob(payloadPath, sigPath, keyPath, getPassword); err != nil {
+			return nil, fmt.Errorf("failed to sign the bundle with %s: %w", keyPath, err)
+		}
+		layers, err := PushFiles(ctx, dst, []string{payloadPath, sigPath})
+		if err != nil {
+			return nil, err
+		}
+		desc, err := Attach(ctx, dst, rootDesc, config.BundleSignatureArtifactType, layers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach the bundle's signature: %w", err)
+		}
+		message.Debug("Attached signature:", message.JSONValue(desc))
+		signatures = append(signatures, desc)
+	}
+	return signatures, nil
+}
+
+// ManifestSignatures returns the referrers that are signatures of a bundle's root manifest, leaving out signatures of its uds-bundle.yaml
+func ManifestSignatures(ctx context.Context, src content.Fetcher, referrers []ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var signatures []ocispec.Descriptor
+	for _, desc := range referrers {
+		if desc.ArtifactType != config.BundleSignatureArtifactType {
+			continue
+		}
+		manifest, err := FetchReferrer(ctx, src, desc)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, ok := signatureLayers(manifest); ok {
+			signatures = append(signatures, desc)
+		}
+	}
+	return signatures, nil
+}
+
+// signatureLayers returns the payload and signature layers of a signature referrer
+func signatureLayers(manifest ocispec.Manifest) (payload ocispec.Descriptor, signature ocispec.Descriptor, ok bool) {
+	for _, layer := range manifest.Layers {
+		switch layer.Annotations[ocispec.AnnotationTitle] {
+		case config.BundleManifestPayload:
+			payload = layer
+		case config.BundleManifestSignature:
+			signature = layer
+		}
+	}
+	return payload, signature, payload.Digest != "" && signature.Digest != ""
+}
+
+// VerifyBundle verifies the signatures of a bundle's root manifest, requiring a valid signature from at least threshold of the keys
+//
+// the keys and threshold must already have been checked with ValidateKeys
+func VerifyBundle(ctx context.Context, src content.Fetcher, signatures []ocispec.Descriptor, rootDesc ocispec.Descriptor, keyPaths []string, threshold int) error {
+	tmp, err := zarfUtils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	verified := make(map[string]bool)
+	for i, desc := range signatures {
+		manifest, err := FetchReferrer(ctx, src, desc)
+		if err != nil {
+			return err
+		}
+		payloadDesc, sigDesc, ok := signatureLayers(manifest)
+		if !ok {
+			continue
+		}
+		payloadBytes, err := content.FetchAll(ctx, src, payloadDesc)
+		if err != nil {
+			return err
+		}
+		var payload SignaturePayload
+		if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+			return fmt.Errorf("invalid signature payload in %s: %w", desc.Digest, err)
+		}
+		if payload.Digest != rootDesc.Digest {
+			message.Debugf("Skipping signature %s, it signs %s rather than %s", desc.Digest, payload.Digest, rootDesc.Digest)
+			continue
+		}
+		sig, err := content.FetchAll(ctx, src, sigDesc)
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Join(tmp, strconv.Itoa(i))
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+		payloadPath := filepath.Join(dir, config.BundleManifestPayload)
+		sigPath := filepath.Join(dir, config.BundleManifestSignature)
+		if err := os.WriteFile(payloadPath, payloadBytes, 0o600); err != nil {
+			return err
+		}
+		if err := os.WriteFile(sigPath, sig, 0o600); err != nil {
+			return err
+		}
+		for _, keyPath := range keyPaths {
+			if verified[keyPath] {
+				continue
+			}
+			if err := zarfUtils.CosignVerifyBlob(payloadPath, sigPath, keyPath); err != nil {
+				message.Debugf("Signature %s was not signed by %s: %s", desc.Digest, keyPath, err.Error())
+				continue
+			}
+			verified[keyPath] = true
+		}
+	}
+
+	if len(verified) < threshold {
+		return fmt.Errorf("the bundle's root manifest %s has valid signatures from %d of the %d keys, %d are required", rootDesc.Digest, len(verified), len(keyPaths), threshold)
+	}
+	message.Debugf("Verified the bundle's root manifest %s with %d of %d keys", rootDesc.Digest, len(verified), len(keyPaths))
+	return nil
+}
+
+// ValidateKeys ensures threshold is between 1 and the number of keys, and that no two of the keys are the same public key
+// so a key passed twice can't count towards the threshold twice
+func ValidateKeys(keyPaths []string, threshold int) error {
+	if threshold < 1 || threshold > len(keyPaths) {
+		return fmt.Errorf("--key-threshold must be between 1 and the number of keys (%d), got %d", len(keyPaths), threshold)
+	}
+	seen := make(map[string]string)
+	for _, keyPath := range keyPaths {
+		fingerprint := keyFingerprint(keyPath)
+		if other, ok := seen[fingerprint]; ok {
+			return fmt.Errorf("the keys %s and %s are the same public key", other, keyPath)
+		}
+		seen[fingerprint] = keyPath
+	}
+	return nil
+}
+
+// keyFingerprint returns the sha256 of a PEM encoded public key, or the key's normalized path or ref if it isn't a PEM
+// encoded public key, such as a KMS ref
+func keyFingerprint(keyPath string) string {
+	if strings.Contains(keyPath, "://") {
+		return keyPath
+	}
+	normalized := filepath.Clean(keyPath)
+	if abs, err := filepath.Abs(keyPath); err == nil {
+		normalized = abs
+	}
+	b, err := os.ReadFile(keyPath)
+	if err != nil {
+		return normalized
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return normalized
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return normalized
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return normalized
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package boci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/defenseunicorns/uds-cli/src/config"
+	"github.com/defenseunicorns/uds-cli/src/test/keypair"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestSignAndVerifyBundle(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	var privateKeys, publicKeys []string
+	for i := 0; i < 3; i++ {
+		privateKey, publicKey := keypair.Write(t, dir, strconv.Itoa(i))
+		privateKeys = append(privateKeys, privateKey)
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	store := memory.New()
+	rootDesc := pushTestBundle(t, store)
+	signatures, err := SignBundle(ctx, store, rootDesc, privateKeys[:2], keypair.Password)
+	require.NoError(t, err)
+	require.Len(t, signatures, 2)
+
+	// the signatures are attached to the root manifest alongside other artifacts
+	referrers, err := Referrers(ctx, store, rootDesc)
+	require.NoError(t, err)
+	require.Len(t, referrers, 2)
+	found, err := ManifestSignatures(ctx, store, referrers)
+	require.NoError(t, err)
+	require.ElementsMatch(t, signatures, found)
+
+	tests := []struct {
+		name      string
+		keys      []string
+		threshold int
+		err       string
+	}{
+		{name: "one of the signing keys", keys: publicKeys[1:2], threshold: 1},
+		{name: "both signing keys", keys: publicKeys[:2], threshold: 2},
+		{name: "two of three keys", keys: publicKeys, threshold: 2},
+		{name: "all three keys", keys: publicKeys, threshold: 3, err: "valid signatures from 2 of the 3 keys, 3 are required"},
+		{name: "key that didn't sign", keys: publicKeys[2:], threshold: 1, err: "valid signatures from 0 of the 1 keys"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyBundle(ctx, store, found, rootDesc, tt.keys, tt.threshold)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// signatures of another root manifest don't count
+	otherRoot := rootDesc
+	otherRoot.Digest = digest.FromString("another bundle")
+	require.ErrorContains(t, VerifyBundle(ctx, store, found, otherRoot, publicKeys[:1], 1), "valid signatures from 0 of the 1 keys")
+}
+
+func TestManifestSignaturesSkipsYAMLSignatures(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	rootDesc := pushTestBundle(t, store)
+	sig := filepath.Join(t.TempDir(), config.BundleYAMLSignature)
+	require.NoError(t, os.WriteFile(sig, []byte("signature"), 0o600))
+	layers, err := PushFiles(ctx, store, []string{sig})
+	require.NoError(t, err)
+	require.NoError(t, AttachSignature(ctx, store, rootDesc, layers[0]))
+
+	referrers, err := Referrers(ctx, store, rootDesc)
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	signatures, err := ManifestSignatures(ctx, store, referrers)
+	require.NoError(t, err)
+	require.Empty(t, signatures)
+}
+
+func TestValidateKeys(t *testing.T) {
+	dir := t.TempDir()
+	_, publicKey := keypair.Write(t, dir, "0")
+	_, otherKey := keypair.Write(t, dir, "1")
+	require.NoError(t, ValidateKeys([]string{publicKey, otherKey}, 2))
+
+	// the threshold must be between 1 and the number of keys
+	require.ErrorContains(t, ValidateKeys([]string{publicKey}, 2), "--key-threshold must be between 1 and the number of keys (1), got 2")
+	require.ErrorContains(t, ValidateKeys([]string{publicKey}, 0), "--key-threshold must be between 1 and the number of keys (1), got 0")
+
+	// the same public key at another path or under a path that isn't clean
+	copied := filepath.Join(dir, "copy.pub")
+	b, err := os.ReadFile(publicKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(copied, b, 0o600))
+	unclean := filepath.Join(dir, "..", filepath.Base(dir), "0.pub")
+	require.ErrorContains(t, ValidateKeys([]string{publicKey, copied}, 2), "are the same public key")
+	require.ErrorContains(t, ValidateKeys([]string{publicKey, unclean}, 2), "are the same public key")
+
+	// keys that aren't PEM encoded public keys are compared by their normalized path or ref
+	require.NoError(t, ValidateKeys([]string{"awskms:///alias/a", "awskms:///alias/b", "missing.pub"}, 1))
+	require.ErrorContains(t, ValidateKeys([]string{"awskms:///alias/a", "awskms:///alias/a"}, 1), "are the same public key")
+	require.ErrorContains(t, ValidateKeys([]string{"keys/missing.pub", "./keys/../keys/missing.pub"}, 1), "the keys keys/missing.pub and ./keys/../keys/missing.pub are the same public key")
+}
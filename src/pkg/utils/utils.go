@@ -24,6 +24,7 @@ import (
 	"github.com/defenseunicorns/uds-cli/src/types"
 	"github.com/defenseunicorns/zarf/src/pkg/message"
 	av4 "github.com/mholt/archiver/v4"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 )
@@ -44,6 +45,14 @@ func IsValidTarballPath(path string) bool {
 	return re.MatchString(name)
 }
 
+// IsValidLayoutPath returns true if the path is a directory containing an OCI image layout, ie. a bundle pulled with --format oci-layout
+func IsValidLayoutPath(path string) bool {
+	if helpers.InvalidPath(path) || !helpers.IsDir(path) {
+		return false
+	}
+	return !helpers.InvalidPath(filepath.Join(path, ocispec.ImageLayoutFile)) && !helpers.InvalidPath(filepath.Join(path, ocispec.ImageIndexFile))
+}
+
 // IncludeComponent checks if a component has been specified in a a list of components (used for filtering optional components)
 func IncludeComponent(componentToCheck string, filteredComponents []zarfTypes.ZarfComponent) bool {
 	for _, component := range filteredComponents {
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -91,3 +93,28 @@ func Test_IsRegistryURL(t *testing.T) {
 		})
 	}
 }
+
+func Test_IsValidLayoutPath(t *testing.T) {
+	layoutDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(layoutDir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(layoutDir, "index.json"), []byte(`{}`), 0o600))
+
+	missingIndexDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(missingIndexDir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o600))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantResult bool
+	}{
+		{name: "IsLayout", path: layoutDir, wantResult: true},
+		{name: "IsMissingIndex", path: missingIndexDir, wantResult: false},
+		{name: "IsFile", path: filepath.Join(layoutDir, "index.json"), wantResult: false},
+		{name: "DoesNotExist", path: filepath.Join(layoutDir, "missing"), wantResult: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantResult, IsValidLayoutPath(tt.path))
+		})
+	}
+}
@@ -444,6 +444,37 @@ func TestBundleIndexInRemoteOnPublish(t *testing.T) {
 	deployAndRemoveLocalAndRemoteInsecure(t, fmt.Sprintf("oci://localhost:888/%s:0.0.1", bundleName), tarballPath)
 }
 
+func TestCopyBundleBetweenRegistries(t *testing.T) {
+	deployZarfInit(t)
+	e2e.SetupDockerRegistry(t, 888)
+	defer e2e.TeardownRegistry(t, 888)
+	e2e.SetupDockerRegistry(t, 889)
+	defer e2e.TeardownRegistry(t, 889)
+
+	bundleDir := "src/test/bundles/06-ghcr"
+	bundleName := "ghcr-test"
+	bundleTarballName := fmt.Sprintf("uds-bundle-%s-%s-0.0.1.tar.zst", bundleName, e2e.Arch)
+	bundlePath := filepath.Join(bundleDir, bundleTarballName)
+
+	createLocal(t, bundleDir, e2e.Arch)
+	publishInsecure(t, bundlePath, "localhost:888")
+
+	// copy to another registry, then to another repo in the same registry to use cross repository blob mounts
+	publishInsecure(t, fmt.Sprintf("oci://localhost:888/%s:0.0.1", bundleName), "oci://localhost:889")
+	publishInsecure(t, fmt.Sprintf("oci://localhost:889/%s:0.0.1", bundleName), "oci://localhost:889/copies")
+
+	// copying again skips the blobs that already exist
+	publishInsecure(t, fmt.Sprintf("oci://localhost:888/%s:0.0.1", bundleName), "oci://localhost:889")
+
+	for _, ref := range []string{
+		fmt.Sprintf("oci://localhost:889/%s:0.0.1", bundleName),
+		fmt.Sprintf("oci://localhost:889/copies/%s:0.0.1", bundleName),
+	} {
+		inspectRemoteInsecure(t, ref)
+		pull(t, strings.TrimPrefix(ref, "oci://"), bundleTarballName)
+	}
+}
+
 func TestBundleIndexInRemoteOnCreate(t *testing.T) {
 	deployZarfInit(t)
 	e2e.SetupDockerRegistry(t, 888)
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package keypair contains cosign key pairs for signing and verifying in tests
+package keypair
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/stretchr/testify/require"
+)
+
+// Password returns the password of the private keys written by Write
+func Password(_ bool) ([]byte, error) {
+	return []byte("password"), nil
+}
+
+// Write writes a cosign key pair to dir, returning the paths of the private and public keys
+func Write(t *testing.T, dir string, name string) (string, string) {
+	t.Helper()
+	keys, err := cosign.GenerateKeyPair(Password)
+	require.NoError(t, err)
+	privateKey := filepath.Join(dir, name+".key")
+	publicKey := filepath.Join(dir, name+".pub")
+	require.NoError(t, os.WriteFile(privateKey, keys.PrivateBytes, 0o600))
+	require.NoError(t, os.WriteFile(publicKey, keys.PublicBytes, 0o600))
+	return privateKey, publicKey
+}
@@ -4,12 +4,15 @@
 // Package types contains all the types used by UDS.
 package types
 
+import zarfTypes "github.com/defenseunicorns/zarf/src/types"
+
 // UDSBundle is the top-level structure of a UDS bundle
 type UDSBundle struct {
-	Kind     string       `json:"kind" jsonschema:"description=The kind of UDS package,enum=UDSBundle"`
-	Metadata UDSMetadata  `json:"metadata" jsonschema:"description=UDSBundle metadata"`
-	Build    UDSBuildData `json:"build,omitempty" jsonschema:"description=Generated bundle build data"`
-	Packages []Package    `json:"packages" jsonschema:"description=List of Zarf packages"`
+	Kind     string        `json:"kind" jsonschema:"description=The kind of UDS package,enum=UDSBundle"`
+	Metadata UDSMetadata   `json:"metadata" jsonschema:"description=UDSBundle metadata"`
+	Build    UDSBuildData  `json:"build,omitempty" jsonschema:"description=Generated bundle build data"`
+	Packages []Package     `json:"packages" jsonschema:"description=List of Zarf packages"`
+	Actions  BundleActions `json:"actions,omitempty" jsonschema:"description=Actions to run before and after all of the bundle's packages are deployed or removed"`
 }
 
 // Package represents a Zarf package in a UDS bundle
@@ -24,6 +27,16 @@ type Package struct {
 	Imports            []BundleVariableImport                     `json:"imports,omitempty" jsonschema:"description=List of Zarf variables to import from another Zarf package"`
 	Exports            []BundleVariableExport                     `json:"exports,omitempty" jsonschema:"description=List of Zarf variables to export from the Zarf package"`
 	Overrides          map[string]map[string]BundleChartOverrides `json:"overrides,omitempty" jsonschema:"description=Map of Helm chart overrides to set. The format is <component>:, <chart-name>:"`
+	DependsOn          []string                                   `json:"dependsOn,omitempty" jsonschema:"description=List of packages in the bundle that must be deployed before this package (packages referenced in imports are included automatically)"`
+	Actions            BundleActions                              `json:"actions,omitempty" jsonschema:"description=Actions to run before and after the Zarf package is deployed or removed"`
+	Timeout            string                                     `json:"timeout,omitempty" jsonschema:"description=How long to wait for the package's Helm charts to be ready (defaults to 15m),example=40m"`
+	Retries            *int                                       `json:"retries,omitempty" jsonschema:"description=Number of times to retry failed Helm installs and image pushes with an exponential backoff (defaults to the --retries flag)"`
+}
+
+// BundleActions are actions to run around the deployment and removal of a bundle or one of its packages
+type BundleActions struct {
+	OnDeploy zarfTypes.ZarfComponentActionSet `json:"onDeploy,omitempty" jsonschema:"description=Actions to run when deploying"`
+	OnRemove zarfTypes.ZarfComponentActionSet `json:"onRemove,omitempty" jsonschema:"description=Actions to run when removing"`
 }
 
 // BundleChartOverrides represents a Helm chart override to set via UDS variables
@@ -76,9 +89,10 @@ type UDSMetadata struct {
 
 // UDSBuildData is written during the bundle.Create() operation to track details of the created package.
 type UDSBuildData struct {
-	Terminal     string `json:"terminal" jsonschema:"description=The machine name that created this package"`
-	User         string `json:"user" jsonschema:"description=The username who created this package"`
-	Architecture string `json:"architecture" jsonschema:"description=The architecture this package was created on"`
-	Timestamp    string `json:"timestamp" jsonschema:"description=The timestamp when this package was created"`
-	Version      string `json:"version" jsonschema:"description=The version of Zarf used to build this package"`
+	Terminal         string   `json:"terminal" jsonschema:"description=The machine name that created this package"`
+	User             string   `json:"user" jsonschema:"description=The username who created this package"`
+	Architecture     string   `json:"architecture" jsonschema:"description=The architecture this package was created on"`
+	Timestamp        string   `json:"timestamp" jsonschema:"description=The timestamp when this package was created"`
+	Version          string   `json:"version" jsonschema:"description=The version of Zarf used to build this package"`
+	VerifiedPackages []string `json:"verifiedPackages,omitempty" jsonschema:"description=The packages whose signatures were verified with their publicKey when the bundle was created"`
 }
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package types contains all the types used by UDS.
+package types
+
+import "time"
+
+// EventType is the name of an event written with --events, these names are stable and safe to build on
+type EventType string
+
+// Event types written with --events
+const (
+	EventOperationStarted  EventType = "operation.started"
+	EventOperationFinished EventType = "operation.finished"
+	EventPackageStarted    EventType = "package.started"
+	EventPackageFinished   EventType = "package.finished"
+	EventLayerPulled       EventType = "layer.pulled"
+	EventLayerPushed       EventType = "layer.pushed"
+	EventOverrideApplied   EventType = "override.applied"
+	EventVariableResolved  EventType = "variable.resolved"
+	EventError             EventType = "error"
+)
+
+// Event is a single line of the JSON-lines event stream written with --events
+type Event struct {
+	Type      EventType `json:"type" jsonschema:"description=The type of event,enum=operation.started,enum=operation.finished,enum=package.started,enum=package.finished,enum=layer.pulled,enum=layer.pushed,enum=override.applied,enum=variable.resolved,enum=error"`
+	Time      time.Time `json:"time" jsonschema:"description=When the event happened"`
+	Operation string    `json:"operation" jsonschema:"description=The operation that emitted the event,enum=create,enum=pull,enum=publish,enum=deploy,enum=remove"`
+	Bundle    string    `json:"bundle,omitempty" jsonschema:"description=The name of the bundle once it is known"`
+	Source    string    `json:"source,omitempty" jsonschema:"description=The bundle source or destination the operation was started with"`
+	Package   string    `json:"package,omitempty" jsonschema:"description=The name of the package the event belongs to"`
+	// Result and Error are set on operation.finished, package.finished and error events
+	Result          BundleStatus `json:"result,omitempty" jsonschema:"description=The outcome of the operation or package,enum=Succeeded,enum=Failed,enum=Unchanged"`
+	Error           string       `json:"error,omitempty" jsonschema:"description=The error that caused the operation or package to fail"`
+	DurationSeconds float64      `json:"durationSeconds,omitempty" jsonschema:"description=How long the operation or package took"`
+	// Digest, MediaType and Bytes are set on layer events
+	Digest    string `json:"digest,omitempty" jsonschema:"description=The digest of the layer"`
+	MediaType string `json:"mediaType,omitempty" jsonschema:"description=The media type of the layer"`
+	Bytes     int64  `json:"bytes,omitempty" jsonschema:"description=The size of the layer in bytes"`
+	// Component, Chart and Path are set on override.applied events
+	Component string `json:"component,omitempty" jsonschema:"description=The component of the overridden chart"`
+	Chart     string `json:"chart,omitempty" jsonschema:"description=The overridden chart"`
+	Path      string `json:"path,omitempty" jsonschema:"description=The Helm values path that was overridden or the namespace for namespace overrides"`
+	// Variable, Value and Sensitive are set on variable.resolved events
+	Variable  string `json:"variable,omitempty" jsonschema:"description=The name of the resolved variable"`
+	Value     string `json:"value,omitempty" jsonschema:"description=The value of the resolved variable which is masked if the variable is sensitive"`
+	Sensitive bool   `json:"sensitive,omitempty" jsonschema:"description=Whether the variable is sensitive"`
+}
@@ -12,65 +12,153 @@ type BundleConfig struct {
 	PullOpts    BundlePullOptions
 	InspectOpts BundleInspectOptions
 	RemoveOpts  BundleRemoveOptions
+	AttachOpts  BundleAttachOptions
 }
 
 // BundleCreateOptions is the options for the bundler.Create() function
 type BundleCreateOptions struct {
 	SourceDirectory    string
 	Output             string
-	SigningKeyPath     string
+	SigningKeyPaths    []string
 	SigningKeyPassword string
 	BundleFile         string
+	Tags               []string
+	Repository         string
 }
 
 // BundleDeployOptions is the options for the bundler.Deploy() function
 type BundleDeployOptions struct {
-	Resume        bool
-	Source        string
-	Packages      []string
-	PublicKeyPath string
-	SetVariables  map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used by Zarf packages in a bundle"`
+	Resume                   bool
+	Source                   string
+	Packages                 []string
+	PublicKeyPaths           []string
+	KeyThreshold             int
+	RequireManifestSignature bool
+	Concurrency              int
+	RollbackOnFailure        bool
+	Force                    bool
+	DryRun                   bool
+	PlanOut                  string
+	PlanFile                 string
+	ReportPath               string
+	RetriesSet               bool
+	SetVariables             map[string]string `json:"setVariables" jsonschema:"description=Key-Value map of variable names and their corresponding values that will be used by Zarf packages in a bundle"`
 	// Variables and SharedVariables are read in from uds-config.yaml
 	Variables       map[string]map[string]interface{} `yaml:"variables,omitempty"`
 	SharedVariables map[string]interface{}            `yaml:"shared,omitempty"`
 	Retries         int                               `yaml:"retries"`
 	Options         map[string]interface{}            `yaml:"options,omitempty"`
+	// PackageOptions are read in from uds-config.yaml and override the settings of individual packages in the bundle
+	PackageOptions map[string]PackageDeployOptions `yaml:"package_options,omitempty"`
+	// RegistryMirrors are read in from uds-config.yaml and rewrite the OCI refs bundles and packages are read from
+	RegistryMirrors []RegistryMirror `yaml:"registry_mirrors,omitempty"`
+	// Registries are read in from uds-config.yaml and hold the credentials and TLS settings of each registry host
+	Registries map[string]RegistryConfig `yaml:"registries,omitempty"`
+}
+
+// PackageDeployOptions are the deploy settings of a single package in a bundle
+type PackageDeployOptions struct {
+	Timeout string `yaml:"timeout,omitempty"`
+	Retries *int   `yaml:"retries,omitempty"`
+}
+
+// RegistryMirror rewrites OCI refs starting with Prefix to each of its Mirrors in turn
+type RegistryMirror struct {
+	Prefix  string   `yaml:"prefix"`
+	Mirrors []string `yaml:"mirrors"`
+	// SkipUpstream stops the original ref from being tried once none of the mirrors resolve
+	SkipUpstream bool `yaml:"skip_upstream,omitempty"`
+}
+
+// RegistryConfig holds the credentials and TLS settings for a registry host
+//
+// the password and token can each be set directly, or read from an env var or file
+type RegistryConfig struct {
+	Username     string `yaml:"username,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordEnv  string `yaml:"password_env,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
+	Token        string `yaml:"token,omitempty"`
+	TokenEnv     string `yaml:"token_env,omitempty"`
+	TokenFile    string `yaml:"token_file,omitempty"`
+	CAFile       string `yaml:"ca_file,omitempty"`
+	CertFile     string `yaml:"cert_file,omitempty"`
+	KeyFile      string `yaml:"key_file,omitempty"`
+	// PlainHTTP talks to the registry over HTTP instead of HTTPS
+	PlainHTTP bool `yaml:"plain_http,omitempty"`
+	// InsecureSkipVerify skips verifying the registry's certificate
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
 }
 
 // BundleInspectOptions is the options for the bundler.Inspect() function
 type BundleInspectOptions struct {
-	PublicKeyPath string
-	Source        string
-	IncludeSBOM   bool
-	ExtractSBOM   bool
+	PublicKeyPaths           []string
+	KeyThreshold             int
+	RequireManifestSignature bool
+	Source                   string
+	IncludeSBOM              bool
+	ExtractSBOM              bool
+	// ListReferrers lists the artifacts attached to the bundle, which are also extracted with ExtractSBOM
+	ListReferrers bool
 }
 
 // BundlePublishOptions is the options for the bundle.Publish() function
 type BundlePublishOptions struct {
 	Source      string
 	Destination string
+	Tags        []string
+	Repository  string
 }
 
 // BundlePullOptions is the options for the bundler.Pull() function
 type BundlePullOptions struct {
-	OutputDirectory string
-	PublicKeyPath   string
-	Source          string
+	OutputDirectory          string
+	PublicKeyPaths           []string
+	KeyThreshold             int
+	RequireManifestSignature bool
+	Source                   string
+	Packages                 []string
+	SigningKeyPaths          []string
+	SigningKeyPassword       string
+	KeepSignature            bool
+	Format                   string
+	Referrers                bool
+}
+
+// Formats a bundle can be pulled as with --format
+const (
+	PullFormatTarball   = "tarball"
+	PullFormatOCILayout = "oci-layout"
+)
+
+// BundleAttachOptions is the options for the bundle.Attach() function
+type BundleAttachOptions struct {
+	Source       string
+	Files        []string
+	ArtifactType string
 }
 
 // BundleRemoveOptions is the options for the bundler.Remove() function
 type BundleRemoveOptions struct {
-	Source   string
-	Packages []string
+	Source     string
+	Packages   []string
+	BundleName string
+	ReportPath string
 }
 
 // BundleCommonOptions tracks the user-defined preferences used across commands.
 type BundleCommonOptions struct {
-	Confirm        bool   `json:"confirm" jsonschema:"description=Verify that Zarf should perform an action"`
-	Insecure       bool   `json:"insecure" jsonschema:"description=Allow insecure connections for remote packages"`
-	CachePath      string `json:"cachePath" jsonschema:"description=Path to use to cache images and git repos on package create"`
-	TempDirectory  string `json:"tempDirectory" jsonschema:"description=Location Zarf should use as a staging ground when managing files and images for package creation and deployment"`
-	OCIConcurrency int    `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package"`
+	Confirm        bool     `json:"confirm" jsonschema:"description=Verify that Zarf should perform an action"`
+	Insecure       bool     `json:"insecure" jsonschema:"description=Allow insecure connections for remote packages"`
+	CachePath      string   `json:"cachePath" jsonschema:"description=Path to use to cache images and git repos on package create"`
+	TempDirectory  string   `json:"tempDirectory" jsonschema:"description=Location Zarf should use as a staging ground when managing files and images for package creation and deployment"`
+	OCIConcurrency int      `jsonschema:"description=Number of concurrent layer operations to perform when interacting with a remote package"`
+	MaxCacheSize   string   `json:"maxCacheSize" jsonschema:"description=Maximum size of the UDS cache which is pruned of the least recently used files after pulls and deploys"`
+	SearchPaths    []string `json:"searchPaths" jsonschema:"description=Ordered list of OCI paths that bundle refs without a registry are searched for in"`
+	NoSearch       bool     `json:"noSearch" jsonschema:"description=Require fully qualified bundle refs instead of searching the search paths"`
+	// RegistryMirrors and Registries are only set in the uds-config
+	RegistryMirrors []RegistryMirror          `json:"-"`
+	Registries      map[string]RegistryConfig `json:"-"`
 }
 
 // PathMap is a map of either absolute paths to relative paths or relative paths to absolute paths
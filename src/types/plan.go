@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package types contains all the types used by UDS.
+package types
+
+// PlanAction is what a bundle deploy will do with a package
+type PlanAction string
+
+// Actions that can be planned for a package
+const (
+	PlanActionInstall PlanAction = "install"
+	PlanActionUpgrade PlanAction = "upgrade"
+	PlanActionSkip    PlanAction = "skip"
+)
+
+// DeployPlan is the result of a bundle deploy dry run
+type DeployPlan struct {
+	Bundle  string `json:"bundle"`
+	Version string `json:"version"`
+	Digest  string `json:"digest"`
+	Source  string `json:"source"`
+	// Concurrent is set when the plan is for a deploy with --deploy-concurrency above 1, where packages only get the
+	// exports of the packages they depend on
+	Concurrent bool          `json:"concurrent,omitempty"`
+	Packages   []PackagePlan `json:"packages"`
+}
+
+// PackagePlan is what a bundle deploy will do with a bundled Zarf package
+type PackagePlan struct {
+	Name               string                                       `json:"name"`
+	Ref                string                                       `json:"ref"`
+	Action             PlanAction                                   `json:"action"`
+	OptionalComponents []string                                     `json:"optionalComponents,omitempty"`
+	ValuesOverrides    map[string]map[string]map[string]interface{} `json:"valuesOverrides,omitempty"`
+	NamespaceOverrides map[string]map[string]string                 `json:"namespaceOverrides,omitempty"`
+	// ConfigHash is a hash of the package's resolved variables and chart overrides
+	ConfigHash string `json:"configHash"`
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package types contains all the types used by UDS.
+package types
+
+import "time"
+
+// Report is the summary of a bundle deploy or remove that is written with --report
+type Report struct {
+	Operation       string          `json:"operation"`
+	Bundle          string          `json:"bundle"`
+	Version         string          `json:"version,omitempty"`
+	Source          string          `json:"source,omitempty"`
+	StartedAt       time.Time       `json:"startedAt"`
+	FinishedAt      time.Time       `json:"finishedAt"`
+	DurationSeconds float64         `json:"durationSeconds"`
+	Result          BundleStatus    `json:"result"`
+	Error           string          `json:"error,omitempty"`
+	Packages        []PackageReport `json:"packages"`
+}
+
+// PackageReport is the result of deploying or removing a single package in a bundle
+type PackageReport struct {
+	Name            string       `json:"name"`
+	Ref             string       `json:"ref,omitempty"`
+	StartedAt       time.Time    `json:"startedAt,omitempty"`
+	FinishedAt      time.Time    `json:"finishedAt,omitempty"`
+	DurationSeconds float64      `json:"durationSeconds"`
+	Result          BundleStatus `json:"result"`
+	Error           string       `json:"error,omitempty"`
+	Components      []string     `json:"components,omitempty"`
+	// HelmReleases are the Helm releases the package touched, in the format <namespace>/<release>
+	HelmReleases []string `json:"helmReleases,omitempty"`
+}
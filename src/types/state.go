@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2023-Present The UDS Authors
+
+// Package types contains all the types used by UDS.
+package types
+
+import "time"
+
+// BundleStatus is the outcome of a bundle or package operation
+type BundleStatus string
+
+// Status values recorded for deployed bundles and their packages
+const (
+	StatusDeploying BundleStatus = "Deploying"
+	StatusSucceeded BundleStatus = "Succeeded"
+	StatusFailed    BundleStatus = "Failed"
+	StatusSkipped   BundleStatus = "Skipped"
+	// StatusUnchanged is reported for packages that kept the record of an earlier deploy because they haven't changed
+	StatusUnchanged BundleStatus = "Unchanged"
+)
+
+// BundleState is the record of a bundle deployment that is stored in the cluster
+type BundleState struct {
+	Name         string         `json:"name"`
+	Version      string         `json:"version"`
+	Architecture string         `json:"architecture"`
+	Digest       string         `json:"digest"`
+	Source       string         `json:"source"`
+	Packages     []PackageState `json:"packages"`
+	User         string         `json:"user"`
+	Terminal     string         `json:"terminal"`
+	CLIVersion   string         `json:"cliVersion"`
+	StartedAt    time.Time      `json:"startedAt"`
+	FinishedAt   time.Time      `json:"finishedAt,omitempty"`
+	Status       BundleStatus   `json:"status"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// PackageState is the record of a bundled Zarf package deployment that is stored in the cluster
+type PackageState struct {
+	Name   string `json:"name"`
+	Ref    string `json:"ref"`
+	Digest string `json:"digest"`
+	// Version is the metadata.version of the deployed Zarf package, which isn't always the tag in Ref (ie. for flavored packages)
+	Version    string       `json:"version,omitempty"`
+	StartedAt  time.Time    `json:"startedAt,omitempty"`
+	FinishedAt time.Time    `json:"finishedAt,omitempty"`
+	Status     BundleStatus `json:"status"`
+	Error      string       `json:"error,omitempty"`
+	// ConfigHash is a hash of the package's resolved variables and chart overrides
+	ConfigHash string `json:"configHash,omitempty"`
+	// Exports are the variables the package exported so they can be reused when the package is skipped, sensitive
+	// variables are left out since the state is readable by anyone who can read its secret
+	Exports map[string]string `json:"exports,omitempty"`
+	// SensitiveExports are the names of exported variables marked sensitive, packages that export them are never skipped
+	// so that their values are exported again
+	SensitiveExports []string `json:"sensitiveExports,omitempty"`
+}